@@ -9,9 +9,14 @@ import (
 	"github.com/HuseyinAsik/Notifications/cmd/email-worker/pkg/settings"
 	"github.com/HuseyinAsik/Notifications/pkg/gpostgresql"
 	"github.com/HuseyinAsik/Notifications/pkg/logging"
+	"github.com/HuseyinAsik/Notifications/pkg/metrics"
+	"github.com/HuseyinAsik/Notifications/pkg/ratelimit"
+	"github.com/HuseyinAsik/Notifications/pkg/timeline"
+	"github.com/HuseyinAsik/Notifications/pkg/tracing"
 	"github.com/HuseyinAsik/Notifications/pkg/worker"
 	"github.com/HuseyinAsik/Notifications/providers"
 	"github.com/HuseyinAsik/Notifications/repository/postgre"
+	"go.uber.org/zap"
 )
 
 func init() {
@@ -24,18 +29,37 @@ func init() {
 func main() {
 	pgPool := gpostgresql.GetPool()
 	repo := postgre.NewPostgresNotificationRepository(pgPool)
+	deadLetterRepo := postgre.NewPostgresDeadLetterRepository(pgPool)
+	preferenceRepo := postgre.NewPostgresPreferenceRepository(pgPool)
+	eventRepo := postgre.NewPostgresNotificationEventRepository(pgPool)
+	counterRepo := postgre.NewPostgresCounterRepository(pgPool)
+	tokenBucketRepo := postgre.NewPostgresTokenBucketRepository(pgPool)
 	logger := logging.GetLogger()
 	w := worker.NewWorker(
 		settings.KafkaSettings.Brokers,
 		"email",
-		100,
-		&providers.EmailProvider{},
+		ratelimit.NewLimiter(tokenBucketRepo, "email", settings.RateLimitSettings),
+		ratelimit.NewFrequencyCaps(counterRepo, settings.FrequencyCapSettings, logger),
+		worker.NewBackoff(settings.RetrySettings),
+		worker.NewPriorities(settings.PrioritySettings),
+		providers.NewEmailProvider(settings.SmtpSettings),
 		repo,
+		deadLetterRepo,
+		preferenceRepo,
+		nil,
+		timeline.NewRecorder(eventRepo, timeline.SourceWorker, settings.AppSettings.Hostname, logger),
 		logger,
 	)
 
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
+	shutdownTracing, err := tracing.Setup(ctx, settings.TracingSettings, settings.AppSettings.AppName)
+	if err != nil {
+		logger.Fatal(ctx, "Tracing setup error", zap.Error(err))
+	}
+	defer shutdownTracing()
+
+	go metrics.Serve(ctx, settings.MetricsSettings.Addr, logger)
 	w.Start(ctx)
 }
@@ -11,8 +11,15 @@ import (
 )
 
 var AppSettings = &variables.App{}
+var TracingSettings = &variables.Tracing{}
 var DatabaseSettings = &variables.Database{}
 var KafkaSettings = &variables.Kafka{}
+var MetricsSettings = &variables.Metrics{}
+var RetrySettings = &variables.Retry{}
+var FrequencyCapSettings = &variables.FrequencyCap{}
+var RateLimitSettings = &variables.RateLimit{}
+var PrioritySettings = &variables.PriorityScheduling{}
+var SmtpSettings = &variables.Smtp{}
 
 func Setup() {
 	_ = godotenv.Load()
@@ -46,4 +53,76 @@ func Setup() {
 	if databaseSettingsErr != nil {
 		log.Fatalf("database settings missing err: %v", databaseSettingsErr)
 	}
+
+	RetrySettings.BaseDelayStr = os.Getenv("RETRY_BASE_DELAY")
+	RetrySettings.MaxDelayStr = os.Getenv("RETRY_MAX_DELAY")
+	RetrySettings.MaxAttemptsStr = os.Getenv("RETRY_MAX_ATTEMPTS")
+
+	retrySettingsErr := validate.Struct(RetrySettings)
+	if retrySettingsErr != nil {
+		log.Fatalf("retry settings missing err: %v", retrySettingsErr)
+	}
+	RetrySettings.Load()
+
+	FrequencyCapSettings.RecipientStr = os.Getenv("FREQUENCY_CAP_RECIPIENT")
+	FrequencyCapSettings.TenantStr = os.Getenv("FREQUENCY_CAP_TENANT")
+	FrequencyCapSettings.Policy = strings.ToLower(os.Getenv("FREQUENCY_CAP_POLICY"))
+	FrequencyCapSettings.PurgeIntervalStr = os.Getenv("FREQUENCY_CAP_PURGE_INTERVAL")
+
+	frequencyCapSettingsErr := validate.Struct(FrequencyCapSettings)
+	if frequencyCapSettingsErr != nil {
+		log.Fatalf("frequency cap settings missing err: %v", frequencyCapSettingsErr)
+	}
+	FrequencyCapSettings.Load()
+
+	RateLimitSettings.Backend = strings.ToLower(os.Getenv("RATE_LIMIT_BACKEND"))
+	RateLimitSettings.Account = os.Getenv("RATE_LIMIT_ACCOUNT")
+	RateLimitSettings.RateStr = os.Getenv("RATE_LIMIT_RATE")
+	RateLimitSettings.BurstStr = os.Getenv("RATE_LIMIT_BURST")
+
+	rateLimitSettingsErr := validate.Struct(RateLimitSettings)
+	if rateLimitSettingsErr != nil {
+		log.Fatalf("rate limit settings missing err: %v", rateLimitSettingsErr)
+	}
+	RateLimitSettings.Load()
+
+	SmtpSettings.Host = os.Getenv("SMTP_HOST")
+	SmtpSettings.PortStr = os.Getenv("SMTP_PORT")
+	SmtpSettings.Username = os.Getenv("SMTP_USERNAME")
+	SmtpSettings.Password = os.Getenv("SMTP_PASSWORD")
+	SmtpSettings.From = os.Getenv("SMTP_FROM")
+	SmtpSettings.ReplyTo = os.Getenv("SMTP_REPLY_TO")
+	SmtpSettings.TLSMode = strings.ToLower(os.Getenv("SMTP_TLS_MODE"))
+	SmtpSettings.AuthMechanism = strings.ToLower(os.Getenv("SMTP_AUTH_MECHANISM"))
+	SmtpSettings.DefaultSubject = os.Getenv("SMTP_DEFAULT_SUBJECT")
+	SmtpSettings.TimeoutStr = os.Getenv("SMTP_TIMEOUT")
+
+	smtpSettingsErr := validate.Struct(SmtpSettings)
+	if smtpSettingsErr != nil {
+		log.Fatalf("smtp settings missing err: %v", smtpSettingsErr)
+	}
+	SmtpSettings.Load()
+
+	MetricsSettings.Addr = os.Getenv("METRICS_ADDR")
+	MetricsSettings.Load()
+
+	TracingSettings.Exporter = os.Getenv("TRACING_EXPORTER")
+	TracingSettings.SampleRatioStr = os.Getenv("TRACING_SAMPLE_RATIO")
+
+	tracingSettingsErr := validate.Struct(TracingSettings)
+	if tracingSettingsErr != nil {
+		log.Fatalf("tracing settings missing err: %v", tracingSettingsErr)
+	}
+	TracingSettings.Load()
+
+	PrioritySettings.WeightsStr = os.Getenv("PRIORITY_WEIGHTS")
+	PrioritySettings.MinSharePercentStr = os.Getenv("PRIORITY_MIN_SHARE_PERCENT")
+	PrioritySettings.AgingStr = os.Getenv("PRIORITY_AGING")
+	PrioritySettings.UrgentCategoriesStr = os.Getenv("PRIORITY_URGENT_CATEGORIES")
+
+	prioritySettingsErr := validate.Struct(PrioritySettings)
+	if prioritySettingsErr != nil {
+		log.Fatalf("priority settings missing err: %v", prioritySettingsErr)
+	}
+	PrioritySettings.Load()
 }
@@ -14,6 +14,7 @@ import (
 	"github.com/HuseyinAsik/Notifications/pkg/gpostgresql"
 	"github.com/HuseyinAsik/Notifications/pkg/httpx"
 	"github.com/HuseyinAsik/Notifications/pkg/logging"
+	"github.com/HuseyinAsik/Notifications/pkg/tracing"
 	"github.com/HuseyinAsik/Notifications/routers"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
@@ -28,6 +29,12 @@ func init() {
 
 func main() {
 	logger := logging.GetLogger()
+	shutdownTracing, err := tracing.Setup(context.Background(), settings.TracingSettings, settings.AppSettings.AppName)
+	if err != nil {
+		logger.Fatal(context.Background(), "Tracing setup error", zap.Error(err))
+	}
+	defer shutdownTracing()
+
 	gin.SetMode(settings.AppSettings.RunMode)
 	if settings.AppSettings.RunMode == gin.ReleaseMode {
 		gin.DefaultWriter = io.Discard
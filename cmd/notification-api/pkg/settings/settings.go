@@ -10,8 +10,15 @@ import (
 )
 
 var AppSettings = &variables.App{}
+var TracingSettings = &variables.Tracing{}
 var ServerSettings = &variables.Server{}
 var DatabaseSettings = &variables.Database{}
+var SchedulerSettings = &variables.Scheduler{}
+var IdempotencySettings = &variables.Idempotency{}
+var TemplateSettings = &variables.Template{}
+var AuthSettings = &variables.Auth{}
+var ReceiptSettings = &variables.Receipt{}
+var JwtSettings = &variables.Jwt{}
 
 func Setup() {
 	_ = godotenv.Load()
@@ -45,4 +52,53 @@ func Setup() {
 	if databaseSettingsErr != nil {
 		log.Fatalf("database settings missing err: %v", databaseSettingsErr)
 	}
+
+	SchedulerSettings.MaxHorizonStr = os.Getenv("SCHEDULE_MAX_HORIZON")
+	SchedulerSettings.Load()
+
+	IdempotencySettings.RetentionStr = os.Getenv("IDEMPOTENCY_RETENTION")
+	IdempotencySettings.PurgeIntervalStr = os.Getenv("IDEMPOTENCY_PURGE_INTERVAL")
+	IdempotencySettings.LockTimeoutStr = os.Getenv("IDEMPOTENCY_LOCK_TIMEOUT")
+	IdempotencySettings.Load()
+
+	TemplateSettings.DefaultLocale = os.Getenv("TEMPLATE_DEFAULT_LOCALE")
+	TemplateSettings.Load()
+
+	AuthSettings.AdminApiKey = os.Getenv("ADMIN_API_KEY")
+
+	ReceiptSettings.SmsSecret = os.Getenv("DLR_SMS_SECRET")
+	ReceiptSettings.SmsSignatureHeader = os.Getenv("DLR_SMS_SIGNATURE_HEADER")
+	ReceiptSettings.SmsTimestampHeader = os.Getenv("DLR_SMS_TIMESTAMP_HEADER")
+	ReceiptSettings.SmsMessageIdField = os.Getenv("DLR_SMS_MESSAGE_ID_FIELD")
+	ReceiptSettings.SmsStatusField = os.Getenv("DLR_SMS_STATUS_FIELD")
+	ReceiptSettings.SmsReasonField = os.Getenv("DLR_SMS_REASON_FIELD")
+	ReceiptSettings.SmsTimestampField = os.Getenv("DLR_SMS_TIMESTAMP_FIELD")
+	ReceiptSettings.SmsStatusMapStr = os.Getenv("DLR_SMS_STATUS_MAP")
+	ReceiptSettings.MailgunSigningKey = os.Getenv("DLR_MAILGUN_SIGNING_KEY")
+	ReceiptSettings.SendgridPublicKey = os.Getenv("DLR_SENDGRID_PUBLIC_KEY")
+	ReceiptSettings.ToleranceStr = os.Getenv("DLR_TIMESTAMP_TOLERANCE")
+	ReceiptSettings.Load()
+
+	JwtSettings.JwksURL = os.Getenv("JWT_JWKS_URL")
+	JwtSettings.JwksFile = os.Getenv("JWT_JWKS_FILE")
+	JwtSettings.Issuer = os.Getenv("JWT_ISSUER")
+	JwtSettings.Audience = os.Getenv("JWT_AUDIENCE")
+	JwtSettings.TenantClaim = os.Getenv("JWT_TENANT_CLAIM")
+	JwtSettings.ScopeClaim = os.Getenv("JWT_SCOPE_CLAIM")
+	JwtSettings.RefreshIntervalStr = os.Getenv("JWT_JWKS_REFRESH_INTERVAL")
+
+	jwtSettingsErr := validate.Struct(JwtSettings)
+	if jwtSettingsErr != nil {
+		log.Fatalf("jwt settings missing err: %v", jwtSettingsErr)
+	}
+	JwtSettings.Load()
+
+	TracingSettings.Exporter = os.Getenv("TRACING_EXPORTER")
+	TracingSettings.SampleRatioStr = os.Getenv("TRACING_SAMPLE_RATIO")
+
+	tracingSettingsErr := validate.Struct(TracingSettings)
+	if tracingSettingsErr != nil {
+		log.Fatalf("tracing settings missing err: %v", tracingSettingsErr)
+	}
+	TracingSettings.Load()
 }
@@ -2,17 +2,24 @@ package main
 
 import (
 	"context"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 
 	"github.com/HuseyinAsik/Notifications/cmd/outbox-publisher/pkg/settings"
 	"github.com/HuseyinAsik/Notifications/pkg/gpostgresql"
+	"github.com/HuseyinAsik/Notifications/pkg/httpx"
 	"github.com/HuseyinAsik/Notifications/pkg/kafka"
 	"github.com/HuseyinAsik/Notifications/pkg/logging"
+	"github.com/HuseyinAsik/Notifications/pkg/metrics"
+	"github.com/HuseyinAsik/Notifications/pkg/timeline"
+	"github.com/HuseyinAsik/Notifications/pkg/tracing"
+	"github.com/HuseyinAsik/Notifications/pkg/worker"
 	"github.com/HuseyinAsik/Notifications/services"
 
 	"github.com/HuseyinAsik/Notifications/repository/postgre"
+	"go.uber.org/zap"
 )
 
 func init() {
@@ -30,10 +37,49 @@ func main() {
 	logger := logging.GetLogger()
 	postgresqlPool := gpostgresql.GetPool()
 
+	shutdownTracing, err := tracing.Setup(ctx, settings.TracingSettings, settings.AppSettings.AppName)
+	if err != nil {
+		logger.Fatal(ctx, "Tracing setup error", zap.Error(err))
+	}
+	defer shutdownTracing()
+
 	repo := postgre.NewPostgresNotificationRepository(postgresqlPool)
 	writer := kafka.NewWriter(settings.KafkaSettings.Brokers)
 
-	pub := services.NewOutbox(repo, writer, logger)
+	recorder := timeline.NewRecorder(
+		postgre.NewPostgresNotificationEventRepository(postgresqlPool),
+		timeline.SourceOutboxPublisher,
+		settings.AppSettings.Hostname,
+		logger,
+	)
+	go metrics.Serve(ctx, settings.MetricsSettings.Addr, logger)
+
+	pub := services.NewOutbox(repo, writer, recorder, logger)
+
+	if settings.SchedulerSettings.Enabled {
+		scheduler := services.NewScheduler(repo, settings.SchedulerSettings.Interval, logger)
+		go scheduler.Run(ctx)
+	}
+
+	reaper := services.NewReaper(repo, settings.ReaperSettings.Interval, settings.ReaperSettings.Lease, logger)
+	go reaper.Run(ctx)
+
+	if settings.WebhookSettings.Enabled {
+		client := httpx.NewHTTPClient(&http.Client{
+			Timeout:   settings.WebhookSettings.Timeout,
+			Transport: services.NewWebhookTransport(),
+		}, logger)
+		dispatcher := services.NewWebhookDispatcher(
+			postgre.NewPostgresWebhookRepository(postgresqlPool),
+			client,
+			worker.NewBackoff(settings.WebhookRetrySettings),
+			settings.WebhookSettings.Interval,
+			settings.WebhookSettings.Timeout,
+			settings.WebhookSettings.Concurrency,
+			logger,
+		)
+		go dispatcher.Run(ctx)
+	}
 
 	pub.Run(ctx)
 }
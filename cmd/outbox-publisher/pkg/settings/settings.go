@@ -11,9 +11,15 @@ import (
 )
 
 var AppSettings = &variables.App{}
+var TracingSettings = &variables.Tracing{}
 var ServerSettings = &variables.Server{}
 var DatabaseSettings = &variables.Database{}
 var KafkaSettings = &variables.Kafka{}
+var MetricsSettings = &variables.Metrics{}
+var SchedulerSettings = &variables.Scheduler{}
+var ReaperSettings = &variables.Reaper{}
+var WebhookSettings = &variables.Webhook{}
+var WebhookRetrySettings = &variables.Retry{}
 
 func Setup() {
 	_ = godotenv.Load()
@@ -47,4 +53,50 @@ func Setup() {
 	}
 
 	KafkaSettings.Brokers = strings.Split(KafkaSettings.BrokersStr, ",")
+
+	SchedulerSettings.EnabledStr = os.Getenv("SCHEDULER_ENABLED")
+	SchedulerSettings.IntervalStr = os.Getenv("SCHEDULER_INTERVAL")
+
+	schedulerSettingsErr := validate.Struct(SchedulerSettings)
+	if schedulerSettingsErr != nil {
+		log.Fatalf("scheduler settings missing err: %v", schedulerSettingsErr)
+	}
+	SchedulerSettings.Load()
+
+	ReaperSettings.IntervalStr = os.Getenv("OUTBOX_REAPER_INTERVAL")
+	ReaperSettings.LeaseStr = os.Getenv("OUTBOX_PROCESSING_LEASE")
+	ReaperSettings.Load()
+
+	WebhookSettings.EnabledStr = os.Getenv("WEBHOOK_ENABLED")
+	WebhookSettings.IntervalStr = os.Getenv("WEBHOOK_INTERVAL")
+	WebhookSettings.TimeoutStr = os.Getenv("WEBHOOK_TIMEOUT")
+	WebhookSettings.ConcurrencyStr = os.Getenv("WEBHOOK_CONCURRENCY")
+
+	webhookSettingsErr := validate.Struct(WebhookSettings)
+	if webhookSettingsErr != nil {
+		log.Fatalf("webhook settings missing err: %v", webhookSettingsErr)
+	}
+	WebhookSettings.Load()
+
+	WebhookRetrySettings.BaseDelayStr = os.Getenv("WEBHOOK_RETRY_BASE_DELAY")
+	WebhookRetrySettings.MaxDelayStr = os.Getenv("WEBHOOK_RETRY_MAX_DELAY")
+	WebhookRetrySettings.MaxAttemptsStr = os.Getenv("WEBHOOK_RETRY_MAX_ATTEMPTS")
+
+	webhookRetrySettingsErr := validate.Struct(WebhookRetrySettings)
+	if webhookRetrySettingsErr != nil {
+		log.Fatalf("webhook retry settings missing err: %v", webhookRetrySettingsErr)
+	}
+	WebhookRetrySettings.Load()
+
+	MetricsSettings.Addr = os.Getenv("METRICS_ADDR")
+	MetricsSettings.Load()
+
+	TracingSettings.Exporter = os.Getenv("TRACING_EXPORTER")
+	TracingSettings.SampleRatioStr = os.Getenv("TRACING_SAMPLE_RATIO")
+
+	tracingSettingsErr := validate.Struct(TracingSettings)
+	if tracingSettingsErr != nil {
+		log.Fatalf("tracing settings missing err: %v", tracingSettingsErr)
+	}
+	TracingSettings.Load()
 }
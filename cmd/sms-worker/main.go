@@ -2,16 +2,23 @@ package main
 
 import (
 	"context"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 
 	"github.com/HuseyinAsik/Notifications/cmd/sms-worker/pkg/settings"
 	"github.com/HuseyinAsik/Notifications/pkg/gpostgresql"
+	"github.com/HuseyinAsik/Notifications/pkg/httpx"
 	"github.com/HuseyinAsik/Notifications/pkg/logging"
+	"github.com/HuseyinAsik/Notifications/pkg/metrics"
+	"github.com/HuseyinAsik/Notifications/pkg/ratelimit"
+	"github.com/HuseyinAsik/Notifications/pkg/timeline"
+	"github.com/HuseyinAsik/Notifications/pkg/tracing"
 	"github.com/HuseyinAsik/Notifications/pkg/worker"
 	"github.com/HuseyinAsik/Notifications/providers"
 	"github.com/HuseyinAsik/Notifications/repository/postgre"
+	"go.uber.org/zap"
 )
 
 func init() {
@@ -24,18 +31,44 @@ func init() {
 func main() {
 	pgPool := gpostgresql.GetPool()
 	repo := postgre.NewPostgresNotificationRepository(pgPool)
+	deadLetterRepo := postgre.NewPostgresDeadLetterRepository(pgPool)
+	preferenceRepo := postgre.NewPostgresPreferenceRepository(pgPool)
+	eventRepo := postgre.NewPostgresNotificationEventRepository(pgPool)
+	counterRepo := postgre.NewPostgresCounterRepository(pgPool)
+	tokenBucketRepo := postgre.NewPostgresTokenBucketRepository(pgPool)
 	logger := logging.GetLogger()
+	httpxClient := httpx.NewHTTPClient(&http.Client{Timeout: settings.SmsSettings.Timeout}, logger)
+	smsProvider := providers.NewSMSProvider(
+		httpxClient,
+		providers.NewRESTSMSAdapter(settings.SmsSettings),
+		settings.SmsSettings.DefaultCountryCode,
+		settings.SmsSettings.Timeout,
+	)
 	w := worker.NewWorker(
 		settings.KafkaSettings.Brokers,
 		"sms",
-		100,
-		&providers.SMSProvider{},
+		ratelimit.NewLimiter(tokenBucketRepo, "sms", settings.RateLimitSettings),
+		ratelimit.NewFrequencyCaps(counterRepo, settings.FrequencyCapSettings, logger),
+		worker.NewBackoff(settings.RetrySettings),
+		worker.NewPriorities(settings.PrioritySettings),
+		smsProvider,
 		repo,
+		deadLetterRepo,
+		preferenceRepo,
+		nil,
+		timeline.NewRecorder(eventRepo, timeline.SourceWorker, settings.AppSettings.Hostname, logger),
 		logger,
 	)
 
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
+	shutdownTracing, err := tracing.Setup(ctx, settings.TracingSettings, settings.AppSettings.AppName)
+	if err != nil {
+		logger.Fatal(ctx, "Tracing setup error", zap.Error(err))
+	}
+	defer shutdownTracing()
+
+	go metrics.Serve(ctx, settings.MetricsSettings.Addr, logger)
 	w.Start(ctx)
 }
@@ -11,8 +11,15 @@ import (
 )
 
 var AppSettings = &variables.App{}
+var TracingSettings = &variables.Tracing{}
 var DatabaseSettings = &variables.Database{}
 var KafkaSettings = &variables.Kafka{}
+var MetricsSettings = &variables.Metrics{}
+var RetrySettings = &variables.Retry{}
+var FrequencyCapSettings = &variables.FrequencyCap{}
+var RateLimitSettings = &variables.RateLimit{}
+var PrioritySettings = &variables.PriorityScheduling{}
+var SmsSettings = &variables.Sms{}
 
 func Setup() {
 	_ = godotenv.Load()
@@ -46,4 +53,82 @@ func Setup() {
 	if databaseSettingsErr != nil {
 		log.Fatalf("database settings missing err: %v", databaseSettingsErr)
 	}
+
+	RetrySettings.BaseDelayStr = os.Getenv("RETRY_BASE_DELAY")
+	RetrySettings.MaxDelayStr = os.Getenv("RETRY_MAX_DELAY")
+	RetrySettings.MaxAttemptsStr = os.Getenv("RETRY_MAX_ATTEMPTS")
+
+	retrySettingsErr := validate.Struct(RetrySettings)
+	if retrySettingsErr != nil {
+		log.Fatalf("retry settings missing err: %v", retrySettingsErr)
+	}
+	RetrySettings.Load()
+
+	FrequencyCapSettings.RecipientStr = os.Getenv("FREQUENCY_CAP_RECIPIENT")
+	FrequencyCapSettings.TenantStr = os.Getenv("FREQUENCY_CAP_TENANT")
+	FrequencyCapSettings.Policy = strings.ToLower(os.Getenv("FREQUENCY_CAP_POLICY"))
+	FrequencyCapSettings.PurgeIntervalStr = os.Getenv("FREQUENCY_CAP_PURGE_INTERVAL")
+
+	frequencyCapSettingsErr := validate.Struct(FrequencyCapSettings)
+	if frequencyCapSettingsErr != nil {
+		log.Fatalf("frequency cap settings missing err: %v", frequencyCapSettingsErr)
+	}
+	FrequencyCapSettings.Load()
+
+	RateLimitSettings.Backend = strings.ToLower(os.Getenv("RATE_LIMIT_BACKEND"))
+	RateLimitSettings.Account = os.Getenv("RATE_LIMIT_ACCOUNT")
+	RateLimitSettings.RateStr = os.Getenv("RATE_LIMIT_RATE")
+	RateLimitSettings.BurstStr = os.Getenv("RATE_LIMIT_BURST")
+
+	rateLimitSettingsErr := validate.Struct(RateLimitSettings)
+	if rateLimitSettingsErr != nil {
+		log.Fatalf("rate limit settings missing err: %v", rateLimitSettingsErr)
+	}
+	RateLimitSettings.Load()
+
+	SmsSettings.GatewayURL = os.Getenv("SMS_GATEWAY_URL")
+	SmsSettings.Method = strings.ToUpper(os.Getenv("SMS_GATEWAY_METHOD"))
+	SmsSettings.AuthHeader = os.Getenv("SMS_GATEWAY_AUTH_HEADER")
+	SmsSettings.AuthValue = os.Getenv("SMS_GATEWAY_AUTH_VALUE")
+	SmsSettings.Sender = os.Getenv("SMS_SENDER")
+	SmsSettings.DefaultCountryCode = strings.TrimPrefix(os.Getenv("SMS_DEFAULT_COUNTRY_CODE"), "+")
+	SmsSettings.ToField = os.Getenv("SMS_FIELD_TO")
+	SmsSettings.MessageField = os.Getenv("SMS_FIELD_MESSAGE")
+	SmsSettings.SenderField = os.Getenv("SMS_FIELD_SENDER")
+	SmsSettings.ReferenceField = os.Getenv("SMS_FIELD_REFERENCE")
+	SmsSettings.MessageIdField = os.Getenv("SMS_FIELD_MESSAGE_ID")
+	SmsSettings.ErrorCodeField = os.Getenv("SMS_FIELD_ERROR_CODE")
+	SmsSettings.SuccessCodesStr = os.Getenv("SMS_SUCCESS_CODES")
+	SmsSettings.RetryableCodesStr = os.Getenv("SMS_RETRYABLE_CODES")
+	SmsSettings.PermanentCodesStr = os.Getenv("SMS_PERMANENT_CODES")
+	SmsSettings.TimeoutStr = os.Getenv("SMS_TIMEOUT")
+
+	smsSettingsErr := validate.Struct(SmsSettings)
+	if smsSettingsErr != nil {
+		log.Fatalf("sms settings missing err: %v", smsSettingsErr)
+	}
+	SmsSettings.Load()
+
+	MetricsSettings.Addr = os.Getenv("METRICS_ADDR")
+	MetricsSettings.Load()
+
+	TracingSettings.Exporter = os.Getenv("TRACING_EXPORTER")
+	TracingSettings.SampleRatioStr = os.Getenv("TRACING_SAMPLE_RATIO")
+
+	tracingSettingsErr := validate.Struct(TracingSettings)
+	if tracingSettingsErr != nil {
+		log.Fatalf("tracing settings missing err: %v", tracingSettingsErr)
+	}
+	TracingSettings.Load()
+
+	PrioritySettings.WeightsStr = os.Getenv("PRIORITY_WEIGHTS")
+	PrioritySettings.MinSharePercentStr = os.Getenv("PRIORITY_MIN_SHARE_PERCENT")
+	PrioritySettings.AgingStr = os.Getenv("PRIORITY_AGING")
+	PrioritySettings.UrgentCategoriesStr = os.Getenv("PRIORITY_URGENT_CATEGORIES")
+
+	prioritySettingsErr := validate.Struct(PrioritySettings)
+	if prioritySettingsErr != nil {
+		log.Fatalf("priority settings missing err: %v", prioritySettingsErr)
+	}
+	PrioritySettings.Load()
 }
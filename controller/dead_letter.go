@@ -0,0 +1,77 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/HuseyinAsik/Notifications/pkg/logging"
+	"github.com/HuseyinAsik/Notifications/serializers"
+	"github.com/HuseyinAsik/Notifications/services"
+	"github.com/gin-gonic/gin"
+)
+
+type deadLetterController struct {
+	Logger            *logging.LogWrapper
+	DeadLetterService *services.DeadLetterService
+}
+
+func NewDeadLetterController(R gin.IRouter, deadLetterService *services.DeadLetterService, logger *logging.LogWrapper) {
+
+	controller := &deadLetterController{
+		DeadLetterService: deadLetterService,
+		Logger:            logger,
+	}
+
+	dlq := R.Group("api/v1/admin/dlq")
+	{
+		dlq.GET("", controller.List)
+		dlq.POST("/replay", controller.Replay)
+	}
+}
+
+func (c *deadLetterController) List(g *gin.Context) {
+	serializer := serializers.Serializer{C: g, Logger: c.Logger}
+	ctx := g.Request.Context()
+	var form serializers.DeadLetterListForm
+	_ = serializer.ShouldBindQuery(ctx, &form)
+
+	if err := form.Validate(ctx); err != nil {
+		serializer.ErrorResponse(http.StatusBadRequest, err)
+		return
+	}
+
+	deadLetters, total, err := c.DeadLetterService.List(ctx, form)
+
+	if err != nil {
+		serializer.ErrorResponse(http.StatusInternalServerError, err)
+		return
+	}
+
+	serializer.DeadLetterListResponse(http.StatusOK, serializers.DeadLetterListResponse{
+		DeadLetters: deadLetters,
+		Total:       total,
+	})
+}
+
+func (c *deadLetterController) Replay(g *gin.Context) {
+	serializer := serializers.Serializer{C: g, Logger: c.Logger}
+	ctx := g.Request.Context()
+	var form serializers.ReplayDeadLettersForm
+
+	_ = serializer.ShouldBindJSON(ctx, &form)
+	if validateErr := form.Validate(ctx); validateErr != nil {
+		serializer.ErrorResponse(http.StatusBadRequest, validateErr)
+		return
+	}
+
+	replayed, skipped, replayErr := c.DeadLetterService.Replay(ctx, form)
+
+	if replayErr != nil {
+		serializer.ErrorResponse(http.StatusInternalServerError, replayErr)
+		return
+	}
+
+	serializer.ReplayDeadLettersResponse(http.StatusOK, serializers.ReplayDeadLettersResponse{
+		Replayed: replayed,
+		Skipped:  skipped,
+	})
+}
@@ -0,0 +1,49 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/HuseyinAsik/Notifications/middleware"
+	"github.com/HuseyinAsik/Notifications/pkg/auth"
+	"github.com/HuseyinAsik/Notifications/pkg/logging"
+	"github.com/HuseyinAsik/Notifications/serializers"
+	"github.com/HuseyinAsik/Notifications/services"
+	"github.com/gin-gonic/gin"
+)
+
+type deviceTokenController struct {
+	Logger             *logging.LogWrapper
+	DeviceTokenService *services.DeviceTokenService
+}
+
+func NewDeviceTokenController(R gin.IRouter, deviceTokenService *services.DeviceTokenService, logger *logging.LogWrapper) {
+
+	controller := &deviceTokenController{
+		DeviceTokenService: deviceTokenService,
+		Logger:             logger,
+	}
+
+	api := R.Group("api/v1/device-tokens")
+	{
+		api.PUT("", middleware.RequireScope(auth.ScopeWrite), controller.Register)
+	}
+}
+
+func (c *deviceTokenController) Register(g *gin.Context) {
+	serializer := serializers.Serializer{C: g, Logger: c.Logger}
+	ctx := g.Request.Context()
+	var form serializers.DeviceTokenForm
+
+	_ = serializer.ShouldBindJSON(ctx, &form)
+	if validateErr := form.Validate(ctx); validateErr != nil {
+		serializer.ErrorResponse(http.StatusBadRequest, validateErr)
+		return
+	}
+
+	if err := c.DeviceTokenService.Register(ctx, auth.TenantId(ctx), form); err != nil {
+		serializer.ErrorResponse(errorStatus(err), err)
+		return
+	}
+
+	g.Status(http.StatusNoContent)
+}
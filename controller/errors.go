@@ -0,0 +1,35 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/HuseyinAsik/Notifications/providers"
+	"github.com/HuseyinAsik/Notifications/services"
+)
+
+// errorStatus maps service errors to HTTP status codes; unknown errors are internal.
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrScheduleHorizonExceeded),
+		errors.Is(err, services.ErrInvalidReceipt):
+		return http.StatusBadRequest
+	case errors.Is(err, providers.ErrInvalidSignature):
+		return http.StatusUnauthorized
+	case errors.Is(err, services.ErrTemplateChannelMismatch),
+		errors.Is(err, services.ErrInvalidTemplate),
+		errors.Is(err, services.ErrTemplateVariablesMissing):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, services.ErrNotificationNotFound),
+		errors.Is(err, services.ErrTemplateNotFound),
+		errors.Is(err, services.ErrPreferenceNotFound),
+		errors.Is(err, services.ErrTenantNotFound),
+		errors.Is(err, services.ErrApiKeyNotFound),
+		errors.Is(err, services.ErrReceiptProviderNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrNotificationNotPending):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/HuseyinAsik/Notifications/middleware"
+	"github.com/HuseyinAsik/Notifications/pkg/auth"
 	"github.com/HuseyinAsik/Notifications/pkg/logging"
 	"github.com/HuseyinAsik/Notifications/serializers"
 	"github.com/HuseyinAsik/Notifications/services"
@@ -15,18 +17,26 @@ type notificationController struct {
 	NotificationService *services.NotificationService
 }
 
-func NewNotificationController(R *gin.Engine, notificationService *services.NotificationService, logger *logging.LogWrapper) {
+func NewNotificationController(R gin.IRouter, notificationService *services.NotificationService, idempotency gin.HandlerFunc, logger *logging.LogWrapper) {
 
 	controller := &notificationController{
 		NotificationService: notificationService,
 		Logger:              logger,
 	}
 
+	write := middleware.RequireScope(auth.ScopeWrite)
+	read := middleware.RequireScope(auth.ScopeRead)
+
 	api := R.Group("api/v1/notifications")
 	{
-		api.POST("", controller.Create)
-		api.POST("/batch", controller.Batch)
-		api.GET("", controller.List)
+		api.POST("", write, idempotency, controller.Create)
+		api.POST("/batch", write, idempotency, controller.Batch)
+		api.GET("", read, controller.List)
+		api.GET("/:id", read, controller.Get)
+		api.GET("/:id/events", read, controller.Events)
+		api.GET("/groups/:groupId", read, controller.GroupStatus)
+		api.POST("/:id/cancel", write, controller.Cancel)
+		api.PATCH("/:id/schedule", write, controller.Reschedule)
 	}
 }
 
@@ -40,10 +50,11 @@ func (c *notificationController) Create(g *gin.Context) {
 		serializer.ErrorResponse(http.StatusBadRequest, validateErr)
 		return
 	}
-	Id, createErr := c.NotificationService.Create(ctx, &form)
+	Id, createErr := c.NotificationService.Create(ctx, auth.TenantId(ctx), &form)
 
 	if createErr != nil {
-		serializer.ErrorResponse(http.StatusInternalServerError, createErr)
+		serializer.ErrorResponse(errorStatus(createErr), createErr)
+		return
 	}
 
 	serializer.NotificationResponse(http.StatusAccepted, serializers.NotificationResponse{
@@ -64,10 +75,11 @@ func (c *notificationController) Batch(g *gin.Context) {
 		return
 	}
 
-	Id, bulkErr := c.NotificationService.BulkCreate(ctx, form)
+	Id, bulkErr := c.NotificationService.BulkCreate(ctx, auth.TenantId(ctx), form)
 
 	if bulkErr != nil {
-		serializer.ErrorResponse(http.StatusInternalServerError, bulkErr)
+		serializer.ErrorResponse(errorStatus(bulkErr), bulkErr)
+		return
 	}
 
 	serializer.NotificationResponse(http.StatusAccepted, serializers.NotificationResponse{
@@ -88,10 +100,11 @@ func (c *notificationController) List(g *gin.Context) {
 		return
 	}
 
-	notifications, total, err := c.NotificationService.List(ctx, form)
+	notifications, total, err := c.NotificationService.List(ctx, auth.TenantId(ctx), form)
 
 	if err != nil {
 		serializer.ErrorResponse(http.StatusInternalServerError, err)
+		return
 	}
 
 	serializer.NotificationListResponse(http.StatusOK, serializers.NotificationListResponse{
@@ -99,3 +112,133 @@ func (c *notificationController) List(g *gin.Context) {
 		Total:         total,
 	})
 }
+
+func (c *notificationController) Get(g *gin.Context) {
+	serializer := serializers.Serializer{C: g, Logger: c.Logger}
+	ctx := g.Request.Context()
+	var form serializers.NotificationIdForm
+
+	_ = serializer.ShouldBindUri(ctx, &form)
+	if validateErr := form.Validate(ctx); validateErr != nil {
+		serializer.ErrorResponse(http.StatusBadRequest, validateErr)
+		return
+	}
+
+	notification, event, err := c.NotificationService.Get(ctx, auth.TenantId(ctx), form.Id)
+	if err != nil {
+		serializer.ErrorResponse(errorStatus(err), err)
+		return
+	}
+
+	response := serializers.NotificationDetailResponse{Notification: *notification}
+	if event != nil {
+		response.Delivery = &serializers.DeliveryResponse{
+			Status:        event.Status,
+			Attempts:      event.RetryCount,
+			NextAttemptAt: event.NextAttemptAt,
+			PublishedAt:   event.PublishedAt,
+		}
+	}
+
+	serializer.NotificationDetailResponse(http.StatusOK, response)
+}
+
+func (c *notificationController) GroupStatus(g *gin.Context) {
+	serializer := serializers.Serializer{C: g, Logger: c.Logger}
+	ctx := g.Request.Context()
+	var form serializers.GroupStatusForm
+
+	_ = serializer.ShouldBindUri(ctx, &form)
+	_ = serializer.ShouldBindQuery(ctx, &form)
+	if validateErr := form.Validate(ctx); validateErr != nil {
+		serializer.ErrorResponse(http.StatusBadRequest, validateErr)
+		return
+	}
+
+	counts, notifications, total, err := c.NotificationService.GroupStatus(ctx, auth.TenantId(ctx), form)
+	if err != nil {
+		serializer.ErrorResponse(errorStatus(err), err)
+		return
+	}
+
+	serializer.GroupStatusResponse(http.StatusOK, serializers.GroupStatusResponse{
+		GroupId:       form.GroupId,
+		Total:         total,
+		Counts:        counts,
+		Page:          form.Page,
+		Notifications: notifications,
+	})
+}
+
+func (c *notificationController) Cancel(g *gin.Context) {
+	serializer := serializers.Serializer{C: g, Logger: c.Logger}
+	ctx := g.Request.Context()
+	var form serializers.NotificationIdForm
+
+	_ = serializer.ShouldBindUri(ctx, &form)
+	if validateErr := form.Validate(ctx); validateErr != nil {
+		serializer.ErrorResponse(http.StatusBadRequest, validateErr)
+		return
+	}
+
+	if cancelErr := c.NotificationService.Cancel(ctx, auth.TenantId(ctx), form.Id); cancelErr != nil {
+		serializer.ErrorResponse(errorStatus(cancelErr), cancelErr)
+		return
+	}
+
+	serializer.NotificationResponse(http.StatusOK, serializers.NotificationResponse{
+		MessageId: form.Id,
+		Status:    "Cancelled",
+		CreatedAt: time.Now().Format(time.RFC3339),
+	})
+}
+
+func (c *notificationController) Reschedule(g *gin.Context) {
+	serializer := serializers.Serializer{C: g, Logger: c.Logger}
+	ctx := g.Request.Context()
+	var idForm serializers.NotificationIdForm
+	var form serializers.RescheduleForm
+
+	_ = serializer.ShouldBindUri(ctx, &idForm)
+	if validateErr := idForm.Validate(ctx); validateErr != nil {
+		serializer.ErrorResponse(http.StatusBadRequest, validateErr)
+		return
+	}
+
+	_ = serializer.ShouldBindJSON(ctx, &form)
+	if validateErr := form.Validate(ctx); validateErr != nil {
+		serializer.ErrorResponse(http.StatusBadRequest, validateErr)
+		return
+	}
+
+	if rescheduleErr := c.NotificationService.Reschedule(ctx, auth.TenantId(ctx), idForm.Id, form); rescheduleErr != nil {
+		serializer.ErrorResponse(errorStatus(rescheduleErr), rescheduleErr)
+		return
+	}
+
+	serializer.NotificationResponse(http.StatusOK, serializers.NotificationResponse{
+		MessageId: idForm.Id,
+		Status:    "Scheduled",
+		CreatedAt: time.Now().Format(time.RFC3339),
+	})
+}
+
+func (c *notificationController) Events(g *gin.Context) {
+	serializer := serializers.Serializer{C: g, Logger: c.Logger}
+	ctx := g.Request.Context()
+	var form serializers.NotificationIdForm
+
+	_ = serializer.ShouldBindUri(ctx, &form)
+	if validateErr := form.Validate(ctx); validateErr != nil {
+		serializer.ErrorResponse(http.StatusBadRequest, validateErr)
+		return
+	}
+
+	events, err := c.NotificationService.Events(ctx, auth.TenantId(ctx), form.Id)
+	if err != nil {
+		serializer.ErrorResponse(errorStatus(err), err)
+		return
+	}
+
+	serializer.NotificationEventListResponse(http.StatusOK, serializers.NotificationEventListResponse{Events: events})
+}
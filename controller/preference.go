@@ -0,0 +1,99 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/HuseyinAsik/Notifications/middleware"
+	"github.com/HuseyinAsik/Notifications/pkg/auth"
+	"github.com/HuseyinAsik/Notifications/pkg/logging"
+	"github.com/HuseyinAsik/Notifications/serializers"
+	"github.com/HuseyinAsik/Notifications/services"
+	"github.com/gin-gonic/gin"
+)
+
+type preferenceController struct {
+	Logger            *logging.LogWrapper
+	PreferenceService *services.PreferenceService
+}
+
+func NewPreferenceController(R gin.IRouter, preferenceService *services.PreferenceService, logger *logging.LogWrapper) {
+
+	controller := &preferenceController{
+		PreferenceService: preferenceService,
+		Logger:            logger,
+	}
+
+	write := middleware.RequireScope(auth.ScopeWrite)
+	read := middleware.RequireScope(auth.ScopeRead)
+
+	api := R.Group("api/v1/preferences")
+	{
+		api.PUT("", write, controller.Upsert)
+		api.GET("", read, controller.List)
+		api.DELETE("", write, controller.Delete)
+	}
+}
+
+func (c *preferenceController) Upsert(g *gin.Context) {
+	serializer := serializers.Serializer{C: g, Logger: c.Logger}
+	ctx := g.Request.Context()
+	var form serializers.PreferenceForm
+
+	_ = serializer.ShouldBindJSON(ctx, &form)
+	if validateErr := form.Validate(ctx); validateErr != nil {
+		serializer.ErrorResponse(http.StatusBadRequest, validateErr)
+		return
+	}
+
+	preference, err := c.PreferenceService.Upsert(ctx, auth.TenantId(ctx), form)
+	if err != nil {
+		serializer.ErrorResponse(errorStatus(err), err)
+		return
+	}
+
+	serializer.PreferenceResponse(http.StatusOK, *preference)
+}
+
+func (c *preferenceController) List(g *gin.Context) {
+	serializer := serializers.Serializer{C: g, Logger: c.Logger}
+	ctx := g.Request.Context()
+	var form serializers.PreferenceQueryForm
+	_ = serializer.ShouldBindQuery(ctx, &form)
+
+	if err := form.Validate(ctx); err != nil {
+		serializer.ErrorResponse(http.StatusBadRequest, err)
+		return
+	}
+
+	preferences, err := c.PreferenceService.List(ctx, auth.TenantId(ctx), form)
+	if err != nil {
+		serializer.ErrorResponse(errorStatus(err), err)
+		return
+	}
+
+	serializer.PreferenceListResponse(http.StatusOK, serializers.PreferenceListResponse{Preferences: preferences})
+}
+
+func (c *preferenceController) Delete(g *gin.Context) {
+	serializer := serializers.Serializer{C: g, Logger: c.Logger}
+	ctx := g.Request.Context()
+	var form serializers.PreferenceQueryForm
+	_ = serializer.ShouldBindQuery(ctx, &form)
+
+	if err := form.Validate(ctx); err != nil {
+		serializer.ErrorResponse(http.StatusBadRequest, err)
+		return
+	}
+	if form.Channel == "" {
+		serializer.ErrorResponse(http.StatusBadRequest, errors.New("channel is required"))
+		return
+	}
+
+	if err := c.PreferenceService.Delete(ctx, auth.TenantId(ctx), form); err != nil {
+		serializer.ErrorResponse(errorStatus(err), err)
+		return
+	}
+
+	g.Status(http.StatusNoContent)
+}
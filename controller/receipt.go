@@ -0,0 +1,90 @@
+package controller
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/HuseyinAsik/Notifications/middleware"
+	"github.com/HuseyinAsik/Notifications/pkg/auth"
+	"github.com/HuseyinAsik/Notifications/pkg/logging"
+	"github.com/HuseyinAsik/Notifications/serializers"
+	"github.com/HuseyinAsik/Notifications/services"
+	"github.com/gin-gonic/gin"
+)
+
+// Delivery reports are small; larger bodies are rejected before they are buffered.
+const maxReceiptBody = 1 << 20
+
+type receiptController struct {
+	Logger         *logging.LogWrapper
+	ReceiptService *services.ReceiptService
+}
+
+// NewReceiptController registers the provider callbacks on public, which must not require
+// tenant credentials since callbacks are authenticated by their signature, and the status
+// history on R.
+func NewReceiptController(public, R gin.IRouter, receiptService *services.ReceiptService, logger *logging.LogWrapper) {
+
+	controller := &receiptController{
+		ReceiptService: receiptService,
+		Logger:         logger,
+	}
+
+	read := middleware.RequireScope(auth.ScopeRead)
+
+	receipts := public.Group("api/v1/receipts")
+	{
+		receipts.POST("/:provider", controller.Receive)
+	}
+
+	api := R.Group("api/v1/notifications")
+	{
+		api.GET("/:id/history", read, controller.History)
+	}
+}
+
+func (c *receiptController) Receive(g *gin.Context) {
+	serializer := serializers.Serializer{C: g, Logger: c.Logger}
+	ctx := g.Request.Context()
+	var form serializers.ReceiptProviderForm
+
+	_ = serializer.ShouldBindUri(ctx, &form)
+	if validateErr := form.Validate(ctx); validateErr != nil {
+		serializer.ErrorResponse(http.StatusBadRequest, validateErr)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(g.Writer, g.Request.Body, maxReceiptBody))
+	if err != nil {
+		serializer.ErrorResponse(http.StatusRequestEntityTooLarge, err)
+		return
+	}
+
+	recorded, unmatched, err := c.ReceiptService.Handle(ctx, form.Provider, g.Request.Header, body)
+	if err != nil {
+		serializer.ErrorResponse(errorStatus(err), err)
+		return
+	}
+
+	serializer.ReceiptResponse(http.StatusOK, serializers.ReceiptResponse{Recorded: recorded, Unmatched: unmatched})
+}
+
+func (c *receiptController) History(g *gin.Context) {
+	serializer := serializers.Serializer{C: g, Logger: c.Logger}
+	ctx := g.Request.Context()
+	var form serializers.NotificationIdForm
+
+	_ = serializer.ShouldBindUri(ctx, &form)
+	if validateErr := form.Validate(ctx); validateErr != nil {
+		serializer.ErrorResponse(http.StatusBadRequest, validateErr)
+		return
+	}
+
+	history, err := c.ReceiptService.History(ctx, auth.TenantId(ctx), form.Id)
+	if err != nil {
+		serializer.ErrorResponse(errorStatus(err), err)
+		return
+	}
+
+	serializer.StatusHistoryResponse(http.StatusOK, serializers.StatusHistoryResponse{History: history})
+}
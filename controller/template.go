@@ -0,0 +1,146 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/HuseyinAsik/Notifications/middleware"
+	"github.com/HuseyinAsik/Notifications/pkg/auth"
+	"github.com/HuseyinAsik/Notifications/pkg/logging"
+	"github.com/HuseyinAsik/Notifications/serializers"
+	"github.com/HuseyinAsik/Notifications/services"
+	"github.com/gin-gonic/gin"
+)
+
+type templateController struct {
+	Logger          *logging.LogWrapper
+	TemplateService *services.TemplateService
+}
+
+func NewTemplateController(R gin.IRouter, templateService *services.TemplateService, logger *logging.LogWrapper) {
+
+	controller := &templateController{
+		TemplateService: templateService,
+		Logger:          logger,
+	}
+
+	write := middleware.RequireScope(auth.ScopeWrite)
+	read := middleware.RequireScope(auth.ScopeRead)
+
+	api := R.Group("api/v1/templates")
+	{
+		api.POST("", write, controller.Create)
+		api.GET("", read, controller.List)
+		api.GET("/:id", read, controller.Get)
+		api.PUT("/:id", write, controller.Update)
+		api.DELETE("/:id", write, controller.Delete)
+	}
+}
+
+func (c *templateController) Create(g *gin.Context) {
+	serializer := serializers.Serializer{C: g, Logger: c.Logger}
+	ctx := g.Request.Context()
+	var form serializers.CreateTemplateForm
+
+	_ = serializer.ShouldBindJSON(ctx, &form)
+	if validateErr := form.Validate(ctx); validateErr != nil {
+		serializer.ErrorResponse(http.StatusBadRequest, validateErr)
+		return
+	}
+
+	template, err := c.TemplateService.Create(ctx, auth.TenantId(ctx), form)
+	if err != nil {
+		serializer.ErrorResponse(errorStatus(err), err)
+		return
+	}
+
+	serializer.TemplateResponse(http.StatusCreated, serializers.TemplateResponse{Template: *template})
+}
+
+func (c *templateController) List(g *gin.Context) {
+	serializer := serializers.Serializer{C: g, Logger: c.Logger}
+	ctx := g.Request.Context()
+	var form serializers.TemplateListForm
+	_ = serializer.ShouldBindQuery(ctx, &form)
+
+	if err := form.Validate(ctx); err != nil {
+		serializer.ErrorResponse(http.StatusBadRequest, err)
+		return
+	}
+
+	templates, total, err := c.TemplateService.List(ctx, auth.TenantId(ctx), form)
+	if err != nil {
+		serializer.ErrorResponse(http.StatusInternalServerError, err)
+		return
+	}
+
+	serializer.TemplateListResponse(http.StatusOK, serializers.TemplateListResponse{
+		Templates: templates,
+		Total:     total,
+	})
+}
+
+func (c *templateController) Get(g *gin.Context) {
+	serializer := serializers.Serializer{C: g, Logger: c.Logger}
+	ctx := g.Request.Context()
+	var form serializers.TemplateIdForm
+
+	_ = serializer.ShouldBindUri(ctx, &form)
+	if validateErr := form.Validate(ctx); validateErr != nil {
+		serializer.ErrorResponse(http.StatusBadRequest, validateErr)
+		return
+	}
+
+	versions, err := c.TemplateService.Get(ctx, auth.TenantId(ctx), form.Id)
+	if err != nil {
+		serializer.ErrorResponse(errorStatus(err), err)
+		return
+	}
+
+	serializer.TemplateVersionsResponse(http.StatusOK, serializers.TemplateVersionsResponse{Versions: versions})
+}
+
+func (c *templateController) Update(g *gin.Context) {
+	serializer := serializers.Serializer{C: g, Logger: c.Logger}
+	ctx := g.Request.Context()
+	var idForm serializers.TemplateIdForm
+	var form serializers.UpdateTemplateForm
+
+	_ = serializer.ShouldBindUri(ctx, &idForm)
+	if validateErr := idForm.Validate(ctx); validateErr != nil {
+		serializer.ErrorResponse(http.StatusBadRequest, validateErr)
+		return
+	}
+
+	_ = serializer.ShouldBindJSON(ctx, &form)
+	if validateErr := form.Validate(ctx); validateErr != nil {
+		serializer.ErrorResponse(http.StatusBadRequest, validateErr)
+		return
+	}
+
+	template, err := c.TemplateService.Update(ctx, auth.TenantId(ctx), idForm.Id, form)
+	if err != nil {
+		serializer.ErrorResponse(errorStatus(err), err)
+		return
+	}
+
+	serializer.TemplateResponse(http.StatusOK, serializers.TemplateResponse{Template: *template})
+}
+
+func (c *templateController) Delete(g *gin.Context) {
+	serializer := serializers.Serializer{C: g, Logger: c.Logger}
+	ctx := g.Request.Context()
+	var form serializers.TemplateIdForm
+
+	_ = serializer.ShouldBindUri(ctx, &form)
+	if validateErr := form.Validate(ctx); validateErr != nil {
+		serializer.ErrorResponse(http.StatusBadRequest, validateErr)
+		return
+	}
+
+	if err := c.TemplateService.Delete(ctx, auth.TenantId(ctx), form.Id); err != nil {
+		serializer.ErrorResponse(errorStatus(err), err)
+		return
+	}
+
+	g.Status(http.StatusNoContent)
+}
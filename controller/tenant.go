@@ -0,0 +1,173 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/HuseyinAsik/Notifications/pkg/logging"
+	"github.com/HuseyinAsik/Notifications/serializers"
+	"github.com/HuseyinAsik/Notifications/services"
+	"github.com/gin-gonic/gin"
+)
+
+type tenantController struct {
+	Logger        *logging.LogWrapper
+	TenantService *services.TenantService
+}
+
+func NewTenantController(R gin.IRouter, tenantService *services.TenantService, logger *logging.LogWrapper) {
+
+	controller := &tenantController{
+		TenantService: tenantService,
+		Logger:        logger,
+	}
+
+	tenants := R.Group("api/v1/admin/tenants")
+	{
+		tenants.POST("", controller.Create)
+		tenants.GET("", controller.List)
+		tenants.POST("/:id/api-keys", controller.CreateApiKey)
+		tenants.GET("/:id/api-keys", controller.ListApiKeys)
+		tenants.PUT("/:id/webhook", controller.UpdateWebhook)
+	}
+
+	apiKeys := R.Group("api/v1/admin/api-keys")
+	{
+		apiKeys.DELETE("/:id", controller.RevokeApiKey)
+	}
+}
+
+func (c *tenantController) Create(g *gin.Context) {
+	serializer := serializers.Serializer{C: g, Logger: c.Logger}
+	ctx := g.Request.Context()
+	var form serializers.CreateTenantForm
+
+	_ = serializer.ShouldBindJSON(ctx, &form)
+	if validateErr := form.Validate(ctx); validateErr != nil {
+		serializer.ErrorResponse(http.StatusBadRequest, validateErr)
+		return
+	}
+
+	tenant, err := c.TenantService.Create(ctx, form)
+	if err != nil {
+		serializer.ErrorResponse(errorStatus(err), err)
+		return
+	}
+
+	serializer.TenantResponse(http.StatusCreated, *tenant)
+}
+
+func (c *tenantController) List(g *gin.Context) {
+	serializer := serializers.Serializer{C: g, Logger: c.Logger}
+	ctx := g.Request.Context()
+	var form serializers.TenantListForm
+	_ = serializer.ShouldBindQuery(ctx, &form)
+
+	if err := form.Validate(ctx); err != nil {
+		serializer.ErrorResponse(http.StatusBadRequest, err)
+		return
+	}
+
+	tenants, total, err := c.TenantService.List(ctx, form)
+	if err != nil {
+		serializer.ErrorResponse(http.StatusInternalServerError, err)
+		return
+	}
+
+	serializer.TenantListResponse(http.StatusOK, serializers.TenantListResponse{
+		Tenants: tenants,
+		Total:   total,
+	})
+}
+
+func (c *tenantController) CreateApiKey(g *gin.Context) {
+	serializer := serializers.Serializer{C: g, Logger: c.Logger}
+	ctx := g.Request.Context()
+	var idForm serializers.TenantIdForm
+	var form serializers.CreateApiKeyForm
+
+	_ = serializer.ShouldBindUri(ctx, &idForm)
+	if validateErr := idForm.Validate(ctx); validateErr != nil {
+		serializer.ErrorResponse(http.StatusBadRequest, validateErr)
+		return
+	}
+
+	_ = serializer.ShouldBindJSON(ctx, &form)
+	if validateErr := form.Validate(ctx); validateErr != nil {
+		serializer.ErrorResponse(http.StatusBadRequest, validateErr)
+		return
+	}
+
+	apiKey, key, err := c.TenantService.CreateApiKey(ctx, idForm.Id, form)
+	if err != nil {
+		serializer.ErrorResponse(errorStatus(err), err)
+		return
+	}
+
+	serializer.ApiKeyResponse(http.StatusCreated, serializers.ApiKeyResponse{ApiKey: *apiKey, Key: key})
+}
+
+func (c *tenantController) ListApiKeys(g *gin.Context) {
+	serializer := serializers.Serializer{C: g, Logger: c.Logger}
+	ctx := g.Request.Context()
+	var form serializers.TenantIdForm
+
+	_ = serializer.ShouldBindUri(ctx, &form)
+	if validateErr := form.Validate(ctx); validateErr != nil {
+		serializer.ErrorResponse(http.StatusBadRequest, validateErr)
+		return
+	}
+
+	apiKeys, err := c.TenantService.ListApiKeys(ctx, form.Id)
+	if err != nil {
+		serializer.ErrorResponse(errorStatus(err), err)
+		return
+	}
+
+	serializer.ApiKeyListResponse(http.StatusOK, serializers.ApiKeyListResponse{ApiKeys: apiKeys})
+}
+
+func (c *tenantController) UpdateWebhook(g *gin.Context) {
+	serializer := serializers.Serializer{C: g, Logger: c.Logger}
+	ctx := g.Request.Context()
+	var idForm serializers.TenantIdForm
+	var form serializers.UpdateWebhookForm
+
+	_ = serializer.ShouldBindUri(ctx, &idForm)
+	if validateErr := idForm.Validate(ctx); validateErr != nil {
+		serializer.ErrorResponse(http.StatusBadRequest, validateErr)
+		return
+	}
+
+	_ = serializer.ShouldBindJSON(ctx, &form)
+	if validateErr := form.Validate(ctx); validateErr != nil {
+		serializer.ErrorResponse(http.StatusBadRequest, validateErr)
+		return
+	}
+
+	tenant, secret, err := c.TenantService.UpdateWebhook(ctx, idForm.Id, form)
+	if err != nil {
+		serializer.ErrorResponse(errorStatus(err), err)
+		return
+	}
+
+	serializer.TenantWebhookResponse(http.StatusOK, serializers.TenantWebhookResponse{Tenant: *tenant, Secret: secret})
+}
+
+func (c *tenantController) RevokeApiKey(g *gin.Context) {
+	serializer := serializers.Serializer{C: g, Logger: c.Logger}
+	ctx := g.Request.Context()
+	var form serializers.ApiKeyIdForm
+
+	_ = serializer.ShouldBindUri(ctx, &form)
+	if validateErr := form.Validate(ctx); validateErr != nil {
+		serializer.ErrorResponse(http.StatusBadRequest, validateErr)
+		return
+	}
+
+	if err := c.TenantService.RevokeApiKey(ctx, form.Id); err != nil {
+		serializer.ErrorResponse(errorStatus(err), err)
+		return
+	}
+
+	g.Status(http.StatusNoContent)
+}
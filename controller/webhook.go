@@ -0,0 +1,52 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/HuseyinAsik/Notifications/middleware"
+	"github.com/HuseyinAsik/Notifications/pkg/auth"
+	"github.com/HuseyinAsik/Notifications/pkg/logging"
+	"github.com/HuseyinAsik/Notifications/serializers"
+	"github.com/HuseyinAsik/Notifications/services"
+	"github.com/gin-gonic/gin"
+)
+
+type webhookController struct {
+	Logger         *logging.LogWrapper
+	WebhookService *services.WebhookService
+}
+
+func NewWebhookController(R gin.IRouter, webhookService *services.WebhookService, logger *logging.LogWrapper) {
+
+	controller := &webhookController{
+		WebhookService: webhookService,
+		Logger:         logger,
+	}
+
+	read := middleware.RequireScope(auth.ScopeRead)
+
+	api := R.Group("api/v1/notifications")
+	{
+		api.GET("/:id/webhooks", read, controller.Deliveries)
+	}
+}
+
+func (c *webhookController) Deliveries(g *gin.Context) {
+	serializer := serializers.Serializer{C: g, Logger: c.Logger}
+	ctx := g.Request.Context()
+	var form serializers.NotificationIdForm
+
+	_ = serializer.ShouldBindUri(ctx, &form)
+	if validateErr := form.Validate(ctx); validateErr != nil {
+		serializer.ErrorResponse(http.StatusBadRequest, validateErr)
+		return
+	}
+
+	deliveries, err := c.WebhookService.Deliveries(ctx, auth.TenantId(ctx), form.Id)
+	if err != nil {
+		serializer.ErrorResponse(errorStatus(err), err)
+		return
+	}
+
+	serializer.WebhookDeliveryListResponse(http.StatusOK, serializers.WebhookDeliveryListResponse{Deliveries: deliveries})
+}
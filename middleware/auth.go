@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/HuseyinAsik/Notifications/pkg/auth"
+	"github.com/HuseyinAsik/Notifications/services"
+	"github.com/gin-gonic/gin"
+)
+
+const ApiKeyHeader = "X-Api-Key"
+
+// AuthMiddleware authenticates the request by a bearer JWT or an API key and puts the
+// caller in the request context. The admin key gets the admin scope, tenant API keys get
+// the notification scopes. verifier may be nil when JWTs are not accepted.
+func AuthMiddleware(tenantService *services.TenantService, verifier *auth.Verifier, adminKey string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		var principal *auth.Principal
+		var err error
+
+		if bearer, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer "); ok {
+			if verifier == nil {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"errorDetail": "bearer tokens are not accepted"})
+				return
+			}
+			principal, err = verifier.Verify(ctx, strings.TrimSpace(bearer))
+		} else {
+			key := c.GetHeader(ApiKeyHeader)
+			switch {
+			case key == "":
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"errorDetail": "X-Api-Key or Authorization header is required"})
+				return
+			case adminKey != "" && subtle.ConstantTimeCompare([]byte(key), []byte(adminKey)) == 1:
+				principal = &auth.Principal{Scopes: []string{auth.ScopeAdmin}}
+			default:
+				principal, err = tenantService.Authenticate(ctx, key)
+			}
+		}
+
+		if errors.Is(err, services.ErrInvalidApiKey) || errors.Is(err, auth.ErrInvalidToken) {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"errorDetail": err.Error()})
+			return
+		}
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"errorDetail": err.Error()})
+			return
+		}
+
+		c.Request = c.Request.WithContext(auth.WithPrincipal(ctx, principal))
+		c.Next()
+	}
+}
+
+// RequireScope rejects callers whose principal lacks scope with 403.
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !auth.PrincipalFrom(c.Request.Context()).HasScope(scope) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"errorDetail": "missing scope " + scope})
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/HuseyinAsik/Notifications/pkg/auth"
+	"github.com/HuseyinAsik/Notifications/services"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	IdempotencyKeyHeader = "Idempotency-Key"
+	maxIdempotencyKeyLen = 255
+)
+
+type capturingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *capturingWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *capturingWriter) WriteString(data string) (int, error) {
+	w.body.WriteString(data)
+	return w.ResponseWriter.WriteString(data)
+}
+
+// IdempotencyMiddleware replays the stored response when a request is retried with the same
+// Idempotency-Key. Requests without the header pass through untouched.
+func IdempotencyMiddleware(idempotencyService *services.IdempotencyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLen {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"errorDetail": "Idempotency-Key must be at most 255 characters"})
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"errorDetail": err.Error()})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		ctx := c.Request.Context()
+		tenantId := auth.TenantId(ctx)
+		endpoint := c.Request.Method + " " + c.FullPath()
+		hash := sha256.Sum256(body)
+
+		existing, err := idempotencyService.Begin(ctx, tenantId, key, endpoint, hex.EncodeToString(hash[:]))
+		if err != nil {
+			c.AbortWithStatusJSON(idempotencyErrorStatus(err), gin.H{"errorDetail": err.Error()})
+			return
+		}
+		if existing != nil {
+			c.Header("Idempotent-Replayed", "true")
+			c.Data(existing.StatusCode, gin.MIMEJSON, existing.Response)
+			c.Abort()
+			return
+		}
+
+		// The outcome is stored even if the request context has timed out, otherwise the key
+		// would stay in progress until it expires.
+		storeCtx := context.WithoutCancel(ctx)
+		writer := &capturingWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+
+		completed := false
+		defer func() {
+			if !completed {
+				idempotencyService.Release(storeCtx, tenantId, key, endpoint)
+			}
+		}()
+
+		c.Next()
+
+		if writer.Status() >= http.StatusInternalServerError {
+			return
+		}
+		idempotencyService.Complete(storeCtx, tenantId, key, endpoint, writer.Status(), writer.body.Bytes())
+		completed = true
+	}
+}
+
+func idempotencyErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrIdempotencyKeyReused):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, services.ErrIdempotencyKeyInProgress):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,191 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/HuseyinAsik/Notifications/models"
+	"github.com/HuseyinAsik/Notifications/pkg/logging"
+	"github.com/HuseyinAsik/Notifications/repository"
+	"github.com/HuseyinAsik/Notifications/services"
+)
+
+// memoryIdempotencyRepo keeps keys in memory; a reserved key is held until it is completed
+// or released.
+type memoryIdempotencyRepo struct {
+	mu   sync.Mutex
+	keys map[string]*models.IdempotencyKey
+}
+
+func (r *memoryIdempotencyRepo) Reserve(ctx context.Context, tenantId, key, endpoint, requestHash string, lockedUntil, expiresAt time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.keys[endpoint+" "+key]; ok {
+		return false, nil
+	}
+	r.keys[endpoint+" "+key] = &models.IdempotencyKey{Key: key, Endpoint: endpoint, RequestHash: requestHash, ExpiresAt: expiresAt}
+	return true, nil
+}
+
+func (r *memoryIdempotencyRepo) Find(ctx context.Context, tenantId, key, endpoint string) (*models.IdempotencyKey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.keys[endpoint+" "+key]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	found := *existing
+	return &found, nil
+}
+
+func (r *memoryIdempotencyRepo) Complete(ctx context.Context, tenantId, key, endpoint string, statusCode int, response []byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing := r.keys[endpoint+" "+key]
+	existing.StatusCode, existing.Response = statusCode, response
+	return nil
+}
+
+func (r *memoryIdempotencyRepo) Release(ctx context.Context, tenantId, key, endpoint string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.keys, endpoint+" "+key)
+	return nil
+}
+
+func (r *memoryIdempotencyRepo) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	return 0, nil
+}
+
+// newIdempotencyRouter serves POST /notifications, answering with the queued statuses in
+// turn; before, when set, runs ahead of each answer.
+func newIdempotencyRouter(statuses []int, before func()) (*gin.Engine, *int) {
+	gin.SetMode(gin.TestMode)
+	service := services.NewIdempotencyService(
+		&memoryIdempotencyRepo{keys: map[string]*models.IdempotencyKey{}},
+		time.Hour, time.Minute, &logging.LogWrapper{ZapLogger: zap.NewNop()},
+	)
+
+	calls := 0
+	router := gin.New()
+	router.POST("/notifications", IdempotencyMiddleware(service), func(c *gin.Context) {
+		status := statuses[calls]
+		calls++
+		if before != nil {
+			before()
+		}
+		c.JSON(status, gin.H{"call": calls})
+	})
+
+	return router, &calls
+}
+
+func postNotification(router http.Handler, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/notifications", strings.NewReader(body))
+	if key != "" {
+		req.Header.Set(IdempotencyKeyHeader, key)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	return w
+}
+
+func TestIdempotencyMiddlewareReplays(t *testing.T) {
+	router, calls := newIdempotencyRouter([]int{http.StatusAccepted, http.StatusAccepted}, nil)
+
+	first := postNotification(router, "k-1", `{"a":1}`)
+	second := postNotification(router, "k-1", `{"a":1}`)
+
+	if *calls != 1 {
+		t.Fatalf("handler ran %d times, want 1", *calls)
+	}
+	if second.Code != http.StatusAccepted || second.Body.String() != first.Body.String() {
+		t.Errorf("replay = %d %s, want %d %s", second.Code, second.Body, first.Code, first.Body)
+	}
+	if second.Header().Get("Idempotent-Replayed") != "true" || first.Header().Get("Idempotent-Replayed") != "" {
+		t.Error("only the replayed response carries Idempotent-Replayed")
+	}
+}
+
+func TestIdempotencyMiddlewareRejects(t *testing.T) {
+	tests := []struct {
+		name string
+		key  string
+		body string
+		want int
+	}{
+		{name: "different body", key: "k-1", body: `{"a":2}`, want: http.StatusUnprocessableEntity},
+		{name: "key too long", key: strings.Repeat("k", maxIdempotencyKeyLen+1), body: `{"a":1}`, want: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, _ := newIdempotencyRouter([]int{http.StatusAccepted, http.StatusAccepted}, nil)
+			postNotification(router, "k-1", `{"a":1}`)
+
+			if w := postNotification(router, tt.key, tt.body); w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestIdempotencyMiddlewareInProgress(t *testing.T) {
+	started, hold := make(chan struct{}), make(chan struct{})
+	router, _ := newIdempotencyRouter([]int{http.StatusAccepted}, func() {
+		close(started)
+		<-hold
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		postNotification(router, "k-1", `{"a":1}`)
+	}()
+	<-started
+
+	w := postNotification(router, "k-1", `{"a":1}`)
+	close(hold)
+	<-done
+
+	if w.Code != http.StatusConflict {
+		t.Errorf("status while in progress = %d, want 409", w.Code)
+	}
+}
+
+func TestIdempotencyMiddlewareReleasesOnServerError(t *testing.T) {
+	router, calls := newIdempotencyRouter([]int{http.StatusServiceUnavailable, http.StatusAccepted}, nil)
+
+	if w := postNotification(router, "k-1", `{"a":1}`); w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("first status = %d, want 503", w.Code)
+	}
+	w := postNotification(router, "k-1", `{"a":1}`)
+
+	if *calls != 2 || w.Code != http.StatusAccepted || w.Header().Get("Idempotent-Replayed") != "" {
+		t.Errorf("retry = %d after %d calls, want a fresh 202", w.Code, *calls)
+	}
+}
+
+func TestIdempotencyMiddlewareWithoutKey(t *testing.T) {
+	router, calls := newIdempotencyRouter([]int{http.StatusAccepted, http.StatusAccepted}, nil)
+
+	postNotification(router, "", `{"a":1}`)
+	postNotification(router, "", `{"a":1}`)
+
+	if *calls != 2 {
+		t.Errorf("handler ran %d times, want 2", *calls)
+	}
+}
@@ -9,8 +9,10 @@ import (
 	"strings"
 	"time"
 
+	"github.com/HuseyinAsik/Notifications/pkg/logging"
 	ginzap "github.com/gin-contrib/zap"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
@@ -31,11 +33,43 @@ func LogMiddleware(logger *zap.Logger) gin.HandlerFunc {
 					result = append(result, zap.String(value, c.GetHeader(key)))
 				}
 			}
+			if requestId := logging.RequestId(c.Request.Context()); requestId != "" {
+				result = append(result, zap.String("requestId", requestId))
+			}
 			return result
 		},
 	}
 	return ginzap.GinzapWithConfig(logger, &config)
 }
+
+// RequestIdMiddleware accepts the caller's X-Request-Id or generates one, echoes it in the
+// response and puts it into the request context, so every log line of the request carries it.
+func RequestIdMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestId := c.GetHeader(logging.RequestIdHeader)
+		if !validRequestId(requestId) {
+			requestId = uuid.NewString()
+		}
+
+		c.Header(logging.RequestIdHeader, requestId)
+		c.Request = c.Request.WithContext(logging.WithRequestId(c.Request.Context(), requestId))
+		c.Next()
+	}
+}
+
+// validRequestId accepts up to 128 printable ASCII characters without spaces, so a client
+// id cannot break log lines or Kafka headers.
+func validRequestId(requestId string) bool {
+	if requestId == "" || len(requestId) > 128 {
+		return false
+	}
+	for _, r := range requestId {
+		if r <= ' ' || r > '~' {
+			return false
+		}
+	}
+	return true
+}
 func defaultHandleRecovery(c *gin.Context, err interface{}) {
 	c.AbortWithStatus(http.StatusInternalServerError)
 }
@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/HuseyinAsik/Notifications/pkg/metrics"
+	"github.com/gin-gonic/gin"
+)
+
+// MetricsMiddleware counts requests and observes their latency by route template, so
+// /notifications/{id} is one series however many ids are requested.
+func MetricsMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		started := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		metrics.HTTPRequests.WithLabelValues(c.Request.Method, route, strconv.Itoa(c.Writer.Status())).Inc()
+		metrics.HTTPRequestDuration.WithLabelValues(c.Request.Method, route).Observe(time.Since(started).Seconds())
+	}
+}
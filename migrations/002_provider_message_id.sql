@@ -0,0 +1,6 @@
+-- =========================
+-- PROVIDER DELIVERY RESULT
+-- =========================
+
+ALTER TABLE notifications
+    ADD COLUMN IF NOT EXISTS provider_message_id TEXT NULL;
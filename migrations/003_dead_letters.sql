@@ -0,0 +1,34 @@
+-- =========================
+-- DEAD LETTERS TABLE
+-- =========================
+
+-- Mirrors the {channel}_dlq topics so failed messages can be listed and replayed by the API
+CREATE TABLE IF NOT EXISTS dead_letters (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+
+    aggregate_id UUID NULL,
+    channel VARCHAR(20) NOT NULL,
+    reason VARCHAR(50) NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT NULL,
+
+    original_topic VARCHAR(100) NOT NULL,
+    original_partition INT NOT NULL,
+    original_offset BIGINT NOT NULL,
+    payload BYTEA NOT NULL,
+
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    replayed_at TIMESTAMP NULL
+);
+
+-- -------------------------
+-- DEAD LETTER INDEXES
+-- -------------------------
+
+-- Listing per channel
+CREATE INDEX IF NOT EXISTS idx_dead_letters_channel_created
+ON dead_letters (channel, created_at DESC);
+
+-- Aggregate lookup
+CREATE INDEX IF NOT EXISTS idx_dead_letters_aggregate_id
+ON dead_letters (aggregate_id);
@@ -0,0 +1,11 @@
+-- =========================
+-- OUTBOX RETRY SCHEDULING
+-- =========================
+
+ALTER TABLE outbox
+    ADD COLUMN IF NOT EXISTS next_attempt_at TIMESTAMP NULL;
+
+-- Publisher polling (pending events whose retry time has arrived)
+CREATE INDEX IF NOT EXISTS idx_outbox_pending_next_attempt
+ON outbox (next_attempt_at, created_at)
+WHERE status = 'pending';
@@ -0,0 +1,12 @@
+-- =========================
+-- SCHEDULED NOTIFICATIONS
+-- =========================
+
+-- IANA time zone the client scheduled the notification in
+ALTER TABLE notifications
+    ADD COLUMN IF NOT EXISTS timezone VARCHAR(64) NULL;
+
+-- Scheduler polling (due scheduled notifications)
+CREATE INDEX IF NOT EXISTS idx_notifications_scheduled_due
+ON notifications (scheduled_at)
+WHERE status = 'scheduled';
@@ -0,0 +1,7 @@
+-- =========================
+-- GROUP STATUS
+-- =========================
+
+-- Batch progress lookup (per-status counts and member listing)
+CREATE INDEX IF NOT EXISTS idx_notifications_group_id
+ON notifications (group_id, created_at);
@@ -0,0 +1,23 @@
+-- =========================
+-- IDEMPOTENCY KEYS TABLE
+-- =========================
+
+-- Original responses of create/batch requests sent with an Idempotency-Key header
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    key VARCHAR(255) NOT NULL,
+    endpoint VARCHAR(255) NOT NULL,
+    request_hash CHAR(64) NOT NULL,
+
+    -- NULL while the original request is still in progress
+    status_code INT NULL,
+    response BYTEA NULL,
+
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP NOT NULL,
+
+    PRIMARY KEY (endpoint, key)
+);
+
+-- Retention cleanup
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at
+ON idempotency_keys (expires_at);
@@ -0,0 +1,31 @@
+-- =========================
+-- TEMPLATES TABLE
+-- =========================
+
+-- Every update of a template locale inserts a new version; notifications keep the version they were rendered with
+CREATE TABLE IF NOT EXISTS templates (
+    id UUID NOT NULL,
+    locale VARCHAR(35) NOT NULL,
+    version INT NOT NULL,
+
+    name VARCHAR(100) NOT NULL,
+    channel VARCHAR(20) NOT NULL,
+    subject TEXT NULL,
+    body TEXT NOT NULL,
+
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    deleted_at TIMESTAMP NULL,
+
+    PRIMARY KEY (id, locale, version)
+);
+
+-- Template listing by channel
+CREATE INDEX IF NOT EXISTS idx_templates_channel
+ON templates (channel, created_at DESC)
+WHERE deleted_at IS NULL;
+
+-- Template reference on notifications
+ALTER TABLE notifications
+    ADD COLUMN IF NOT EXISTS template_id UUID NULL,
+    ADD COLUMN IF NOT EXISTS template_version INT NULL,
+    ADD COLUMN IF NOT EXISTS locale VARCHAR(35) NULL;
@@ -0,0 +1,25 @@
+-- =========================
+-- RECIPIENT PREFERENCES TABLE
+-- =========================
+
+CREATE TABLE IF NOT EXISTS recipient_preferences (
+    recipient TEXT NOT NULL,
+    channel VARCHAR(20) NOT NULL,
+
+    opted_out BOOLEAN NOT NULL DEFAULT FALSE,
+    -- Local wall clock window ("22:00" - "08:00") in the recipient time zone
+    quiet_hours_start VARCHAR(5) NULL,
+    quiet_hours_end VARCHAR(5) NULL,
+    timezone VARCHAR(64) NULL,
+    -- Category subscriptions; a category set to false is suppressed, missing ones are allowed
+    categories JSONB NOT NULL DEFAULT '{}',
+
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (recipient, channel)
+);
+
+-- Suppression on notifications
+ALTER TABLE notifications
+    ADD COLUMN IF NOT EXISTS category VARCHAR(50) NULL,
+    ADD COLUMN IF NOT EXISTS suppression_reason VARCHAR(50) NULL;
@@ -0,0 +1,8 @@
+-- =========================
+-- QUIET HOURS
+-- =========================
+
+-- Per-request quiet hours window; falls back to recipient_preferences when empty
+ALTER TABLE notifications
+    ADD COLUMN IF NOT EXISTS quiet_hours_start VARCHAR(5) NULL,
+    ADD COLUMN IF NOT EXISTS quiet_hours_end VARCHAR(5) NULL;
@@ -0,0 +1,17 @@
+-- =========================
+-- FREQUENCY COUNTERS TABLE
+-- =========================
+
+-- Fixed-window message counters shared by all worker replicas for frequency caps
+CREATE TABLE IF NOT EXISTS frequency_counters (
+    key TEXT NOT NULL,
+    window_start TIMESTAMP NOT NULL,
+    count INT NOT NULL DEFAULT 0,
+    expires_at TIMESTAMP NOT NULL,
+
+    PRIMARY KEY (key, window_start)
+);
+
+-- Expired window cleanup
+CREATE INDEX IF NOT EXISTS idx_frequency_counters_expires_at
+ON frequency_counters (expires_at);
@@ -0,0 +1,10 @@
+-- =========================
+-- TOKEN BUCKETS TABLE
+-- =========================
+
+-- Provider send rate shared by all worker replicas, one bucket per channel and provider account
+CREATE TABLE IF NOT EXISTS token_buckets (
+    key VARCHAR(255) PRIMARY KEY,
+    tokens DOUBLE PRECISION NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
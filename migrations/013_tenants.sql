@@ -0,0 +1,77 @@
+-- =========================
+-- TENANTS AND API KEYS
+-- =========================
+
+CREATE TABLE IF NOT EXISTS tenants (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Owner of rows created before multi-tenancy
+INSERT INTO tenants (id, name)
+VALUES ('00000000-0000-0000-0000-000000000000', 'default')
+ON CONFLICT (id) DO NOTHING;
+
+-- Only the SHA-256 of a key is stored; the key itself is shown once when it is created
+CREATE TABLE IF NOT EXISTS api_keys (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL REFERENCES tenants (id),
+    name VARCHAR(100) NOT NULL,
+    prefix VARCHAR(16) NOT NULL,
+    key_hash CHAR(64) NOT NULL UNIQUE,
+
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    revoked_at TIMESTAMP NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_keys_tenant_id
+ON api_keys (tenant_id);
+
+-- -------------------------
+-- TENANT OWNERSHIP
+-- -------------------------
+
+ALTER TABLE notifications
+    ADD COLUMN IF NOT EXISTS tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000000';
+ALTER TABLE notifications ALTER COLUMN tenant_id DROP DEFAULT;
+
+ALTER TABLE outbox
+    ADD COLUMN IF NOT EXISTS tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000000';
+ALTER TABLE outbox ALTER COLUMN tenant_id DROP DEFAULT;
+
+ALTER TABLE templates
+    ADD COLUMN IF NOT EXISTS tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000000';
+ALTER TABLE templates ALTER COLUMN tenant_id DROP DEFAULT;
+
+-- NULL for undecodable messages whose owner is unknown
+ALTER TABLE dead_letters
+    ADD COLUMN IF NOT EXISTS tenant_id UUID NULL;
+
+ALTER TABLE recipient_preferences
+    ADD COLUMN IF NOT EXISTS tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000000';
+ALTER TABLE recipient_preferences ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE recipient_preferences DROP CONSTRAINT IF EXISTS recipient_preferences_pkey;
+ALTER TABLE recipient_preferences ADD PRIMARY KEY (tenant_id, recipient, channel);
+
+ALTER TABLE idempotency_keys
+    ADD COLUMN IF NOT EXISTS tenant_id UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000000';
+ALTER TABLE idempotency_keys ALTER COLUMN tenant_id DROP DEFAULT;
+ALTER TABLE idempotency_keys DROP CONSTRAINT IF EXISTS idempotency_keys_pkey;
+ALTER TABLE idempotency_keys ADD PRIMARY KEY (tenant_id, endpoint, key);
+
+-- -------------------------
+-- TENANT SCOPED INDEXES
+-- -------------------------
+
+CREATE INDEX IF NOT EXISTS idx_notifications_tenant_created_at
+ON notifications (tenant_id, created_at DESC);
+
+CREATE INDEX IF NOT EXISTS idx_notifications_tenant_group_id
+ON notifications (tenant_id, group_id, created_at);
+
+CREATE INDEX IF NOT EXISTS idx_templates_tenant_id
+ON templates (tenant_id, id);
+
+CREATE INDEX IF NOT EXISTS idx_dead_letters_tenant_id
+ON dead_letters (tenant_id, created_at DESC);
@@ -0,0 +1,53 @@
+-- =========================
+-- DELIVERY STATUS WEBHOOKS
+-- =========================
+
+-- Default callback URL of the tenant and the secret its webhooks are signed with
+ALTER TABLE tenants
+    ADD COLUMN IF NOT EXISTS webhook_url TEXT NULL,
+    ADD COLUMN IF NOT EXISTS webhook_secret VARCHAR(64) NULL;
+
+-- Callback URL sent with the request, wins over the tenant's
+ALTER TABLE notifications
+    ADD COLUMN IF NOT EXISTS callback_url TEXT NULL;
+
+-- Outbox of webhook calls, written in the same transaction as the status change
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL,
+    notification_id UUID NOT NULL,
+    event VARCHAR(20) NOT NULL,
+    url TEXT NOT NULL,
+    payload JSONB NOT NULL,
+
+    -- pending / delivering / delivered / failed
+    status VARCHAR(20) NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    last_status_code INT NULL,
+    last_error TEXT NULL,
+
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    delivered_at TIMESTAMP NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due
+ON webhook_deliveries (next_attempt_at)
+WHERE status IN ('pending', 'delivering');
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_notification
+ON webhook_deliveries (tenant_id, notification_id, created_at);
+
+-- One row per HTTP call
+CREATE TABLE IF NOT EXISTS webhook_delivery_attempts (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    delivery_id UUID NOT NULL REFERENCES webhook_deliveries (id) ON DELETE CASCADE,
+    attempt INT NOT NULL,
+    status_code INT NULL,
+    error TEXT NULL,
+    duration_ms INT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_delivery_attempts_delivery
+ON webhook_delivery_attempts (delivery_id, attempt);
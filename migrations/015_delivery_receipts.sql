@@ -0,0 +1,33 @@
+-- =========================
+-- PROVIDER DELIVERY RECEIPTS
+-- =========================
+
+-- Latest state reported by the provider after the message was sent
+ALTER TABLE notifications
+    ADD COLUMN IF NOT EXISTS delivery_status VARCHAR(20) NULL,
+    ADD COLUMN IF NOT EXISTS delivery_status_at TIMESTAMP NULL;
+
+-- Receipts are correlated by the id the provider returned on send
+CREATE INDEX IF NOT EXISTS idx_notifications_provider_message_id
+ON notifications (channel, provider_message_id)
+WHERE provider_message_id IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS notification_status_history (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL,
+    notification_id UUID NOT NULL,
+
+    -- delivered / bounced / complained / read
+    status VARCHAR(20) NOT NULL,
+    provider VARCHAR(20) NOT NULL,
+    provider_message_id TEXT NOT NULL,
+    reason TEXT NULL,
+    payload JSONB NULL,
+
+    occurred_at TIMESTAMP NOT NULL,
+    received_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Providers redeliver receipts until they are acknowledged
+CREATE UNIQUE INDEX IF NOT EXISTS uq_notification_status_history_receipt
+ON notification_status_history (notification_id, status, occurred_at);
@@ -0,0 +1,28 @@
+-- =========================
+-- NOTIFICATION EVENT TIMELINE
+-- =========================
+
+-- One row per status transition, written by the api, the outbox publisher and the workers
+CREATE TABLE IF NOT EXISTS notification_events (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    tenant_id UUID NOT NULL,
+    notification_id UUID NOT NULL,
+    status VARCHAR(20) NOT NULL,
+
+    -- api / outbox-publisher / worker
+    source VARCHAR(30) NOT NULL,
+    hostname TEXT NOT NULL,
+    attempt INT NULL,
+
+    provider_status VARCHAR(20) NULL,
+    provider_code TEXT NULL,
+    provider_message_id TEXT NULL,
+    reason TEXT NULL,
+    error TEXT NULL,
+    next_attempt_at TIMESTAMP NULL,
+
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_events_notification
+ON notification_events (tenant_id, notification_id, created_at);
@@ -0,0 +1,8 @@
+-- =========================
+-- OUTBOX TRACE CONTEXT
+-- =========================
+
+-- W3C trace context (traceparent, tracestate) of the request that created the event, so the
+-- publisher and the workers continue the same trace
+ALTER TABLE outbox
+    ADD COLUMN IF NOT EXISTS trace_context JSONB NULL;
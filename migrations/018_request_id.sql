@@ -0,0 +1,14 @@
+-- =========================
+-- REQUEST ID
+-- =========================
+
+-- X-Request-Id of the API request that created the notification, forwarded to the workers
+ALTER TABLE notifications
+    ADD COLUMN IF NOT EXISTS request_id TEXT NULL;
+
+ALTER TABLE outbox
+    ADD COLUMN IF NOT EXISTS request_id TEXT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_notifications_request_id
+ON notifications (request_id)
+WHERE request_id IS NOT NULL;
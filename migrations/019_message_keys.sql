@@ -0,0 +1,11 @@
+-- =========================
+-- KAFKA MESSAGE KEYS
+-- =========================
+
+-- Client supplied key whose notifications must be delivered in order; the recipient otherwise
+ALTER TABLE notifications
+    ADD COLUMN IF NOT EXISTS ordering_key TEXT NULL;
+
+-- Kafka key the event is published with, so all events of a key land on one partition
+ALTER TABLE outbox
+    ADD COLUMN IF NOT EXISTS message_key TEXT NULL;
@@ -0,0 +1,11 @@
+-- =========================
+-- OUTBOX CLAIM LEASE
+-- =========================
+
+-- When a worker claimed the event; processing rows older than the lease are requeued
+ALTER TABLE outbox
+    ADD COLUMN IF NOT EXISTS claimed_at TIMESTAMP NULL;
+
+CREATE INDEX IF NOT EXISTS idx_outbox_processing_claimed_at
+ON outbox (claimed_at)
+WHERE status = 'processing';
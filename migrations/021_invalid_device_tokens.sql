@@ -0,0 +1,12 @@
+-- =========================
+-- INVALID DEVICE TOKENS
+-- =========================
+
+-- Push tokens FCM or APNs reported as unregistered; notifications to them are suppressed
+CREATE TABLE IF NOT EXISTS invalid_device_tokens (
+    tenant_id UUID NOT NULL,
+    token TEXT NOT NULL,
+    reason TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (tenant_id, token)
+);
@@ -0,0 +1,8 @@
+-- =========================
+-- IDEMPOTENCY KEY LEASE
+-- =========================
+
+-- Until when the request holding an in-progress key is waited for; after that, e.g. when
+-- the API crashed mid-request, a retry with the same key takes the key over
+ALTER TABLE idempotency_keys
+    ADD COLUMN IF NOT EXISTS locked_until TIMESTAMP NULL;
@@ -0,0 +1,18 @@
+-- =========================
+-- FREQUENCY CHARGES TABLE
+-- =========================
+
+-- The notifications counted against each frequency counter; a notification is counted once,
+-- however often it is retried or redelivered
+CREATE TABLE IF NOT EXISTS frequency_charges (
+    key TEXT NOT NULL,
+    notification_id UUID NOT NULL,
+    window_start TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+
+    PRIMARY KEY (key, notification_id)
+);
+
+-- Expired charge cleanup
+CREATE INDEX IF NOT EXISTS idx_frequency_charges_expires_at
+ON frequency_charges (expires_at);
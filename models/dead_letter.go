@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+type DeadLetter struct {
+	Id                string     `json:"id"`
+	AggregateId       string     `json:"aggregateId,omitempty"`
+	TenantId          string     `json:"tenantId,omitempty"`
+	Channel           string     `json:"channel"`
+	Reason            string     `json:"reason"`
+	Attempts          int        `json:"attempts"`
+	LastError         string     `json:"lastError,omitempty"`
+	OriginalTopic     string     `json:"originalTopic"`
+	OriginalPartition int        `json:"originalPartition"`
+	OriginalOffset    int64      `json:"originalOffset"`
+	Payload           []byte     `json:"payload"`
+	CreatedAt         time.Time  `json:"createdAt"`
+	ReplayedAt        *time.Time `json:"replayedAt,omitempty"`
+}
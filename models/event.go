@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+type NotificationEvent struct {
+	Id                string     `json:"id"`
+	TenantId          string     `json:"tenantId"`
+	NotificationId    string     `json:"notificationId"`
+	Status            string     `json:"status"`
+	Source            string     `json:"source"`
+	Hostname          string     `json:"hostname"`
+	Attempt           int        `json:"attempt,omitempty"`
+	ProviderStatus    string     `json:"providerStatus,omitempty"`
+	ProviderCode      string     `json:"providerCode,omitempty"`
+	ProviderMessageId string     `json:"providerMessageId,omitempty"`
+	Reason            string     `json:"reason,omitempty"`
+	Error             string     `json:"error,omitempty"`
+	NextAttemptAt     *time.Time `json:"nextAttemptAt,omitempty"`
+	CreatedAt         time.Time  `json:"createdAt"`
+}
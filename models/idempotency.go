@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+type IdempotencyKey struct {
+	Key         string
+	Endpoint    string
+	RequestHash string
+	StatusCode  int
+	Response    []byte
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+}
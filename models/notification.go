@@ -3,13 +3,28 @@ package models
 import "time"
 
 type Notification struct {
-	Id          string     `json:"id,omitempty"`
-	GroupId     string     `json:"groupId,omitempty"`
-	Recipient   string     `json:"recipient,omitempty"`
-	Channel     string     `json:"channel,omitempty"`
-	Content     string     `json:"content,omitempty"`
-	Status      string     `json:"status,omitempty"`
-	Priority    string     `json:"priority,omitempty"`
-	ScheduledAt *time.Time `json:"scheduledAt,omitempty"`
-	CreatedAt   time.Time  `json:"createdAt,omitempty"`
+	Id                string     `json:"id,omitempty"`
+	TenantId          string     `json:"tenantId,omitempty"`
+	GroupId           string     `json:"groupId,omitempty"`
+	Recipient         string     `json:"recipient,omitempty"`
+	Channel           string     `json:"channel,omitempty"`
+	Content           string     `json:"content,omitempty"`
+	Status            string     `json:"status,omitempty"`
+	Priority          string     `json:"priority,omitempty"`
+	Category          string     `json:"category,omitempty"`
+	SuppressionReason string     `json:"suppressionReason,omitempty"`
+	ProviderMessageId string     `json:"providerMessageId,omitempty"`
+	DeliveryStatus    string     `json:"deliveryStatus,omitempty"`
+	DeliveryStatusAt  *time.Time `json:"deliveryStatusAt,omitempty"`
+	ScheduledAt       *time.Time `json:"scheduledAt,omitempty"`
+	Timezone          string     `json:"timezone,omitempty"`
+	QuietHoursStart   string     `json:"quietHoursStart,omitempty"`
+	QuietHoursEnd     string     `json:"quietHoursEnd,omitempty"`
+	TemplateId        string     `json:"templateId,omitempty"`
+	TemplateVersion   int        `json:"templateVersion,omitempty"`
+	Locale            string     `json:"locale,omitempty"`
+	CallbackUrl       string     `json:"callbackUrl,omitempty"`
+	RequestId         string     `json:"requestId,omitempty"`
+	OrderingKey       string     `json:"orderingKey,omitempty"`
+	CreatedAt         time.Time  `json:"createdAt,omitempty"`
 }
@@ -7,13 +7,19 @@ import (
 type OutboxEvent struct {
 	Id          string
 	AggregateId string
+	TenantId    string
 	GroupId     string
 	EventType   string
 	Topic       string
 	Payload     []byte
 
-	Status      string
-	RetryCount  int
-	CreatedAt   time.Time
-	PublishedAt *time.Time
+	TraceContext map[string]string
+	RequestId    string
+	MessageKey   string
+
+	Status        string
+	RetryCount    int
+	NextAttemptAt *time.Time
+	CreatedAt     time.Time
+	PublishedAt   *time.Time
 }
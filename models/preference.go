@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+const (
+	SuppressedOptedOut             = "opted_out"
+	SuppressedCategoryUnsubscribed = "category_unsubscribed"
+	SuppressedFrequencyCapped      = "frequency_capped"
+	SuppressedTokenUnregistered    = "token_unregistered"
+)
+
+type RecipientPreference struct {
+	TenantId        string          `json:"tenantId"`
+	Recipient       string          `json:"recipient"`
+	Channel         string          `json:"channel"`
+	OptedOut        bool            `json:"optedOut"`
+	QuietHoursStart string          `json:"quietHoursStart,omitempty"`
+	QuietHoursEnd   string          `json:"quietHoursEnd,omitempty"`
+	Timezone        string          `json:"timezone,omitempty"`
+	Categories      map[string]bool `json:"categories"`
+	UpdatedAt       time.Time       `json:"updatedAt"`
+}
+
+// SuppressionReason returns why a notification of the given category must not be sent to
+// the recipient, or an empty string when it is allowed.
+func (p *RecipientPreference) SuppressionReason(category string) string {
+	if p == nil {
+		return ""
+	}
+	if p.OptedOut {
+		return SuppressedOptedOut
+	}
+	if subscribed, ok := p.Categories[category]; ok && category != "" && !subscribed {
+		return SuppressedCategoryUnsubscribed
+	}
+	return ""
+}
@@ -0,0 +1,50 @@
+package models
+
+import "time"
+
+// QuietHours is a daily window, in the recipient's local time, during which non-urgent
+// notifications are held back. A window whose start is after its end spans midnight.
+type QuietHours struct {
+	Start    string
+	End      string
+	Timezone string
+}
+
+// Until returns the end of the quiet window that now falls into. It reports false when now
+// is outside the window or the window is not configured.
+func (q QuietHours) Until(now time.Time) (time.Time, bool) {
+	if q.Start == "" || q.End == "" {
+		return time.Time{}, false
+	}
+	start, startErr := time.Parse("15:04", q.Start)
+	end, endErr := time.Parse("15:04", q.End)
+	if startErr != nil || endErr != nil || q.Start == q.End {
+		return time.Time{}, false
+	}
+
+	loc, err := time.LoadLocation(q.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	local := now.In(loc)
+
+	minute := local.Hour()*60 + local.Minute()
+	startMinute := start.Hour()*60 + start.Minute()
+	endMinute := end.Hour()*60 + end.Minute()
+
+	var quiet bool
+	day := local
+	if startMinute < endMinute {
+		quiet = minute >= startMinute && minute < endMinute
+	} else {
+		quiet = minute >= startMinute || minute < endMinute
+		if minute >= startMinute {
+			day = local.AddDate(0, 0, 1)
+		}
+	}
+	if !quiet {
+		return time.Time{}, false
+	}
+
+	return time.Date(day.Year(), day.Month(), day.Day(), end.Hour(), end.Minute(), 0, 0, loc).UTC(), true
+}
@@ -0,0 +1,19 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type StatusHistory struct {
+	Id                string          `json:"id"`
+	TenantId          string          `json:"tenantId"`
+	NotificationId    string          `json:"notificationId"`
+	Status            string          `json:"status"`
+	Provider          string          `json:"provider"`
+	ProviderMessageId string          `json:"providerMessageId"`
+	Reason            string          `json:"reason,omitempty"`
+	Payload           json.RawMessage `json:"-"`
+	OccurredAt        time.Time       `json:"occurredAt"`
+	ReceivedAt        time.Time       `json:"receivedAt"`
+}
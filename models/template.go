@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+type Template struct {
+	Id        string    `json:"id"`
+	TenantId  string    `json:"tenantId"`
+	Name      string    `json:"name"`
+	Channel   string    `json:"channel"`
+	Locale    string    `json:"locale"`
+	Version   int       `json:"version"`
+	Subject   string    `json:"subject,omitempty"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"createdAt"`
+}
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+type Tenant struct {
+	Id            string    `json:"id"`
+	Name          string    `json:"name"`
+	WebhookUrl    string    `json:"webhookUrl,omitempty"`
+	WebhookSecret string    `json:"-"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
+
+type ApiKey struct {
+	Id        string     `json:"id"`
+	TenantId  string     `json:"tenantId"`
+	Name      string     `json:"name"`
+	Prefix    string     `json:"prefix"`
+	KeyHash   string     `json:"-"`
+	CreatedAt time.Time  `json:"createdAt"`
+	RevokedAt *time.Time `json:"revokedAt,omitempty"`
+}
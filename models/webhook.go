@@ -0,0 +1,34 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type WebhookDelivery struct {
+	Id             string           `json:"id"`
+	TenantId       string           `json:"tenantId"`
+	NotificationId string           `json:"notificationId"`
+	Event          string           `json:"event"`
+	Url            string           `json:"url"`
+	Payload        json.RawMessage  `json:"payload"`
+	Status         string           `json:"status"`
+	Attempts       int              `json:"attempts"`
+	NextAttemptAt  time.Time        `json:"nextAttemptAt"`
+	LastStatusCode int              `json:"lastStatusCode,omitempty"`
+	LastError      string           `json:"lastError,omitempty"`
+	CreatedAt      time.Time        `json:"createdAt"`
+	DeliveredAt    *time.Time       `json:"deliveredAt,omitempty"`
+	Secret         string           `json:"-"`
+	AttemptLog     []WebhookAttempt `json:"attemptLog"`
+}
+
+type WebhookAttempt struct {
+	Id         string    `json:"id"`
+	DeliveryId string    `json:"-"`
+	Attempt    int       `json:"attempt"`
+	StatusCode int       `json:"statusCode,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	DurationMs int       `json:"durationMs"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
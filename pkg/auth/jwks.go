@@ -0,0 +1,246 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/HuseyinAsik/Notifications/pkg/httpx"
+	"github.com/HuseyinAsik/Notifications/pkg/logging"
+	"go.uber.org/zap"
+)
+
+// A token signed with an unknown key id triggers a reload, but not more often than this,
+// so forged key ids cannot hammer the JWKS endpoint.
+const minJWKSReload = 30 * time.Second
+
+var ErrUnknownKey = errors.New("unknown signing key")
+
+type jsonWebKey struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type jsonWebKeySet struct {
+	Keys []jsonWebKey `json:"keys"`
+}
+
+// JWKS holds the verification keys of a JSON Web Key Set read from a file or an http(s)
+// URL. The set is reloaded on every refresh interval so rotated keys are picked up.
+type JWKS struct {
+	source   string
+	client   httpx.HTTPClient
+	interval time.Duration
+	logger   *logging.LogWrapper
+
+	mu         sync.RWMutex
+	keys       map[string]any
+	lastReload time.Time
+}
+
+func NewJWKS(ctx context.Context, source string, client httpx.HTTPClient, interval time.Duration, logger *logging.LogWrapper) (*JWKS, error) {
+	jwks := &JWKS{
+		source:   source,
+		client:   client,
+		interval: interval,
+		logger:   logger,
+	}
+	if err := jwks.Reload(ctx); err != nil {
+		return nil, err
+	}
+
+	return jwks, nil
+}
+
+// Run reloads the key set every refresh interval until ctx is done. A failed reload keeps
+// the previous keys.
+func (j *JWKS) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := j.Reload(ctx); err != nil {
+				j.logger.Error(ctx, "JWKS Reload Err", zap.Error(err), zap.String("source", j.source))
+			}
+		}
+	}
+}
+
+func (j *JWKS) Reload(ctx context.Context) error {
+	j.mu.Lock()
+	j.lastReload = time.Now()
+	j.mu.Unlock()
+
+	set, err := j.fetch(ctx)
+	if err != nil {
+		return err
+	}
+
+	keys := make(map[string]any, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			j.logger.Warn(ctx, "JWKS skipped key", zap.Error(err), zap.String("kid", jwk.Kid))
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+	if len(keys) == 0 {
+		return fmt.Errorf("jwks %s has no usable signing keys", j.source)
+	}
+
+	j.mu.Lock()
+	j.keys = keys
+	j.mu.Unlock()
+
+	return nil
+}
+
+// Key returns the public key with the given key id. A token without a key id is accepted
+// when the set holds a single key.
+func (j *JWKS) Key(ctx context.Context, kid string) (any, error) {
+	if key, ok := j.lookup(kid); ok {
+		return key, nil
+	}
+
+	j.mu.RLock()
+	reload := time.Since(j.lastReload) >= minJWKSReload
+	j.mu.RUnlock()
+	if reload {
+		if err := j.Reload(ctx); err != nil {
+			j.logger.Error(ctx, "JWKS Reload Err", zap.Error(err), zap.String("source", j.source))
+		}
+		if key, ok := j.lookup(kid); ok {
+			return key, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %q", ErrUnknownKey, kid)
+}
+
+func (j *JWKS) lookup(kid string) (any, bool) {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+
+	if kid == "" && len(j.keys) == 1 {
+		for _, key := range j.keys {
+			return key, true
+		}
+	}
+	key, ok := j.keys[kid]
+	return key, ok
+}
+
+func (j *JWKS) fetch(ctx context.Context) (*jsonWebKeySet, error) {
+	var set jsonWebKeySet
+
+	if !strings.HasPrefix(j.source, "http://") && !strings.HasPrefix(j.source, "https://") {
+		raw, err := os.ReadFile(j.source)
+		if err != nil {
+			return nil, fmt.Errorf("read jwks: %w", err)
+		}
+		if err := json.Unmarshal(raw, &set); err != nil {
+			return nil, fmt.Errorf("parse jwks: %w", err)
+		}
+		return &set, nil
+	}
+
+	status, err := j.client.DoJSON(ctx, http.MethodGet, j.source, nil, map[string]string{"Accept": "application/json"}, &set)
+	if err != nil {
+		return nil, fmt.Errorf("fetch jwks: %w", err)
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("fetch jwks: unexpected status %d", status)
+	}
+
+	return &set, nil
+}
+
+func (k jsonWebKey) publicKey() (any, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeSegment(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("rsa modulus: %w", err)
+		}
+		e, err := decodeSegment(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("rsa exponent: %w", err)
+		}
+		exponent := new(big.Int).SetBytes(e)
+		if len(n) < 256 || !exponent.IsInt64() || exponent.Int64() < 3 || exponent.Int64() > 1<<31-1 {
+			return nil, errors.New("rsa key too small or exponent invalid")
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}, nil
+
+	case "EC":
+		var curve elliptic.Curve
+		var ecdhCurve ecdh.Curve
+		switch k.Crv {
+		case "P-256":
+			curve, ecdhCurve = elliptic.P256(), ecdh.P256()
+		case "P-384":
+			curve, ecdhCurve = elliptic.P384(), ecdh.P384()
+		case "P-521":
+			curve, ecdhCurve = elliptic.P521(), ecdh.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+
+		x, err := decodeSegment(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("ec x: %w", err)
+		}
+		y, err := decodeSegment(k.Y)
+		if err != nil {
+			return nil, fmt.Errorf("ec y: %w", err)
+		}
+
+		// crypto/ecdh rejects points that are not on the curve.
+		size := (curve.Params().BitSize + 7) / 8
+		if len(x) > size || len(y) > size {
+			return nil, errors.New("ec coordinates too long")
+		}
+		point := make([]byte, 1+2*size)
+		point[0] = 4
+		copy(point[1+size-len(x):1+size], x)
+		copy(point[1+2*size-len(y):], y)
+		if _, err := ecdhCurve.NewPublicKey(point); err != nil {
+			return nil, fmt.Errorf("ec point: %w", err)
+		}
+
+		return &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
+
+func decodeSegment(value string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(value, "="))
+}
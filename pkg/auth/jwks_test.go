@@ -0,0 +1,109 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/HuseyinAsik/Notifications/pkg/httpx"
+)
+
+func TestJWKSKeyReloadsOnUnknownKid(t *testing.T) {
+	oldKey, newKey := newRSAKey(t, "2026-01"), newRSAKey(t, "2026-02")
+	server := newJWKSServer(t, oldKey)
+	keys := newTestJWKS(t, server.URL)
+
+	server.publish(oldKey, newKey)
+
+	// Within the minimum reload interval an unknown kid does not reach the endpoint.
+	if _, err := keys.Key(context.Background(), newKey.kid); !errors.Is(err, ErrUnknownKey) {
+		t.Fatalf("Key before the reload interval = %v, want ErrUnknownKey", err)
+	}
+	if fetches := server.fetchCount(); fetches != 1 {
+		t.Fatalf("fetched the set %d times, want 1", fetches)
+	}
+
+	keys.mu.Lock()
+	keys.lastReload = time.Now().Add(-minJWKSReload)
+	keys.mu.Unlock()
+
+	if _, err := keys.Key(context.Background(), newKey.kid); err != nil {
+		t.Fatalf("Key after rotation: %v", err)
+	}
+	if fetches := server.fetchCount(); fetches != 2 {
+		t.Errorf("fetched the set %d times, want 2", fetches)
+	}
+}
+
+func TestJWKSKeyWithoutKid(t *testing.T) {
+	only := newECKey(t, "ec-1")
+	keys := newTestJWKS(t, newJWKSServer(t, only).URL)
+
+	if _, err := keys.Key(context.Background(), ""); err != nil {
+		t.Errorf("Key without kid from a single key set: %v", err)
+	}
+
+	both := newTestJWKS(t, newJWKSServer(t, only, newRSAKey(t, "rsa-1")).URL)
+	if _, err := both.Key(context.Background(), ""); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("Key without kid from a two key set = %v, want ErrUnknownKey", err)
+	}
+}
+
+func TestJWKSFromFile(t *testing.T) {
+	key := newECKey(t, "local")
+	raw, err := json.Marshal(map[string]any{"keys": []map[string]string{key.jwk()}})
+	if err != nil {
+		t.Fatalf("marshal jwks: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, raw, 0o600); err != nil {
+		t.Fatalf("write jwks: %v", err)
+	}
+
+	verifier := NewVerifier(newTestJWKS(t, path), "", "", "tenant_id", "scope")
+	if _, err := verifier.Verify(context.Background(), key.sign(t, validClaims())); err != nil {
+		t.Errorf("verify with a file jwks: %v", err)
+	}
+}
+
+func TestJWKSSkipsUnusableKeys(t *testing.T) {
+	usable := newECKey(t, "ec-1")
+	offCurve := usable.jwk()
+	offCurve["kid"] = "off-curve"
+	offCurve["y"] = offCurve["x"]
+	encryption := newRSAKey(t, "enc").jwk()
+	encryption["use"] = "enc"
+
+	raw, err := json.Marshal(map[string]any{"keys": []map[string]string{usable.jwk(), offCurve, encryption}})
+	if err != nil {
+		t.Fatalf("marshal jwks: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, raw, 0o600); err != nil {
+		t.Fatalf("write jwks: %v", err)
+	}
+	keys := newTestJWKS(t, path)
+
+	if _, err := keys.Key(context.Background(), "ec-1"); err != nil {
+		t.Errorf("Key(ec-1): %v", err)
+	}
+	for _, kid := range []string{"off-curve", "enc"} {
+		if _, ok := keys.lookup(kid); ok {
+			t.Errorf("key %s was loaded", kid)
+		}
+	}
+}
+
+func TestNewJWKSWithoutUsableKeys(t *testing.T) {
+	server := newJWKSServer(t)
+	client := httpx.NewHTTPClient(&http.Client{Timeout: 5 * time.Second}, testLogger())
+
+	if _, err := NewJWKS(context.Background(), server.URL, client, time.Hour, testLogger()); err == nil {
+		t.Error("NewJWKS accepted an empty key set")
+	}
+}
@@ -0,0 +1,98 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+var ErrInvalidToken = errors.New("invalid token")
+
+// Verifier validates RS256 and ES256 bearer tokens against a JWKS and maps their claims to
+// a Principal.
+type Verifier struct {
+	keys        *JWKS
+	parser      *jwt.Parser
+	tenantClaim string
+	scopeClaim  string
+}
+
+func NewVerifier(keys *JWKS, issuer, audience, tenantClaim, scopeClaim string) *Verifier {
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodES256.Alg()}),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(30 * time.Second),
+	}
+	if issuer != "" {
+		options = append(options, jwt.WithIssuer(issuer))
+	}
+	if audience != "" {
+		options = append(options, jwt.WithAudience(audience))
+	}
+
+	return &Verifier{
+		keys:        keys,
+		parser:      jwt.NewParser(options...),
+		tenantClaim: tenantClaim,
+		scopeClaim:  scopeClaim,
+	}
+}
+
+// Verify checks the token's signature and registered claims. Tokens must name a tenant
+// unless they carry only the admin scope, so a tenant-less token never reaches tenant routes.
+func (v *Verifier) Verify(ctx context.Context, token string) (*Principal, error) {
+	claims := jwt.MapClaims{}
+	_, err := v.parser.ParseWithClaims(token, claims, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		return v.keys.Key(ctx, kid)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+
+	subject, _ := claims.GetSubject()
+	tenantId, _ := claims[v.tenantClaim].(string)
+	principal := &Principal{
+		TenantId: tenantId,
+		Subject:  subject,
+		Scopes:   scopes(claims[v.scopeClaim]),
+	}
+
+	if principal.TenantId == "" {
+		if !principal.HasScope(ScopeAdmin) {
+			return nil, fmt.Errorf("%w: missing %s claim", ErrInvalidToken, v.tenantClaim)
+		}
+		for _, scope := range principal.Scopes {
+			if scope != ScopeAdmin {
+				return nil, fmt.Errorf("%w: scope %s requires the %s claim", ErrInvalidToken, scope, v.tenantClaim)
+			}
+		}
+	} else if _, err := uuid.Parse(principal.TenantId); err != nil {
+		return nil, fmt.Errorf("%w: %s claim is not a uuid", ErrInvalidToken, v.tenantClaim)
+	}
+
+	return principal, nil
+}
+
+// scopes reads a space separated scope string (OAuth 2.0) or an array of scopes.
+func scopes(claim any) []string {
+	switch value := claim.(type) {
+	case string:
+		return strings.Fields(value)
+	case []any:
+		result := make([]string, 0, len(value))
+		for _, item := range value {
+			if scope, ok := item.(string); ok {
+				result = append(result, scope)
+			}
+		}
+		return result
+	default:
+		return nil
+	}
+}
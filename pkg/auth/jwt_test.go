@@ -0,0 +1,247 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/zap"
+
+	"github.com/HuseyinAsik/Notifications/pkg/httpx"
+	"github.com/HuseyinAsik/Notifications/pkg/logging"
+)
+
+const testTenant = "0b6f3c5e-5d0a-4c1e-9a7b-2f4f1c9e8d21"
+
+// signingKey is a generated key pair published in the test JWKS under kid.
+type signingKey struct {
+	kid     string
+	method  jwt.SigningMethod
+	private any
+}
+
+func newRSAKey(t *testing.T, kid string) signingKey {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate rsa key: %v", err)
+	}
+	return signingKey{kid: kid, method: jwt.SigningMethodRS256, private: key}
+}
+
+func newECKey(t *testing.T, kid string) signingKey {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate ec key: %v", err)
+	}
+	return signingKey{kid: kid, method: jwt.SigningMethodES256, private: key}
+}
+
+func (k signingKey) jwk() map[string]string {
+	encode := base64.RawURLEncoding.EncodeToString
+
+	switch key := k.private.(type) {
+	case *rsa.PrivateKey:
+		return map[string]string{
+			"kid": k.kid, "kty": "RSA", "use": "sig",
+			"n": encode(key.N.Bytes()), "e": encode(big.NewInt(int64(key.E)).Bytes()),
+		}
+	case *ecdsa.PrivateKey:
+		return map[string]string{
+			"kid": k.kid, "kty": "EC", "crv": "P-256",
+			"x": encode(key.X.FillBytes(make([]byte, 32))), "y": encode(key.Y.FillBytes(make([]byte, 32))),
+		}
+	}
+	return nil
+}
+
+func (k signingKey) sign(t *testing.T, claims jwt.MapClaims) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(k.method, claims)
+	token.Header["kid"] = k.kid
+	signed, err := token.SignedString(k.private)
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	return signed
+}
+
+// jwksServer serves the published keys and counts how often the set was fetched.
+type jwksServer struct {
+	*httptest.Server
+	mu      sync.Mutex
+	keys    []signingKey
+	fetches int
+}
+
+func newJWKSServer(t *testing.T, keys ...signingKey) *jwksServer {
+	t.Helper()
+
+	server := &jwksServer{keys: keys}
+	server.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		server.mu.Lock()
+		defer server.mu.Unlock()
+
+		server.fetches++
+		set := map[string][]map[string]string{"keys": {}}
+		for _, key := range server.keys {
+			set["keys"] = append(set["keys"], key.jwk())
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(set)
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func (s *jwksServer) publish(keys ...signingKey) {
+	s.mu.Lock()
+	s.keys = keys
+	s.mu.Unlock()
+}
+
+func (s *jwksServer) fetchCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.fetches
+}
+
+func testLogger() *logging.LogWrapper {
+	return &logging.LogWrapper{ZapLogger: zap.NewNop()}
+}
+
+func newTestJWKS(t *testing.T, url string) *JWKS {
+	t.Helper()
+
+	client := httpx.NewHTTPClient(&http.Client{Timeout: 5 * time.Second}, testLogger())
+	keys, err := NewJWKS(context.Background(), url, client, time.Hour, testLogger())
+	if err != nil {
+		t.Fatalf("new jwks: %v", err)
+	}
+	return keys
+}
+
+func validClaims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"iss":       "https://issuer.example.com",
+		"aud":       "notifications",
+		"sub":       "checkout",
+		"exp":       time.Now().Add(time.Hour).Unix(),
+		"tenant_id": testTenant,
+		"scope":     "notifications:read notifications:write",
+	}
+}
+
+func TestVerifierVerify(t *testing.T) {
+	rsaKey, ecKey := newRSAKey(t, "rsa-1"), newECKey(t, "ec-1")
+	server := newJWKSServer(t, rsaKey, ecKey)
+	verifier := NewVerifier(newTestJWKS(t, server.URL), "https://issuer.example.com", "notifications", "tenant_id", "scope")
+
+	for _, key := range []signingKey{rsaKey, ecKey} {
+		t.Run(key.method.Alg(), func(t *testing.T) {
+			principal, err := verifier.Verify(context.Background(), key.sign(t, validClaims()))
+			if err != nil {
+				t.Fatalf("verify: %v", err)
+			}
+			if principal.TenantId != testTenant || principal.Subject != "checkout" {
+				t.Errorf("principal = %+v", principal)
+			}
+			if !principal.HasScope(ScopeRead) || !principal.HasScope(ScopeWrite) || principal.HasScope(ScopeAdmin) {
+				t.Errorf("scopes = %v", principal.Scopes)
+			}
+		})
+	}
+}
+
+func TestVerifierVerifyScopeArray(t *testing.T) {
+	key := newECKey(t, "ec-1")
+	verifier := NewVerifier(newTestJWKS(t, newJWKSServer(t, key).URL), "", "", "tenant_id", "scp")
+
+	claims := validClaims()
+	claims["scp"] = []string{ScopeRead}
+	principal, err := verifier.Verify(context.Background(), key.sign(t, claims))
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if !slices.Equal(principal.Scopes, []string{ScopeRead}) {
+		t.Errorf("scopes = %v, want [%s]", principal.Scopes, ScopeRead)
+	}
+}
+
+func TestVerifierVerifyRejects(t *testing.T) {
+	key, other := newRSAKey(t, "rsa-1"), newECKey(t, "ec-other")
+	verifier := NewVerifier(newTestJWKS(t, newJWKSServer(t, key).URL), "https://issuer.example.com", "notifications", "tenant_id", "scope")
+
+	with := func(change func(jwt.MapClaims)) jwt.MapClaims {
+		claims := validClaims()
+		change(claims)
+		return claims
+	}
+	hmacToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, validClaims()).SignedString([]byte("secret"))
+	if err != nil {
+		t.Fatalf("sign hs256: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "expired", token: key.sign(t, with(func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Hour).Unix() }))},
+		{name: "without exp", token: key.sign(t, with(func(c jwt.MapClaims) { delete(c, "exp") }))},
+		{name: "wrong issuer", token: key.sign(t, with(func(c jwt.MapClaims) { c["iss"] = "https://other.example.com" }))},
+		{name: "wrong audience", token: key.sign(t, with(func(c jwt.MapClaims) { c["aud"] = "billing" }))},
+		{name: "unknown key", token: other.sign(t, validClaims())},
+		{name: "hs256", token: hmacToken},
+		{name: "tampered", token: key.sign(t, validClaims()) + "x"},
+		{name: "without tenant", token: key.sign(t, with(func(c jwt.MapClaims) { delete(c, "tenant_id") }))},
+		{name: "tenant not a uuid", token: key.sign(t, with(func(c jwt.MapClaims) { c["tenant_id"] = "acme" }))},
+		{name: "admin without tenant and other scopes", token: key.sign(t, with(func(c jwt.MapClaims) {
+			delete(c, "tenant_id")
+			c["scope"] = "admin notifications:write"
+		}))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			principal, err := verifier.Verify(context.Background(), tt.token)
+			if !errors.Is(err, ErrInvalidToken) {
+				t.Errorf("Verify = %+v, %v, want ErrInvalidToken", principal, err)
+			}
+		})
+	}
+}
+
+func TestVerifierVerifyAdminWithoutTenant(t *testing.T) {
+	key := newECKey(t, "ec-1")
+	verifier := NewVerifier(newTestJWKS(t, newJWKSServer(t, key).URL), "", "", "tenant_id", "scope")
+
+	claims := validClaims()
+	delete(claims, "tenant_id")
+	claims["scope"] = ScopeAdmin
+	principal, err := verifier.Verify(context.Background(), key.sign(t, claims))
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if principal.TenantId != "" || !principal.HasScope(ScopeAdmin) {
+		t.Errorf("principal = %+v, want a tenant-less admin", principal)
+	}
+}
@@ -0,0 +1,44 @@
+package auth
+
+import (
+	"context"
+	"slices"
+)
+
+const (
+	ScopeWrite = "notifications:write"
+	ScopeRead  = "notifications:read"
+	ScopeAdmin = "admin"
+)
+
+// Principal is the authenticated caller of an API request.
+type Principal struct {
+	TenantId string
+	ApiKeyId string
+	Subject  string
+	Scopes   []string
+}
+
+func (p *Principal) HasScope(scope string) bool {
+	return p != nil && slices.Contains(p.Scopes, scope)
+}
+
+type principalKey struct{}
+
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+func PrincipalFrom(ctx context.Context) *Principal {
+	principal, _ := ctx.Value(principalKey{}).(*Principal)
+	return principal
+}
+
+// TenantId returns the tenant of the request's caller, or an empty string for anonymous
+// and admin requests.
+func TenantId(ctx context.Context) string {
+	if principal := PrincipalFrom(ctx); principal != nil {
+		return principal.TenantId
+	}
+	return ""
+}
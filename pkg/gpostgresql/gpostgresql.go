@@ -5,8 +5,11 @@ import (
 
 	"github.com/HuseyinAsik/Notifications/pkg/logging"
 	"github.com/HuseyinAsik/Notifications/pkg/settings"
+	"github.com/HuseyinAsik/Notifications/pkg/tracing"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
 
@@ -23,15 +26,35 @@ func (s *Pool) Close() {
 }
 
 type QueryTracer struct {
-	logger *logging.LogWrapper
+	logger   *logging.LogWrapper
+	connType string
 }
 
+// TraceQueryStart logs the query and starts a client span for it when ctx is traced.
+// Queries outside a trace, like the pool's own, are not given a root span of their own.
 func (s *QueryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
 	s.logger.Debug(ctx, "Execute Query", zap.String("query", data.SQL), zap.Any("args", data.Args))
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return ctx
+	}
+
+	ctx, _ = tracing.Tracer().Start(ctx, "postgresql "+s.connType,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", "postgresql"),
+			attribute.String("db.statement", data.SQL),
+		),
+	)
 	return ctx
 }
 
 func (s *QueryTracer) TraceQueryEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryEndData) {
+	span := trace.SpanFromContext(ctx)
+	if !span.IsRecording() {
+		return
+	}
+	span.SetAttributes(attribute.Int64("db.rows_affected", data.CommandTag.RowsAffected()))
+	tracing.End(span, data.Err)
 }
 
 func Setup(config *settings.Database, logger *logging.LogWrapper) {
@@ -51,7 +74,7 @@ func createPool(logger *logging.LogWrapper, dsn, connType string) *pgxpool.Pool
 	}
 	cfg.MaxConns = 100
 	cfg.MinConns = 5
-	cfg.ConnConfig.Tracer = &QueryTracer{logger: logger}
+	cfg.ConnConfig.Tracer = &QueryTracer{logger: logger, connType: connType}
 
 	pgpool, err := pgxpool.NewWithConfig(ctx, cfg)
 	if err != nil {
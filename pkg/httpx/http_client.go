@@ -6,8 +6,13 @@ import (
 	"encoding/json"
 	"io"
 	"net/http"
+	"net/url"
+	"strings"
 
 	"github.com/HuseyinAsik/Notifications/pkg/logging"
+	"github.com/HuseyinAsik/Notifications/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
 
@@ -18,6 +23,8 @@ type httpClient struct {
 
 type HTTPClient interface {
 	DoJSON(ctx context.Context, method, url string, req any, headers map[string]string, response any) (int, error)
+	DoJSONWithHeaders(ctx context.Context, method, url string, req any, headers map[string]string, response any) (int, http.Header, error)
+	DoForm(ctx context.Context, url string, form url.Values, headers map[string]string, response any) (int, error)
 }
 
 func NewHTTPClient(client *http.Client, logger *logging.LogWrapper) HTTPClient {
@@ -28,12 +35,17 @@ func NewHTTPClient(client *http.Client, logger *logging.LogWrapper) HTTPClient {
 }
 
 func (h *httpClient) DoJSON(ctx context.Context, method, url string, reqBody any, headers map[string]string, response any) (int, error) {
+	status, _, err := h.DoJSONWithHeaders(ctx, method, url, reqBody, headers, response)
+	return status, err
+}
+
+func (h *httpClient) DoJSONWithHeaders(ctx context.Context, method, url string, reqBody any, headers map[string]string, response any) (int, http.Header, error) {
 	var body io.Reader
 	if reqBody != nil {
 		byteReqBody, marshalErr := json.Marshal(reqBody)
 		if marshalErr != nil {
 			h.Logger.Error(ctx, "DoJson marshal err:", zap.Error(marshalErr))
-			return 0, marshalErr
+			return 0, nil, marshalErr
 		}
 		body = bytes.NewBuffer(byteReqBody)
 	}
@@ -41,7 +53,7 @@ func (h *httpClient) DoJSON(ctx context.Context, method, url string, reqBody any
 	req, buildErr := http.NewRequestWithContext(ctx, method, url, body)
 	if buildErr != nil {
 		h.Logger.Error(ctx, "DoJson request build err", zap.Error(buildErr))
-		return 0, buildErr
+		return 0, nil, buildErr
 	}
 
 	if reqBody != nil {
@@ -52,10 +64,46 @@ func (h *httpClient) DoJSON(ctx context.Context, method, url string, reqBody any
 		req.Header.Set(k, v)
 	}
 
+	return h.do(ctx, req, response)
+}
+
+func (h *httpClient) DoForm(ctx context.Context, url string, form url.Values, headers map[string]string, response any) (int, error) {
+	req, buildErr := http.NewRequestWithContext(ctx, http.MethodPost, url, strings.NewReader(form.Encode()))
+	if buildErr != nil {
+		h.Logger.Error(ctx, "DoForm request build err", zap.Error(buildErr))
+		return 0, buildErr
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	status, _, err := h.do(ctx, req, response)
+	return status, err
+}
+
+func (h *httpClient) do(ctx context.Context, req *http.Request, response any) (status int, header http.Header, err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "HTTP "+req.Method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("http.request.method", req.Method),
+			attribute.String("server.address", req.URL.Host),
+			attribute.String("url.path", req.URL.Path),
+		),
+	)
+	defer func() {
+		span.SetAttributes(attribute.Int("http.response.status_code", status))
+		tracing.End(span, err)
+	}()
+	req = req.WithContext(ctx)
+	tracing.InjectHTTP(ctx, req.Header)
+
 	resp, doErr := h.Client.Do(req)
 	if doErr != nil {
 		h.Logger.Error(ctx, "DoJson http do error:", zap.Error(doErr))
-		return 0, doErr
+		return 0, nil, doErr
 	}
 
 	// Body always closed here
@@ -64,27 +112,27 @@ func (h *httpClient) DoJSON(ctx context.Context, method, url string, reqBody any
 		resp.Body.Close()
 	}()
 
-	status := resp.StatusCode
+	status = resp.StatusCode
 
 	if response == nil {
-		return status, nil
+		return status, resp.Header, nil
 	}
 
 	respBytes, readBodyErr := io.ReadAll(resp.Body)
 	if readBodyErr != nil {
 		h.Logger.Error(ctx, "DoJson read body error:", zap.Error(readBodyErr))
-		return status, readBodyErr
+		return status, resp.Header, readBodyErr
 	}
 
 	if len(respBytes) == 0 {
-		return status, nil
+		return status, resp.Header, nil
 	}
 
 	if unmarshalErr := json.Unmarshal(respBytes, response); unmarshalErr != nil {
 		h.Logger.Error(ctx, "DoJson unmarshal error:", zap.Error(unmarshalErr))
-		return status, unmarshalErr
+		return status, resp.Header, unmarshalErr
 	}
 
-	return status, nil
+	return status, resp.Header, nil
 
 }
@@ -0,0 +1,70 @@
+package httpx
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+)
+
+var ErrNonPublicAddress = errors.New("address is not public")
+
+// sharedAddressSpace is the carrier-grade NAT range (RFC 6598), private in all but name.
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// IsPublicAddr reports whether addr can be reached on the internet, ruling out loopback,
+// private, link-local (169.254.169.254 included), shared, unspecified and multicast addresses.
+func IsPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsValid() &&
+		!addr.IsLoopback() &&
+		!addr.IsPrivate() &&
+		!addr.IsLinkLocalUnicast() &&
+		!addr.IsLinkLocalMulticast() &&
+		!addr.IsInterfaceLocalMulticast() &&
+		!addr.IsMulticast() &&
+		!addr.IsUnspecified() &&
+		!sharedAddressSpace.Contains(addr)
+}
+
+// CheckPublicUrl rejects URLs whose host is a non-public IP literal or a localhost name.
+// Names are resolved only when dialing, where PublicOnlyControl checks the addresses they
+// resolve to.
+func CheckPublicUrl(rawUrl string) error {
+	u, err := url.Parse(rawUrl)
+	if err != nil {
+		return err
+	}
+
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return fmt.Errorf("%w: %s", ErrNonPublicAddress, host)
+	}
+	if addr, err := netip.ParseAddr(host); err == nil && !IsPublicAddr(addr) {
+		return fmt.Errorf("%w: %s", ErrNonPublicAddress, host)
+	}
+
+	return nil
+}
+
+// PublicOnlyControl is a net.Dialer Control that refuses connections to non-public addresses.
+// It runs after name resolution, so it also holds for names that resolve to internal hosts.
+func PublicOnlyControl(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+	if !IsPublicAddr(addr) {
+		return fmt.Errorf("%w: %s", ErrNonPublicAddress, host)
+	}
+
+	return nil
+}
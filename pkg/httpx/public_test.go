@@ -0,0 +1,74 @@
+package httpx
+
+import (
+	"errors"
+	"net/netip"
+	"testing"
+)
+
+func TestIsPublicAddr(t *testing.T) {
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:2800:220:1:248:1893:25c8:1946", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"fe80::1", false},
+		{"fd00::1", false},
+		{"100.64.0.1", false},
+		{"0.0.0.0", false},
+		{"224.0.0.1", false},
+		{"::ffff:127.0.0.1", false},
+		{"::ffff:169.254.169.254", false},
+	}
+	for _, tt := range tests {
+		if got := IsPublicAddr(netip.MustParseAddr(tt.addr)); got != tt.want {
+			t.Errorf("IsPublicAddr(%s) = %v, want %v", tt.addr, got, tt.want)
+		}
+	}
+}
+
+func TestCheckPublicUrl(t *testing.T) {
+	tests := []struct {
+		url     string
+		wantErr bool
+	}{
+		{"https://example.com/hooks", false},
+		{"https://93.184.216.34/hooks", false},
+		{"http://localhost:8080/hooks", true},
+		{"http://LOCALHOST./hooks", true},
+		{"http://api.localhost/hooks", true},
+		{"http://127.0.0.1/hooks", true},
+		{"http://[::1]:8080/hooks", true},
+		{"http://169.254.169.254/latest/meta-data/", true},
+		{"http://10.0.0.5/hooks", true},
+		{"http://192.168.0.10:9000/hooks", true},
+	}
+	for _, tt := range tests {
+		err := CheckPublicUrl(tt.url)
+		if tt.wantErr && !errors.Is(err, ErrNonPublicAddress) {
+			t.Errorf("CheckPublicUrl(%q) = %v, want ErrNonPublicAddress", tt.url, err)
+		}
+		if !tt.wantErr && err != nil {
+			t.Errorf("CheckPublicUrl(%q) = %v, want nil", tt.url, err)
+		}
+	}
+}
+
+func TestPublicOnlyControl(t *testing.T) {
+	if err := PublicOnlyControl("tcp4", "169.254.169.254:80", nil); !errors.Is(err, ErrNonPublicAddress) {
+		t.Errorf("PublicOnlyControl(169.254.169.254:80) = %v, want ErrNonPublicAddress", err)
+	}
+	if err := PublicOnlyControl("tcp6", "[::1]:443", nil); !errors.Is(err, ErrNonPublicAddress) {
+		t.Errorf("PublicOnlyControl([::1]:443) = %v, want ErrNonPublicAddress", err)
+	}
+	if err := PublicOnlyControl("tcp4", "93.184.216.34:443", nil); err != nil {
+		t.Errorf("PublicOnlyControl(93.184.216.34:443) = %v, want nil", err)
+	}
+}
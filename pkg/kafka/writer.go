@@ -4,23 +4,35 @@ import (
 	"context"
 	"time"
 
+	"github.com/HuseyinAsik/Notifications/pkg/tracing"
 	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// RequestIdHeader carries the X-Request-Id of the API request that created the notification.
+const RequestIdHeader = "x-request-id"
+
 type Writer struct {
 	writer *kafka.Writer
 }
 
 type Message struct {
-	Key   []byte
-	Value []byte
-	Topic string
+	Key     []byte
+	Value   []byte
+	Topic   string
+	Headers map[string]string
+	// TraceContext is the trace the message continues, as stored with its outbox event.
+	// When empty the message continues the trace of the WriteMessages context.
+	TraceContext map[string]string
 }
 
 func NewWriter(brokers []string) *Writer {
+	// The hash balancer sends messages with the same key to the same partition, which keeps
+	// them in order.
 	w := &kafka.Writer{
 		Addr:         kafka.TCP(brokers...),
-		Balancer:     &kafka.LeastBytes{},
+		Balancer:     &kafka.Hash{},
 		RequiredAcks: kafka.RequireAll,
 		Async:        false,
 		BatchTimeout: 10 * time.Millisecond,
@@ -37,14 +49,63 @@ func (w *Writer) WriteMessages(
 ) error {
 
 	var kafkaMessages []kafka.Message
+	var spans []trace.Span
 
 	for _, m := range messages {
+		// Each message gets its own producer span, injected into its headers so the
+		// consumer continues the trace.
+		_, span := tracing.Tracer().Start(tracing.Extract(ctx, m.TraceContext), m.Topic+" publish",
+			trace.WithSpanKind(trace.SpanKindProducer),
+			trace.WithAttributes(
+				attribute.String("messaging.system", "kafka"),
+				attribute.String("messaging.destination.name", m.Topic),
+			),
+		)
+		spans = append(spans, span)
+
+		var headers []kafka.Header
+		for key, value := range m.Headers {
+			headers = append(headers, kafka.Header{Key: key, Value: []byte(value)})
+		}
+		for key, value := range tracing.Inject(trace.ContextWithSpan(ctx, span)) {
+			headers = append(headers, kafka.Header{Key: key, Value: []byte(value)})
+		}
+
 		kafkaMessages = append(kafkaMessages, kafka.Message{
-			Key:   m.Key,
-			Value: m.Value,
-			Topic: m.Topic,
+			Key:     m.Key,
+			Value:   m.Value,
+			Topic:   m.Topic,
+			Headers: headers,
 		})
 	}
 
-	return w.writer.WriteMessages(ctx, kafkaMessages...)
+	err := w.writer.WriteMessages(ctx, kafkaMessages...)
+	for _, span := range spans {
+		tracing.End(span, err)
+	}
+
+	return err
+}
+
+func (w *Writer) Close() error {
+	return w.writer.Close()
+}
+
+// Header returns the value of the first header named key.
+func Header(msg kafka.Message, key string) string {
+	for _, header := range msg.Headers {
+		if header.Key == key {
+			return string(header.Value)
+		}
+	}
+	return ""
+}
+
+// Headers returns the message headers as a map, e.g. to extract the trace context.
+func Headers(msg kafka.Message) map[string]string {
+	headers := make(map[string]string, len(msg.Headers))
+	for _, header := range msg.Headers {
+		headers[header.Key] = string(header.Value)
+	}
+	return headers
 }
@@ -73,14 +73,51 @@ type LogModel struct {
 	fields []zap.Field
 }
 
+type contextKey string
+
+const (
+	logModelKey  contextKey = "logModel"
+	requestIdKey contextKey = "requestId"
+
+	RequestIdHeader = "X-Request-Id"
+)
+
+// WithFields returns ctx with fields added to its LogModel, so every LogWrapper call made
+// with the context logs them.
+func WithFields(ctx context.Context, fields ...zap.Field) context.Context {
+	var logModel LogModel
+	if lm, ok := ctx.Value(logModelKey).(LogModel); ok {
+		logModel.fields = append(logModel.fields, lm.fields...)
+	}
+	logModel.fields = append(logModel.fields, fields...)
+
+	return context.WithValue(ctx, logModelKey, logModel)
+}
+
+// WithRequestId returns ctx carrying the request id, which is also logged as requestId.
+func WithRequestId(ctx context.Context, requestId string) context.Context {
+	if requestId == "" {
+		return ctx
+	}
+	ctx = context.WithValue(ctx, requestIdKey, requestId)
+
+	return WithFields(ctx, zap.String("requestId", requestId))
+}
+
+// RequestId returns the request id carried by ctx, or "" when there is none.
+func RequestId(ctx context.Context) string {
+	requestId, _ := ctx.Value(requestIdKey).(string)
+	return requestId
+}
+
 func (l *LogWrapper) enrichFields(ctx context.Context, fields []zap.Field) []zap.Field {
 	fields = append(fields, zap.Time("time", time.Now().UTC()))
 
-	if lm := ctx.Value("logModel"); lm != nil {
-		if logModel, ok := lm.(LogModel); ok {
-			logModel.fields = append(logModel.fields, fields...)
-			return logModel.fields
-		}
+	if logModel, ok := ctx.Value(logModelKey).(LogModel); ok {
+		// The LogModel is shared by every call made with the context, so it is not appended to.
+		enriched := make([]zap.Field, 0, len(logModel.fields)+len(fields))
+		enriched = append(enriched, logModel.fields...)
+		return append(enriched, fields...)
 	}
 
 	return fields
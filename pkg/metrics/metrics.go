@@ -0,0 +1,145 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.uber.org/zap"
+
+	"github.com/HuseyinAsik/Notifications/pkg/logging"
+)
+
+const namespace = "notifications"
+
+var (
+	HTTPRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests handled by the API by route and status code.",
+	}, []string{"method", "route", "status"})
+
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Latency of HTTP requests handled by the API by route.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	OutboxBacklog = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "outbox_backlog",
+		Help:      "Outbox events waiting to be published.",
+	})
+
+	OutboxPublished = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "outbox_published_total",
+		Help:      "Outbox events written to Kafka by topic and result.",
+	}, []string{"topic", "result"})
+
+	OutboxPublishDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "outbox_publish_duration_seconds",
+		Help:      "Latency of writing a batch of outbox events to Kafka.",
+		Buckets:   prometheus.DefBuckets,
+	})
+
+	OutboxPublishLag = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "outbox_publish_lag_seconds",
+		Help:      "Time from the creation of an outbox event until it was published.",
+		Buckets:   prometheus.ExponentialBuckets(0.05, 2, 14),
+	})
+
+	KafkaFetched = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "kafka_messages_fetched_total",
+		Help:      "Messages fetched from Kafka by topic.",
+	}, []string{"topic"})
+
+	KafkaCommitted = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "kafka_messages_committed_total",
+		Help:      "Kafka offset commits by topic and result.",
+	}, []string{"topic", "result"})
+
+	PriorityWait = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "priority_wait_seconds",
+		Help:      "Time from producing a message until the worker picked it for processing, by priority.",
+		Buckets:   prometheus.ExponentialBuckets(0.05, 2, 14),
+	}, []string{"channel", "priority"})
+
+	PriorityLag = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "priority_lag_seconds",
+		Help:      "Age of the oldest message fetched by the worker but not yet picked, by priority.",
+	}, []string{"channel", "priority"})
+
+	PriorityBuffered = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "priority_buffered_messages",
+		Help:      "Messages fetched by the worker but not yet picked, by priority.",
+	}, []string{"channel", "priority"})
+
+	ProviderSendDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "provider_send_duration_seconds",
+		Help:      "Latency of provider send calls by channel and priority.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"channel", "priority"})
+
+	ProviderSends = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "provider_sends_total",
+		Help:      "Provider send attempts by channel, priority and resulting status (sended, pending, failed).",
+	}, []string{"channel", "priority", "outcome"})
+
+	Retries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "retries_total",
+		Help:      "Send attempts that failed and were scheduled for a retry.",
+	}, []string{"channel", "priority"})
+
+	Failures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "failed_total",
+		Help:      "Notifications that failed for good, by dead letter reason.",
+	}, []string{"channel", "priority", "reason"})
+)
+
+// Result is the result label of an operation that returned err.
+func Result(err error) string {
+	if err != nil {
+		return "error"
+	}
+	return "ok"
+}
+
+// Serve exposes /metrics on addr until ctx is done, for binaries without an HTTP API.
+func Serve(ctx context.Context, addr string, logger *logging.LogWrapper) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+
+	logger.Info(ctx, "Start metrics listener", zap.String("addr", addr))
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		logger.Error(ctx, "Metrics listener error", zap.Error(err))
+	}
+}
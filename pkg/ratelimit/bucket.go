@@ -0,0 +1,70 @@
+package ratelimit
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+
+	"github.com/HuseyinAsik/Notifications/pkg/settings"
+	"github.com/HuseyinAsik/Notifications/repository"
+	"golang.org/x/time/rate"
+)
+
+const (
+	BackendLocal    = "local"
+	BackendPostgres = "postgres"
+
+	minPollInterval = 5 * time.Millisecond
+)
+
+// Limiter blocks until the caller may send one message.
+type Limiter interface {
+	Wait(ctx context.Context) error
+}
+
+// TokenBucket is a token bucket whose state lives in a shared store, so the configured rate
+// holds for all worker replicas together rather than for each of them.
+type TokenBucket struct {
+	buckets      repository.TokenBucketRepository
+	key          string
+	rate         float64
+	burst        int
+	pollInterval time.Duration
+}
+
+// NewLimiter builds the send limiter of a channel worker. The postgres backend shares one
+// bucket per channel and provider account across replicas; the local backend limits each
+// replica on its own.
+func NewLimiter(buckets repository.TokenBucketRepository, channel string, config *settings.RateLimit) Limiter {
+	if config.Backend == BackendLocal {
+		return rate.NewLimiter(rate.Limit(config.Rate), config.Burst)
+	}
+
+	return &TokenBucket{
+		buckets:      buckets,
+		key:          channel + ":" + config.Account,
+		rate:         config.Rate,
+		burst:        config.Burst,
+		pollInterval: max(time.Duration(float64(time.Second)/config.Rate), minPollInterval),
+	}
+}
+
+func (b *TokenBucket) Wait(ctx context.Context) error {
+	for {
+		ok, err := b.buckets.Take(ctx, b.key, b.rate, b.burst)
+		if err != nil {
+			return err
+		}
+		if ok {
+			return nil
+		}
+
+		// Jitter keeps replicas waiting on an empty bucket from polling in lockstep.
+		delay := b.pollInterval + rand.N(b.pollInterval)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
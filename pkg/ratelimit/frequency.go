@@ -0,0 +1,121 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+
+	"github.com/HuseyinAsik/Notifications/pkg/logging"
+	"github.com/HuseyinAsik/Notifications/pkg/settings"
+	"github.com/HuseyinAsik/Notifications/repository"
+	"go.uber.org/zap"
+)
+
+const (
+	ScopeRecipient = "recipient"
+	ScopeTenant    = "tenant"
+
+	PolicyDefer  = "defer"
+	PolicyReject = "reject"
+
+	// DefaultTenant keys the tenant cap of notifications that do not belong to a tenant.
+	DefaultTenant = "default"
+
+	// chargeRetention is how long a notification is remembered as counted, so its retries and
+	// redeliveries are not counted again while it can still be attempted.
+	chargeRetention = 7 * 24 * time.Hour
+)
+
+type Cap struct {
+	Scope  string
+	Limit  int
+	Window time.Duration
+}
+
+// FrequencyCaps enforces fixed-window message caps with counters shared by every worker replica.
+type FrequencyCaps struct {
+	counters      repository.CounterRepository
+	caps          []Cap
+	Policy        string
+	purgeInterval time.Duration
+	logger        *logging.LogWrapper
+}
+
+func NewFrequencyCaps(counters repository.CounterRepository, config *settings.FrequencyCap, logger *logging.LogWrapper) *FrequencyCaps {
+	f := &FrequencyCaps{
+		counters:      counters,
+		Policy:        config.Policy,
+		purgeInterval: config.PurgeInterval,
+		logger:        logger,
+	}
+	if config.RecipientLimit > 0 {
+		f.caps = append(f.caps, Cap{Scope: ScopeRecipient, Limit: config.RecipientLimit, Window: config.RecipientWindow})
+	}
+	if config.TenantLimit > 0 {
+		f.caps = append(f.caps, Cap{Scope: ScopeTenant, Limit: config.TenantLimit, Window: config.TenantWindow})
+	}
+
+	return f
+}
+
+func (f *FrequencyCaps) Enabled() bool {
+	return f != nil && len(f.caps) > 0
+}
+
+// Allow counts the notification once against every cap, using keys to find the counter of each
+// scope; a notification already counted is not counted again. When a cap is exhausted the counts
+// taken by this call are given back and the end of that cap's window is returned, which is when
+// the message may be tried again.
+func (f *FrequencyCaps) Allow(ctx context.Context, channel, notificationId string, keys map[string]string, now time.Time) (bool, time.Time, error) {
+	var counted []string
+
+	release := func() {
+		for _, key := range counted {
+			if err := f.counters.Decrement(ctx, key, notificationId); err != nil {
+				f.logger.Error(ctx, "FrequencyCaps Decrement Err", zap.Error(err), zap.String("key", key))
+			}
+		}
+	}
+
+	for _, c := range f.caps {
+		value := keys[c.Scope]
+		if value == "" {
+			continue
+		}
+
+		key := c.Scope + ":" + channel + ":" + value
+		windowStart := now.UTC().Truncate(c.Window)
+		windowEnd := windowStart.Add(c.Window)
+
+		allowed, charged, err := f.counters.Increment(ctx, key, notificationId, windowStart, windowEnd, now.UTC().Add(chargeRetention), c.Limit)
+		if err != nil {
+			release()
+			return false, time.Time{}, err
+		}
+		if !allowed {
+			release()
+			return false, windowEnd, nil
+		}
+		if charged {
+			counted = append(counted, key)
+		}
+	}
+
+	return true, time.Time{}, nil
+}
+
+// RunPurge deletes counters of windows that have ended and charges past their retention.
+func (f *FrequencyCaps) RunPurge(ctx context.Context) {
+	ticker := time.NewTicker(f.purgeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := f.counters.DeleteExpired(ctx, time.Now().UTC()); err != nil {
+				f.logger.Error(ctx, "FrequencyCaps DeleteExpired Err", zap.Error(err))
+			}
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/HuseyinAsik/Notifications/pkg/logging"
+	"github.com/HuseyinAsik/Notifications/pkg/settings"
+	"github.com/HuseyinAsik/Notifications/repository"
+	"go.uber.org/zap"
+)
+
+type fakeCounters struct {
+	repository.CounterRepository
+	counts  map[string]int
+	charges map[string]bool
+}
+
+func newFakeCounters() *fakeCounters {
+	return &fakeCounters{counts: map[string]int{}, charges: map[string]bool{}}
+}
+
+func (f *fakeCounters) Increment(ctx context.Context, key, notificationId string, windowStart, expiresAt, chargedUntil time.Time, limit int) (bool, bool, error) {
+	if f.charges[key+"/"+notificationId] {
+		return true, false, nil
+	}
+	if f.counts[key] >= limit {
+		return false, false, nil
+	}
+	f.charges[key+"/"+notificationId] = true
+	f.counts[key]++
+	return true, true, nil
+}
+
+func (f *fakeCounters) Decrement(ctx context.Context, key, notificationId string) error {
+	if f.charges[key+"/"+notificationId] {
+		delete(f.charges, key+"/"+notificationId)
+		f.counts[key]--
+	}
+	return nil
+}
+
+func newTestCaps(counters repository.CounterRepository, recipientLimit, tenantLimit int) *FrequencyCaps {
+	return NewFrequencyCaps(counters, &settings.FrequencyCap{
+		RecipientLimit:  recipientLimit,
+		RecipientWindow: time.Hour,
+		TenantLimit:     tenantLimit,
+		TenantWindow:    time.Hour,
+		Policy:          PolicyDefer,
+	}, &logging.LogWrapper{ZapLogger: zap.NewNop()})
+}
+
+func TestFrequencyCapsChargeOncePerNotification(t *testing.T) {
+	counters := newFakeCounters()
+	caps := newTestCaps(counters, 2, 0)
+	keys := map[string]string{ScopeRecipient: "user@example.com"}
+	now := time.Now()
+
+	for range 3 {
+		allowed, _, err := caps.Allow(context.Background(), "email", "n1", keys, now)
+		if err != nil || !allowed {
+			t.Fatalf("Allow(n1) = %v, %v; want true, nil", allowed, err)
+		}
+	}
+	if got := counters.counts["recipient:email:user@example.com"]; got != 1 {
+		t.Fatalf("count = %d after repeated attempts of one notification, want 1", got)
+	}
+
+	if allowed, _, _ := caps.Allow(context.Background(), "email", "n2", keys, now); !allowed {
+		t.Fatal("Allow(n2) = false, want true")
+	}
+	allowed, retryAt, err := caps.Allow(context.Background(), "email", "n3", keys, now)
+	if err != nil || allowed {
+		t.Fatalf("Allow(n3) = %v, %v; want false, nil", allowed, err)
+	}
+	if want := now.UTC().Truncate(time.Hour).Add(time.Hour); !retryAt.Equal(want) {
+		t.Errorf("retryAt = %v, want %v", retryAt, want)
+	}
+}
+
+func TestFrequencyCapsRefundOnlyWhatTheCallCharged(t *testing.T) {
+	counters := newFakeCounters()
+	caps := newTestCaps(counters, 5, 1)
+	now := time.Now()
+
+	// n1 takes the only tenant slot; a retry of n1 is still allowed and keeps its charges.
+	keys := map[string]string{ScopeRecipient: "a@example.com", ScopeTenant: "t1"}
+	if allowed, _, _ := caps.Allow(context.Background(), "email", "n1", keys, now); !allowed {
+		t.Fatal("Allow(n1) = false, want true")
+	}
+	if allowed, _, _ := caps.Allow(context.Background(), "email", "n1", keys, now); !allowed {
+		t.Fatal("retried Allow(n1) = false, want true")
+	}
+
+	// n2 is counted for its recipient, then refused by the tenant cap and refunded.
+	other := map[string]string{ScopeRecipient: "b@example.com", ScopeTenant: "t1"}
+	if allowed, _, _ := caps.Allow(context.Background(), "email", "n2", other, now); allowed {
+		t.Fatal("Allow(n2) = true, want false")
+	}
+
+	if got := counters.counts["recipient:email:a@example.com"]; got != 1 {
+		t.Errorf("recipient count of n1 = %d, want 1", got)
+	}
+	if got := counters.counts["recipient:email:b@example.com"]; got != 0 {
+		t.Errorf("recipient count of n2 = %d, want 0 after the refund", got)
+	}
+	if got := counters.counts["tenant:email:t1"]; got != 1 {
+		t.Errorf("tenant count = %d, want 1", got)
+	}
+}
@@ -2,6 +2,7 @@ package settings
 
 import (
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -33,3 +34,510 @@ type Kafka struct {
 	BrokersStr string `email_worker_validate:"required" sms_worker_validate:"required" push_worker_validate:"required" outbox_publisher_validate:"required"`
 	Brokers    []string
 }
+
+type Smtp struct {
+	Host           string `email_worker_validate:"required"`
+	PortStr        string `email_worker_validate:"required,numeric"`
+	Port           int
+	Username       string
+	Password       string
+	From           string `email_worker_validate:"required"`
+	ReplyTo        string
+	TLSMode        string `email_worker_validate:"omitempty,oneof=starttls tls none"`
+	AuthMechanism  string `email_worker_validate:"omitempty,oneof=plain login"`
+	DefaultSubject string
+	TimeoutStr     string
+	Timeout        time.Duration
+}
+
+func (s *Smtp) Load() {
+	s.Port, _ = strconv.Atoi(s.PortStr)
+	if s.TLSMode == "" {
+		s.TLSMode = "starttls"
+	}
+	if s.AuthMechanism == "" {
+		s.AuthMechanism = "plain"
+	}
+	timeout, err := time.ParseDuration(s.TimeoutStr)
+	if err != nil || timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	s.Timeout = timeout
+}
+
+type Sms struct {
+	GatewayURL         string `sms_worker_validate:"required"`
+	Method             string `sms_worker_validate:"omitempty,oneof=GET POST PUT"`
+	AuthHeader         string
+	AuthValue          string
+	Sender             string
+	DefaultCountryCode string `sms_worker_validate:"required,numeric"`
+	ToField            string
+	MessageField       string
+	SenderField        string
+	ReferenceField     string
+	MessageIdField     string
+	ErrorCodeField     string
+	SuccessCodesStr    string
+	SuccessCodes       []string
+	RetryableCodesStr  string
+	RetryableCodes     []string
+	PermanentCodesStr  string
+	PermanentCodes     []string
+	TimeoutStr         string
+	Timeout            time.Duration
+}
+
+func (s *Sms) Load() {
+	if s.Method == "" {
+		s.Method = "POST"
+	}
+	if s.ToField == "" {
+		s.ToField = "to"
+	}
+	if s.MessageField == "" {
+		s.MessageField = "message"
+	}
+	if s.SenderField == "" {
+		s.SenderField = "from"
+	}
+	if s.MessageIdField == "" {
+		s.MessageIdField = "messageId"
+	}
+	if s.ErrorCodeField == "" {
+		s.ErrorCodeField = "errorCode"
+	}
+	// An absent error code always means success; these are the codes gateways send
+	// alongside an accepted message, such as 0.
+	s.SuccessCodes = splitList(s.SuccessCodesStr)
+	if len(s.SuccessCodes) == 0 {
+		s.SuccessCodes = []string{"0"}
+	}
+	s.RetryableCodes = splitList(s.RetryableCodesStr)
+	s.PermanentCodes = splitList(s.PermanentCodesStr)
+	timeout, err := time.ParseDuration(s.TimeoutStr)
+	if err != nil || timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	s.Timeout = timeout
+}
+
+func splitList(value string) []string {
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+type Push struct {
+	DefaultPlatform    string `push_worker_validate:"omitempty,oneof=fcm apns"`
+	FCMEndpoint        string
+	FCMProjectId       string `push_worker_validate:"required_with=FCMCredentialsFile"`
+	FCMCredentialsFile string
+	FCMTokenURL        string
+	APNsEndpoint       string
+	APNsKeyFile        string
+	APNsKeyId          string `push_worker_validate:"required_with=APNsKeyFile"`
+	APNsTeamId         string `push_worker_validate:"required_with=APNsKeyFile"`
+	APNsTopic          string `push_worker_validate:"required_with=APNsKeyFile"`
+	TimeoutStr         string
+	Timeout            time.Duration
+}
+
+func (s *Push) Load() {
+	if s.DefaultPlatform == "" {
+		s.DefaultPlatform = "fcm"
+	}
+	if s.FCMEndpoint == "" {
+		s.FCMEndpoint = "https://fcm.googleapis.com"
+	}
+	if s.APNsEndpoint == "" {
+		s.APNsEndpoint = "https://api.push.apple.com"
+	}
+	timeout, err := time.ParseDuration(s.TimeoutStr)
+	if err != nil || timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	s.Timeout = timeout
+}
+
+type Retry struct {
+	BaseDelayStr   string
+	BaseDelay      time.Duration
+	MaxDelayStr    string
+	MaxDelay       time.Duration
+	MaxAttemptsStr string `email_worker_validate:"omitempty,numeric" sms_worker_validate:"omitempty,numeric" push_worker_validate:"omitempty,numeric" outbox_publisher_validate:"omitempty,numeric"`
+	MaxAttempts    int
+}
+
+func (s *Retry) Load() {
+	baseDelay, err := time.ParseDuration(s.BaseDelayStr)
+	if err != nil || baseDelay <= 0 {
+		baseDelay = 5 * time.Second
+	}
+	s.BaseDelay = baseDelay
+
+	maxDelay, err := time.ParseDuration(s.MaxDelayStr)
+	if err != nil || maxDelay < baseDelay {
+		maxDelay = 10 * time.Minute
+	}
+	s.MaxDelay = maxDelay
+
+	maxAttempts, err := strconv.Atoi(s.MaxAttemptsStr)
+	if err != nil || maxAttempts <= 0 {
+		maxAttempts = 7
+	}
+	s.MaxAttempts = maxAttempts
+}
+
+type Scheduler struct {
+	EnabledStr    string `outbox_publisher_validate:"omitempty,boolean"`
+	Enabled       bool
+	IntervalStr   string
+	Interval      time.Duration
+	MaxHorizonStr string
+	MaxHorizon    time.Duration
+}
+
+func (s *Scheduler) Load() {
+	s.Enabled = true
+	if enabled, err := strconv.ParseBool(s.EnabledStr); err == nil {
+		s.Enabled = enabled
+	}
+
+	interval, err := time.ParseDuration(s.IntervalStr)
+	if err != nil || interval <= 0 {
+		interval = time.Second
+	}
+	s.Interval = interval
+
+	maxHorizon, err := time.ParseDuration(s.MaxHorizonStr)
+	if err != nil || maxHorizon <= 0 {
+		maxHorizon = 30 * 24 * time.Hour
+	}
+	s.MaxHorizon = maxHorizon
+}
+
+type Reaper struct {
+	IntervalStr string
+	Interval    time.Duration
+	LeaseStr    string
+	Lease       time.Duration
+}
+
+func (s *Reaper) Load() {
+	interval, err := time.ParseDuration(s.IntervalStr)
+	if err != nil || interval <= 0 {
+		interval = time.Minute
+	}
+	s.Interval = interval
+
+	lease, err := time.ParseDuration(s.LeaseStr)
+	if err != nil || lease <= 0 {
+		lease = 10 * time.Minute
+	}
+	s.Lease = lease
+}
+
+type Webhook struct {
+	EnabledStr     string `outbox_publisher_validate:"omitempty,boolean"`
+	Enabled        bool
+	IntervalStr    string
+	Interval       time.Duration
+	TimeoutStr     string
+	Timeout        time.Duration
+	ConcurrencyStr string `outbox_publisher_validate:"omitempty,numeric"`
+	Concurrency    int
+}
+
+func (s *Webhook) Load() {
+	s.Enabled = true
+	if enabled, err := strconv.ParseBool(s.EnabledStr); err == nil {
+		s.Enabled = enabled
+	}
+
+	interval, err := time.ParseDuration(s.IntervalStr)
+	if err != nil || interval <= 0 {
+		interval = time.Second
+	}
+	s.Interval = interval
+
+	timeout, err := time.ParseDuration(s.TimeoutStr)
+	if err != nil || timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	s.Timeout = timeout
+
+	concurrency, err := strconv.Atoi(s.ConcurrencyStr)
+	if err != nil || concurrency <= 0 {
+		concurrency = 10
+	}
+	s.Concurrency = concurrency
+}
+
+type PriorityScheduling struct {
+	WeightsStr          string
+	HighWeight          int
+	MediumWeight        int
+	LowWeight           int
+	MinSharePercentStr  string `email_worker_validate:"omitempty,numeric" sms_worker_validate:"omitempty,numeric" push_worker_validate:"omitempty,numeric"`
+	MinSharePercent     int
+	AgingStr            string
+	Aging               time.Duration
+	UrgentCategoriesStr string
+	UrgentCategories    []string
+}
+
+func (s *PriorityScheduling) Load() {
+	s.HighWeight, s.MediumWeight, s.LowWeight = 70, 20, 10
+	if weights := strings.Split(s.WeightsStr, "/"); len(weights) == 3 {
+		high, highErr := strconv.Atoi(strings.TrimSpace(weights[0]))
+		medium, mediumErr := strconv.Atoi(strings.TrimSpace(weights[1]))
+		low, lowErr := strconv.Atoi(strings.TrimSpace(weights[2]))
+		if highErr == nil && mediumErr == nil && lowErr == nil && high >= 0 && medium >= 0 && low >= 0 && high+medium+low > 0 {
+			s.HighWeight, s.MediumWeight, s.LowWeight = high, medium, low
+		}
+	}
+
+	minShare, err := strconv.Atoi(s.MinSharePercentStr)
+	if err != nil || minShare < 0 || minShare > 33 {
+		minShare = 5
+	}
+	s.MinSharePercent = minShare
+
+	aging, err := time.ParseDuration(s.AgingStr)
+	if err != nil || aging <= 0 {
+		aging = 30 * time.Second
+	}
+	s.Aging = aging
+
+	s.UrgentCategories = splitList(s.UrgentCategoriesStr)
+}
+
+type Metrics struct {
+	Addr string
+}
+
+func (s *Metrics) Load() {
+	if s.Addr == "" {
+		s.Addr = ":9090"
+	}
+}
+
+type Tracing struct {
+	Exporter       string `notification_api_validate:"omitempty,oneof=none stdout otlp" email_worker_validate:"omitempty,oneof=none stdout otlp" sms_worker_validate:"omitempty,oneof=none stdout otlp" push_worker_validate:"omitempty,oneof=none stdout otlp" outbox_publisher_validate:"omitempty,oneof=none stdout otlp"`
+	SampleRatioStr string `notification_api_validate:"omitempty,numeric" email_worker_validate:"omitempty,numeric" sms_worker_validate:"omitempty,numeric" push_worker_validate:"omitempty,numeric" outbox_publisher_validate:"omitempty,numeric"`
+	SampleRatio    float64
+}
+
+func (s *Tracing) Load() {
+	if s.Exporter == "" {
+		s.Exporter = "none"
+	}
+
+	ratio, err := strconv.ParseFloat(s.SampleRatioStr, 64)
+	if err != nil || ratio < 0 || ratio > 1 {
+		ratio = 1
+	}
+	s.SampleRatio = ratio
+}
+
+type Idempotency struct {
+	RetentionStr     string
+	Retention        time.Duration
+	PurgeIntervalStr string
+	PurgeInterval    time.Duration
+	LockTimeoutStr   string
+	LockTimeout      time.Duration
+}
+
+func (s *Idempotency) Load() {
+	retention, err := time.ParseDuration(s.RetentionStr)
+	if err != nil || retention <= 0 {
+		retention = 24 * time.Hour
+	}
+	s.Retention = retention
+
+	purgeInterval, err := time.ParseDuration(s.PurgeIntervalStr)
+	if err != nil || purgeInterval <= 0 {
+		purgeInterval = time.Hour
+	}
+	s.PurgeInterval = purgeInterval
+
+	lockTimeout, err := time.ParseDuration(s.LockTimeoutStr)
+	if err != nil || lockTimeout <= 0 {
+		lockTimeout = time.Minute
+	}
+	s.LockTimeout = lockTimeout
+}
+
+type Template struct {
+	DefaultLocale string
+}
+
+func (s *Template) Load() {
+	if s.DefaultLocale == "" {
+		s.DefaultLocale = "en"
+	}
+}
+
+type FrequencyCap struct {
+	RecipientStr     string
+	RecipientLimit   int
+	RecipientWindow  time.Duration
+	TenantStr        string
+	TenantLimit      int
+	TenantWindow     time.Duration
+	Policy           string `email_worker_validate:"omitempty,oneof=defer reject" sms_worker_validate:"omitempty,oneof=defer reject" push_worker_validate:"omitempty,oneof=defer reject"`
+	PurgeIntervalStr string
+	PurgeInterval    time.Duration
+}
+
+func (s *FrequencyCap) Load() {
+	s.RecipientLimit, s.RecipientWindow = parseCap(s.RecipientStr)
+	s.TenantLimit, s.TenantWindow = parseCap(s.TenantStr)
+	if s.Policy == "" {
+		s.Policy = "defer"
+	}
+
+	purgeInterval, err := time.ParseDuration(s.PurgeIntervalStr)
+	if err != nil || purgeInterval <= 0 {
+		purgeInterval = 10 * time.Minute
+	}
+	s.PurgeInterval = purgeInterval
+}
+
+// parseCap reads a "limit/window" cap such as "3/1h". Invalid or empty values disable the cap.
+func parseCap(value string) (int, time.Duration) {
+	limitStr, windowStr, ok := strings.Cut(value, "/")
+	if !ok {
+		return 0, 0
+	}
+	limit, err := strconv.Atoi(strings.TrimSpace(limitStr))
+	if err != nil || limit <= 0 {
+		return 0, 0
+	}
+	window, err := time.ParseDuration(strings.TrimSpace(windowStr))
+	if err != nil || window <= 0 {
+		return 0, 0
+	}
+	return limit, window
+}
+
+type RateLimit struct {
+	Backend  string `email_worker_validate:"omitempty,oneof=postgres local" sms_worker_validate:"omitempty,oneof=postgres local" push_worker_validate:"omitempty,oneof=postgres local"`
+	Account  string
+	RateStr  string `email_worker_validate:"omitempty,numeric" sms_worker_validate:"omitempty,numeric" push_worker_validate:"omitempty,numeric"`
+	Rate     float64
+	BurstStr string `email_worker_validate:"omitempty,numeric" sms_worker_validate:"omitempty,numeric" push_worker_validate:"omitempty,numeric"`
+	Burst    int
+}
+
+func (s *RateLimit) Load() {
+	if s.Backend == "" {
+		s.Backend = "postgres"
+	}
+	if s.Account == "" {
+		s.Account = "default"
+	}
+
+	rate, err := strconv.ParseFloat(s.RateStr, 64)
+	if err != nil || rate <= 0 {
+		rate = 100
+	}
+	s.Rate = rate
+
+	burst, err := strconv.Atoi(s.BurstStr)
+	if err != nil || burst <= 0 {
+		burst = max(int(rate), 1)
+	}
+	s.Burst = burst
+}
+
+type Receipt struct {
+	SmsSecret          string
+	SmsSignatureHeader string
+	SmsTimestampHeader string
+	SmsMessageIdField  string
+	SmsStatusField     string
+	SmsReasonField     string
+	SmsTimestampField  string
+	SmsStatusMapStr    string
+	SmsStatusMap       map[string]string
+	MailgunSigningKey  string
+	SendgridPublicKey  string
+	ToleranceStr       string
+	Tolerance          time.Duration
+}
+
+func (s *Receipt) Load() {
+	if s.SmsSignatureHeader == "" {
+		s.SmsSignatureHeader = "X-Signature"
+	}
+	if s.SmsMessageIdField == "" {
+		s.SmsMessageIdField = "messageId"
+	}
+	if s.SmsStatusField == "" {
+		s.SmsStatusField = "status"
+	}
+	if s.SmsReasonField == "" {
+		s.SmsReasonField = "error"
+	}
+	if s.SmsTimestampField == "" {
+		s.SmsTimestampField = "timestamp"
+	}
+	tolerance, err := time.ParseDuration(s.ToleranceStr)
+	if err != nil || tolerance <= 0 {
+		tolerance = 5 * time.Minute
+	}
+	s.Tolerance = tolerance
+	if s.SmsStatusMapStr == "" {
+		s.SmsStatusMapStr = "DELIVRD:delivered,DELIVERED:delivered,UNDELIV:bounced,UNDELIVERED:bounced," +
+			"REJECTD:bounced,REJECTED:bounced,EXPIRED:bounced,FAILED:bounced,READ:read"
+	}
+
+	// Gateway statuses are matched case-insensitively.
+	s.SmsStatusMap = map[string]string{}
+	for _, item := range splitList(s.SmsStatusMapStr) {
+		if code, status, ok := strings.Cut(item, ":"); ok {
+			s.SmsStatusMap[strings.ToUpper(strings.TrimSpace(code))] = strings.TrimSpace(status)
+		}
+	}
+}
+
+type Auth struct {
+	AdminApiKey string
+}
+
+type Jwt struct {
+	JwksURL            string `notification_api_validate:"omitempty,url"`
+	JwksFile           string `notification_api_validate:"excluded_with=JwksURL"`
+	JwksSource         string
+	Issuer             string
+	Audience           string
+	TenantClaim        string
+	ScopeClaim         string
+	RefreshIntervalStr string
+	RefreshInterval    time.Duration
+}
+
+func (s *Jwt) Load() {
+	s.JwksSource = s.JwksURL
+	if s.JwksSource == "" {
+		s.JwksSource = s.JwksFile
+	}
+	if s.TenantClaim == "" {
+		s.TenantClaim = "tenant_id"
+	}
+	if s.ScopeClaim == "" {
+		s.ScopeClaim = "scope"
+	}
+	refreshInterval, err := time.ParseDuration(s.RefreshIntervalStr)
+	if err != nil || refreshInterval <= 0 {
+		refreshInterval = 15 * time.Minute
+	}
+	s.RefreshInterval = refreshInterval
+}
@@ -0,0 +1,57 @@
+package timeline
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/HuseyinAsik/Notifications/models"
+	"github.com/HuseyinAsik/Notifications/pkg/logging"
+	"github.com/HuseyinAsik/Notifications/repository"
+)
+
+const (
+	SourceApi             = "api"
+	SourceOutboxPublisher = "outbox-publisher"
+	SourceWorker          = "worker"
+)
+
+// Recorder appends status transitions to the notification event timeline, stamped with the
+// component and host that made them. The timeline is informational: a failed write is
+// logged and never fails the transition itself.
+type Recorder struct {
+	repo     repository.NotificationEventRepository
+	source   string
+	hostname string
+	logger   *logging.LogWrapper
+}
+
+func NewRecorder(repo repository.NotificationEventRepository, source, hostname string, logger *logging.LogWrapper) *Recorder {
+	return &Recorder{
+		repo:     repo,
+		source:   source,
+		hostname: hostname,
+		logger:   logger,
+	}
+}
+
+func (r *Recorder) Record(ctx context.Context, events ...models.NotificationEvent) {
+	if len(events) == 0 {
+		return
+	}
+
+	now := time.Now().UTC()
+	for i := range events {
+		events[i].Id = uuid.NewString()
+		events[i].Source = r.source
+		events[i].Hostname = r.hostname
+		events[i].CreatedAt = now
+	}
+
+	if err := r.repo.Append(ctx, events); err != nil {
+		r.logger.Error(ctx, "Timeline Append Err", zap.Error(err), zap.String("notificationId", events[0].NotificationId),
+			zap.String("status", events[0].Status), zap.Int("count", len(events)))
+	}
+}
@@ -0,0 +1,43 @@
+package worker
+
+import (
+	"math/rand/v2"
+	"time"
+
+	"github.com/HuseyinAsik/Notifications/pkg/settings"
+)
+
+type Backoff struct {
+	Base        time.Duration
+	Cap         time.Duration
+	MaxAttempts int
+}
+
+func NewBackoff(config *settings.Retry) Backoff {
+	return Backoff{
+		Base:        config.BaseDelay,
+		Cap:         config.MaxDelay,
+		MaxAttempts: config.MaxAttempts,
+	}
+}
+
+// Next returns the delay before the attempt following the given number of attempts:
+// base * 2^(attempts-1) capped at Cap, of which the upper half is randomised so that
+// failures of the same batch do not retry in lockstep.
+func (b Backoff) Next(attempts int) time.Duration {
+	delay := b.Cap
+	if attempts < 1 {
+		attempts = 1
+	}
+	if shift := attempts - 1; shift < 32 {
+		if exp := b.Base << shift; exp > 0 && exp < b.Cap {
+			delay = exp
+		}
+	}
+
+	half := delay / 2
+	if half <= 0 {
+		return delay
+	}
+	return half + rand.N(half)
+}
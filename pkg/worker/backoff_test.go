@@ -0,0 +1,47 @@
+package worker
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackoffNext(t *testing.T) {
+	backoff := Backoff{Base: time.Second, Cap: time.Minute, MaxAttempts: 5}
+
+	tests := []struct {
+		name     string
+		attempts int
+		want     time.Duration
+	}{
+		{name: "first attempt", attempts: 1, want: time.Second},
+		{name: "below one", attempts: 0, want: time.Second},
+		{name: "doubles", attempts: 2, want: 2 * time.Second},
+		{name: "doubles again", attempts: 4, want: 8 * time.Second},
+		{name: "capped", attempts: 7, want: time.Minute},
+		{name: "shift overflow", attempts: 100, want: time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The upper half of the delay is jittered: every result lies in [want/2, want).
+			for range 100 {
+				got := backoff.Next(tt.attempts)
+				if got < tt.want/2 || got >= tt.want {
+					t.Fatalf("Next(%d) = %v, want within [%v, %v)", tt.attempts, got, tt.want/2, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestBackoffNextJitters(t *testing.T) {
+	backoff := Backoff{Base: time.Second, Cap: time.Minute}
+
+	seen := map[time.Duration]bool{}
+	for range 20 {
+		seen[backoff.Next(3)] = true
+	}
+	if len(seen) < 2 {
+		t.Errorf("Next(3) returned the same delay %d times", 20)
+	}
+}
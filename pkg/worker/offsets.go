@@ -0,0 +1,107 @@
+package worker
+
+import (
+	"sync"
+)
+
+type partition struct {
+	topic string
+	id    int
+}
+
+// offsets tracks the messages fetched from every partition until they are finished. Messages
+// of a partition are handled in parallel, so one may finish before an earlier one; its offset
+// is committed only once every message before it on the partition is finished too, so a
+// message that is still buffered, in flight or to be tried again is never skipped.
+type offsets struct {
+	mu       sync.Mutex
+	open     map[partition][]int64
+	finished map[partition]map[int64]FetchedMessage
+}
+
+func newOffsets() *offsets {
+	return &offsets{
+		open:     map[partition][]int64{},
+		finished: map[partition]map[int64]FetchedMessage{},
+	}
+}
+
+func partitionOf(m FetchedMessage) partition {
+	return partition{topic: m.Message.Topic, id: m.Message.Partition}
+}
+
+// Fetched starts tracking a message that was just fetched.
+func (o *offsets) Fetched(m FetchedMessage) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	p := partitionOf(m)
+	o.open[p] = append(o.open[p], m.Message.Offset)
+}
+
+// Finish records that the message is done with and its offset may be committed.
+func (o *offsets) Finish(m FetchedMessage) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	p := partitionOf(m)
+	for i, offset := range o.open[p] {
+		if offset == m.Message.Offset {
+			o.open[p] = append(o.open[p][:i], o.open[p][i+1:]...)
+			break
+		}
+	}
+	if o.finished[p] == nil {
+		o.finished[p] = map[int64]FetchedMessage{}
+	}
+	o.finished[p][m.Message.Offset] = m
+}
+
+// Open reports whether the message is not finished yet.
+func (o *offsets) Open(m FetchedMessage) bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	for _, offset := range o.open[partitionOf(m)] {
+		if offset == m.Message.Offset {
+			return true
+		}
+	}
+	return false
+}
+
+// Committable returns, for every partition, the last finished message below its lowest open
+// offset and forgets the finished messages up to it.
+func (o *offsets) Committable() []FetchedMessage {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	var commits []FetchedMessage
+	for p, finished := range o.finished {
+		lowest := int64(-1)
+		for _, offset := range o.open[p] {
+			if lowest < 0 || offset < lowest {
+				lowest = offset
+			}
+		}
+
+		var last *FetchedMessage
+		for offset, m := range finished {
+			if lowest >= 0 && offset >= lowest {
+				continue
+			}
+			if last == nil || offset > last.Message.Offset {
+				last = &m
+			}
+			delete(finished, offset)
+		}
+		if last != nil {
+			commits = append(commits, *last)
+		}
+		if len(finished) == 0 {
+			delete(o.finished, p)
+		}
+	}
+
+	return commits
+}
@@ -0,0 +1,110 @@
+package worker
+
+import (
+	"testing"
+
+	"github.com/segmentio/kafka-go"
+)
+
+func fetchedAt(topic string, partition int, offset int64) FetchedMessage {
+	return FetchedMessage{Message: kafka.Message{Topic: topic, Partition: partition, Offset: offset}}
+}
+
+func committed(commits []FetchedMessage) map[partition]int64 {
+	got := map[partition]int64{}
+	for _, m := range commits {
+		got[partitionOf(m)] = m.Message.Offset
+	}
+	return got
+}
+
+func TestOffsetsCommitUpToLowestOpenOffset(t *testing.T) {
+	o := newOffsets()
+	messages := []FetchedMessage{
+		fetchedAt("sms_high", 0, 10),
+		fetchedAt("sms_high", 0, 11),
+		fetchedAt("sms_high", 0, 12),
+		fetchedAt("sms_high", 1, 5),
+	}
+	for _, m := range messages {
+		o.Fetched(m)
+	}
+
+	// 11 and 12 finish before 10; partition 1 is independent.
+	o.Finish(messages[1])
+	o.Finish(messages[2])
+	o.Finish(messages[3])
+
+	got := committed(o.Committable())
+	if _, ok := got[partition{"sms_high", 0}]; ok {
+		t.Errorf("partition 0 committed at %d while offset 10 is open", got[partition{"sms_high", 0}])
+	}
+	if got[partition{"sms_high", 1}] != 5 {
+		t.Errorf("partition 1 committed at %v, want 5", got)
+	}
+	if !o.Open(messages[0]) || o.Open(messages[1]) {
+		t.Error("Open() does not match the finished messages")
+	}
+
+	o.Finish(messages[0])
+	got = committed(o.Committable())
+	if len(got) != 1 || got[partition{"sms_high", 0}] != 12 {
+		t.Errorf("Committable() = %v, want partition 0 at 12", got)
+	}
+	if commits := o.Committable(); len(commits) != 0 {
+		t.Errorf("Committable() = %v after everything was committed, want nothing", committed(commits))
+	}
+}
+
+func TestOffsetsCommitStopsBeforeBufferedMessages(t *testing.T) {
+	o := newOffsets()
+	for offset := int64(0); offset < 5; offset++ {
+		o.Fetched(fetchedAt("email_low", 0, offset))
+	}
+
+	// 0-1 were handled, 2 failed, 3 was handled, 4 is still buffered.
+	o.Finish(fetchedAt("email_low", 0, 0))
+	o.Finish(fetchedAt("email_low", 0, 1))
+	o.Finish(fetchedAt("email_low", 0, 3))
+
+	if got := committed(o.Committable()); got[partition{"email_low", 0}] != 1 {
+		t.Fatalf("Committable() = %v, want partition 0 at 1", got)
+	}
+
+	o.Finish(fetchedAt("email_low", 0, 2))
+	if got := committed(o.Committable()); got[partition{"email_low", 0}] != 3 {
+		t.Fatalf("Committable() = %v, want partition 0 at 3 while 4 is buffered", got)
+	}
+}
+
+func TestWorkerRequeuePutsOpenMessagesFirst(t *testing.T) {
+	high := &queue{priority: "high", reader: &kafka.Reader{}}
+	low := &queue{priority: "low", reader: &kafka.Reader{}}
+	w := &Worker{queues: []*queue{high, low}, offsets: newOffsets()}
+
+	at := func(q *queue, offset int64) FetchedMessage {
+		m := fetchedAt("sms_"+q.priority, 0, offset)
+		m.Reader = q.reader
+		w.offsets.Fetched(m)
+		return m
+	}
+	batch := []FetchedMessage{at(high, 1), at(high, 2), at(high, 3), at(low, 7)}
+	high.buffer = []FetchedMessage{at(high, 4)}
+
+	w.offsets.Finish(batch[0])
+	w.offsets.Finish(batch[3])
+
+	if got := w.requeue(batch); got != 2 {
+		t.Fatalf("requeue() = %d, want 2", got)
+	}
+	var offsets []int64
+	for _, m := range high.buffer {
+		offsets = append(offsets, m.Message.Offset)
+	}
+	if len(offsets) != 3 || offsets[0] != 2 || offsets[1] != 3 || offsets[2] != 4 {
+		t.Errorf("high buffer = %v, want [2 3 4]", offsets)
+	}
+	if len(low.buffer) != 0 {
+		t.Errorf("low buffer = %d messages, want 0", len(low.buffer))
+	}
+}
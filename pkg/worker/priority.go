@@ -0,0 +1,141 @@
+package worker
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+
+	gkafka "github.com/HuseyinAsik/Notifications/pkg/kafka"
+	"github.com/HuseyinAsik/Notifications/pkg/metrics"
+	"github.com/HuseyinAsik/Notifications/pkg/settings"
+)
+
+// Priorities configures how the worker shares each batch among its priority topics.
+type Priorities struct {
+	Weights          map[string]int
+	MinShare         int
+	Aging            time.Duration
+	UrgentCategories map[string]bool
+}
+
+func NewPriorities(config *settings.PriorityScheduling) Priorities {
+	urgent := map[string]bool{}
+	for _, category := range config.UrgentCategories {
+		urgent[category] = true
+	}
+
+	return Priorities{
+		Weights: map[string]int{
+			"high":   config.HighWeight,
+			"medium": config.MediumWeight,
+			"low":    config.LowWeight,
+		},
+		MinShare:         (batchSize*config.MinSharePercent + 99) / 100,
+		Aging:            config.Aging,
+		UrgentCategories: urgent,
+	}
+}
+
+// Urgent reports whether the message is of an urgent category and may skip the queue.
+func (p Priorities) Urgent(msg kafka.Message) bool {
+	if len(p.UrgentCategories) == 0 {
+		return false
+	}
+
+	var n struct {
+		Category string `json:"category"`
+	}
+	if err := json.Unmarshal(msg.Value, &n); err != nil {
+		return false
+	}
+	return p.UrgentCategories[n.Category]
+}
+
+// queue holds the messages fetched from one priority topic until the scheduler picks them.
+// Messages leave a queue in fetch order, so per-key order and offset commits stay in order.
+type queue struct {
+	priority string
+	reader   *kafka.Reader
+	buffer   []FetchedMessage
+}
+
+func newQueue(brokers []string, channel, priority, groupID string) *queue {
+	return &queue{
+		priority: priority,
+		reader:   gkafka.NewReader(brokers, channel+"_"+priority, groupID),
+	}
+}
+
+// schedule picks the next batch from the queues:
+//  1. Urgent messages at the head of any queue preempt everything else.
+//  2. Every queue holding messages gets at least the minimum share of the batch.
+//  3. The rest is shared by weight. A queue's weight is multiplied by one plus the number of
+//     aging intervals its oldest message has waited, so old low priority messages catch up.
+//  4. Slots a queue cannot fill go to the others in priority order.
+func (w *Worker) schedule(now time.Time) []FetchedMessage {
+	taken := make([]int, len(w.queues))
+	free := batchSize
+
+	for i, q := range w.queues {
+		for taken[i] < len(q.buffer) && free > 0 && w.priorities.Urgent(q.buffer[taken[i]].Message) {
+			taken[i]++
+			free--
+		}
+	}
+	slots := free
+
+	guaranteed := make([]int, len(w.queues))
+	for i, q := range w.queues {
+		guaranteed[i] = min(w.priorities.MinShare, len(q.buffer)-taken[i], free)
+		taken[i] += guaranteed[i]
+		free -= guaranteed[i]
+	}
+
+	weights := make([]float64, len(w.queues))
+	var total float64
+	for i, q := range w.queues {
+		if taken[i] == len(q.buffer) {
+			continue
+		}
+		weights[i] = float64(w.priorities.Weights[q.priority])
+		if w.priorities.Aging > 0 {
+			intervals := int64(now.Sub(q.buffer[taken[i]].Message.Time) / w.priorities.Aging)
+			weights[i] *= float64(1 + max(intervals, 0))
+		}
+		total += weights[i]
+	}
+	for i, q := range w.queues {
+		if total == 0 {
+			break
+		}
+		quota := int(float64(slots)*weights[i]/total) - guaranteed[i]
+		n := max(min(quota, len(q.buffer)-taken[i], free), 0)
+		taken[i] += n
+		free -= n
+	}
+
+	for i, q := range w.queues {
+		n := min(len(q.buffer)-taken[i], free)
+		taken[i] += n
+		free -= n
+	}
+
+	var batch []FetchedMessage
+	for i, q := range w.queues {
+		for _, m := range q.buffer[:taken[i]] {
+			metrics.PriorityWait.WithLabelValues(w.channel, q.priority).Observe(now.Sub(m.Message.Time).Seconds())
+		}
+		batch = append(batch, q.buffer[:taken[i]]...)
+		q.buffer = append([]FetchedMessage(nil), q.buffer[taken[i]:]...)
+
+		lag := 0.0
+		if len(q.buffer) > 0 {
+			lag = now.Sub(q.buffer[0].Message.Time).Seconds()
+		}
+		metrics.PriorityLag.WithLabelValues(w.channel, q.priority).Set(lag)
+		metrics.PriorityBuffered.WithLabelValues(w.channel, q.priority).Set(float64(len(q.buffer)))
+	}
+
+	return batch
+}
@@ -0,0 +1,151 @@
+package worker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+
+	"github.com/HuseyinAsik/Notifications/pkg/settings"
+)
+
+// newScheduleWorker builds a worker with high, medium and low queues holding the given number
+// of messages, all fetched at now unless the queue's age says otherwise.
+func newScheduleWorker(weights string, urgent string, now time.Time, sizes [3]int, ages [3]time.Duration) *Worker {
+	config := &settings.PriorityScheduling{WeightsStr: weights, UrgentCategoriesStr: urgent}
+	config.Load()
+
+	w := &Worker{channel: "sms", priorities: NewPriorities(config)}
+	for i, priority := range []string{"high", "medium", "low"} {
+		q := &queue{priority: priority}
+		for offset := 0; offset < sizes[i]; offset++ {
+			q.buffer = append(q.buffer, FetchedMessage{Message: kafka.Message{
+				Topic:  "sms_" + priority,
+				Offset: int64(offset),
+				Time:   now.Add(-ages[i]),
+				Value:  []byte(`{"category":"marketing"}`),
+			}})
+		}
+		w.queues = append(w.queues, q)
+	}
+
+	return w
+}
+
+func countByTopic(batch []FetchedMessage) map[string]int {
+	counts := map[string]int{}
+	for _, m := range batch {
+		counts[m.Message.Topic]++
+	}
+	return counts
+}
+
+func TestSchedule(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name    string
+		weights string
+		sizes   [3]int
+		ages    [3]time.Duration
+		want    [3]int
+	}{
+		{
+			name:    "shared by weight",
+			weights: "70/20/10",
+			sizes:   [3]int{200, 200, 200},
+			want:    [3]int{70, 20, 10},
+		},
+		{
+			name:    "minimum share for a tiny weight",
+			weights: "98/1/1",
+			sizes:   [3]int{200, 200, 200},
+			want:    [3]int{90, 5, 5},
+		},
+		{
+			name:    "unused slots go to the others in priority order",
+			weights: "70/20/10",
+			sizes:   [3]int{10, 200, 200},
+			want:    [3]int{10, 80, 10},
+		},
+		{
+			name:    "aging lets starved low priority messages catch up",
+			weights: "70/20/10",
+			sizes:   [3]int{200, 200, 200},
+			ages:    [3]time.Duration{0, 0, 90 * time.Second},
+			want:    [3]int{55, 15, 30},
+		},
+		{
+			name:    "a smaller batch takes everything",
+			weights: "70/20/10",
+			sizes:   [3]int{3, 0, 2},
+			want:    [3]int{3, 0, 2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := newScheduleWorker(tt.weights, "", now, tt.sizes, tt.ages)
+
+			batch := w.schedule(now)
+			counts := countByTopic(batch)
+			got := [3]int{counts["sms_high"], counts["sms_medium"], counts["sms_low"]}
+			if got != tt.want {
+				t.Errorf("schedule() took %v, want %v", got, tt.want)
+			}
+			for i, q := range w.queues {
+				if left := tt.sizes[i] - tt.want[i]; len(q.buffer) != left {
+					t.Errorf("%s queue kept %d messages, want %d", q.priority, len(q.buffer), left)
+				}
+			}
+		})
+	}
+}
+
+func TestScheduleKeepsFetchOrder(t *testing.T) {
+	now := time.Now()
+	w := newScheduleWorker("70/20/10", "", now, [3]int{200, 200, 200}, [3]time.Duration{})
+
+	first := w.schedule(now)
+	second := w.schedule(now)
+
+	last := map[string]int64{}
+	for _, m := range append(first, second...) {
+		if previous, ok := last[m.Message.Topic]; ok && m.Message.Offset != previous+1 {
+			t.Fatalf("%s offset %d follows %d", m.Message.Topic, m.Message.Offset, previous)
+		}
+		last[m.Message.Topic] = m.Message.Offset
+	}
+}
+
+func TestScheduleUrgentPreemption(t *testing.T) {
+	now := time.Now()
+	w := newScheduleWorker("70/20/10", "otp", now, [3]int{200, 200, 200}, [3]time.Duration{})
+	low := w.queues[2]
+	for i := range 3 {
+		low.buffer[i].Message.Value = []byte(`{"category":"otp"}`)
+	}
+	// Urgent messages behind others wait their turn.
+	low.buffer[50].Message.Value = []byte(`{"category":"otp"}`)
+
+	batch := w.schedule(now)
+	counts := countByTopic(batch)
+	if got := [3]int{counts["sms_high"], counts["sms_medium"], counts["sms_low"]}; got != [3]int{69, 19, 12} {
+		t.Errorf("schedule() took %v, want [69 19 12]", got)
+	}
+	if len(low.buffer) != 188 || low.buffer[0].Message.Offset != 12 {
+		t.Errorf("low queue starts at offset %d with %d messages, want 12 with 188", low.buffer[0].Message.Offset, len(low.buffer))
+	}
+}
+
+func TestScheduleUrgentFillsTheBatch(t *testing.T) {
+	now := time.Now()
+	w := newScheduleWorker("70/20/10", "otp", now, [3]int{50, 50, 150}, [3]time.Duration{})
+	for i := range w.queues[2].buffer {
+		w.queues[2].buffer[i].Message.Value = []byte(`{"category":"otp"}`)
+	}
+
+	counts := countByTopic(w.schedule(now))
+	if got := [3]int{counts["sms_high"], counts["sms_medium"], counts["sms_low"]}; got != [3]int{0, 0, 100} {
+		t.Errorf("schedule() took %v, want [0 0 100]", got)
+	}
+}
@@ -4,13 +4,16 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
-	"golang.org/x/time/rate"
 
 	"github.com/HuseyinAsik/Notifications/models"
 	"github.com/HuseyinAsik/Notifications/providers"
@@ -18,19 +21,46 @@ import (
 
 	gkafka "github.com/HuseyinAsik/Notifications/pkg/kafka"
 	"github.com/HuseyinAsik/Notifications/pkg/logging"
+	"github.com/HuseyinAsik/Notifications/pkg/metrics"
+	"github.com/HuseyinAsik/Notifications/pkg/ratelimit"
+	"github.com/HuseyinAsik/Notifications/pkg/timeline"
+	"github.com/HuseyinAsik/Notifications/pkg/tracing"
 )
 
-const batchSize = 100
+const (
+	batchSize = 100
 
-type Worker struct {
-	highReader   *kafka.Reader
-	normalReader *kafka.Reader
-	lowReader    *kafka.Reader
+	reasonUndecodable      = "undecodable_payload"
+	reasonPermanentFailure = "permanent_failure"
+	reasonRetriesExhausted = "retries_exhausted"
+	reasonQuietHours       = "quiet_hours"
+
+	// invalidTokenTTL is how long an unregistered device token stays suppressed unless the
+	// app registers it again; after that the provider is asked once more.
+	invalidTokenTTL = 30 * 24 * time.Hour
 
-	limiter  *rate.Limiter
-	provider providers.Provider
-	repo     repository.NotificationRepository
-	logger   *logging.LogWrapper
+	// retryDelay is how long the worker waits before trying a batch again when none of its
+	// messages could be finished.
+	retryDelay = time.Second
+)
+
+type Worker struct {
+	channel   string
+	queues    []*queue
+	offsets   *offsets
+	dlqWriter *gkafka.Writer
+
+	limiter     ratelimit.Limiter
+	caps        *ratelimit.FrequencyCaps
+	backoff     Backoff
+	priorities  Priorities
+	provider    providers.Provider
+	repo        repository.NotificationRepository
+	deadLetters repository.DeadLetterRepository
+	preferences repository.PreferenceRepository
+	tokens      repository.DeviceTokenRepository
+	timeline    *timeline.Recorder
+	logger      *logging.LogWrapper
 }
 type FetchedMessage struct {
 	Reader  *kafka.Reader
@@ -40,26 +70,49 @@ type FetchedMessage struct {
 func NewWorker(
 	brokers []string,
 	channel string,
-	rateLimit int,
+	limiter ratelimit.Limiter,
+	caps *ratelimit.FrequencyCaps,
+	backoff Backoff,
+	priorities Priorities,
 	prov providers.Provider,
 	repo repository.NotificationRepository,
+	deadLetters repository.DeadLetterRepository,
+	preferences repository.PreferenceRepository,
+	tokens repository.DeviceTokenRepository,
+	timeline *timeline.Recorder,
 	logger *logging.LogWrapper,
 ) *Worker {
 
 	groupID := channel + "-worker-group"
 
 	return &Worker{
-		highReader:   gkafka.NewReader(brokers, channel+"_high", groupID),
-		normalReader: gkafka.NewReader(brokers, channel+"_medium", groupID),
-		lowReader:    gkafka.NewReader(brokers, channel+"_low", groupID),
-		limiter:      rate.NewLimiter(rate.Limit(rateLimit), rateLimit),
-		provider:     prov,
-		repo:         repo,
-		logger:       logger,
+		channel: channel,
+		queues: []*queue{
+			newQueue(brokers, channel, "high", groupID),
+			newQueue(brokers, channel, "medium", groupID),
+			newQueue(brokers, channel, "low", groupID),
+		},
+		offsets:     newOffsets(),
+		dlqWriter:   gkafka.NewWriter(brokers),
+		limiter:     limiter,
+		caps:        caps,
+		backoff:     backoff,
+		priorities:  priorities,
+		provider:    prov,
+		repo:        repo,
+		deadLetters: deadLetters,
+		preferences: preferences,
+		tokens:      tokens,
+		timeline:    timeline,
+		logger:      logger,
 	}
 }
 
 func (w *Worker) Start(ctx context.Context) error {
+	if w.caps.Enabled() {
+		go w.caps.RunPurge(ctx)
+	}
+
 	for {
 		select {
 		case <-ctx.Done():
@@ -70,25 +123,28 @@ func (w *Worker) Start(ctx context.Context) error {
 		}
 	}
 }
-func (w *Worker) processBatch(ctx context.Context) {
-	messages := make([]FetchedMessage, 0, batchSize)
 
-	messages = append(messages, w.fetch(ctx, w.highReader, batchSize)...)
-
-	if len(messages) < batchSize {
-		messages = append(messages, w.fetch(ctx, w.normalReader, batchSize-len(messages))...)
-	}
-
-	if len(messages) < batchSize {
-		messages = append(messages, w.fetch(ctx, w.lowReader, batchSize-len(messages))...)
+// processBatch tops up every priority queue to a batch and handles the batch the scheduler
+// picks from them, so a flood of high priority messages cannot starve the others.
+func (w *Worker) processBatch(ctx context.Context) {
+	for _, q := range w.queues {
+		q.buffer = append(q.buffer, w.fetch(ctx, q.reader, batchSize-len(q.buffer))...)
 	}
 
+	messages := w.schedule(time.Now())
 	if len(messages) == 0 {
 		time.Sleep(50 * time.Millisecond)
 		return
 	}
 
 	w.handle(messages, ctx)
+	retried := w.requeue(messages)
+	w.commit(ctx)
+
+	// Nothing could be finished, e.g. while the database is down; do not spin on the batch.
+	if retried == len(messages) {
+		time.Sleep(retryDelay)
+	}
 }
 func (w *Worker) fetch(ctx context.Context, reader *kafka.Reader, limit int) []FetchedMessage {
 	var msgs []FetchedMessage
@@ -101,11 +157,14 @@ func (w *Worker) fetch(ctx context.Context, reader *kafka.Reader, limit int) []F
 		if err != nil {
 			break
 		}
+		metrics.KafkaFetched.WithLabelValues(msg.Topic).Inc()
 
-		msgs = append(msgs, FetchedMessage{
+		m := FetchedMessage{
 			Reader:  reader,
 			Message: msg,
-		})
+		}
+		w.offsets.Fetched(m)
+		msgs = append(msgs, m)
 	}
 
 	return msgs
@@ -113,106 +172,497 @@ func (w *Worker) fetch(ctx context.Context, reader *kafka.Reader, limit int) []F
 func (w *Worker) handle(messages []FetchedMessage, ctx context.Context) {
 	var wg sync.WaitGroup
 
-	for _, msg := range messages {
+	for _, group := range groupByKey(messages) {
 		wg.Add(1)
 
-		go func(m FetchedMessage) {
+		go func(group []FetchedMessage) {
 			defer wg.Done()
 
-			var n models.Notification
-			if err := json.Unmarshal(m.Message.Value, &n); err != nil {
-				return
-			}
-
-			w.limiter.Wait(ctx)
-			ok := w.CheckEvent(ctx, n.Id)
-			if ok {
-				if markErr := w.MarkEvent(ctx, n.Id, true); markErr != nil {
-					w.logger.Error(ctx, "handle markevent err", zap.Error(markErr))
-					return
-				}
-				if updateNotificationErr := w.UpdateNotification(ctx, n.Id, "processing"); updateNotificationErr != nil {
-					w.logger.Error(ctx, "handle updateNotification err",
-						zap.Error(updateNotificationErr),
-						zap.String("id", n.Id),
-						zap.String("status", "processing"))
-					return
-				}
-				if sendErr := w.provider.Send(n.Id, n.Recipient, n.Content); sendErr != nil {
-					w.logger.Error(ctx, "handle send err", zap.Error(sendErr))
-					if markErr := w.MarkEvent(ctx, n.Id, false); markErr != nil {
-						w.logger.Error(ctx, "handle markevent err", zap.Error(markErr))
-						return
-					}
+			// Messages sharing a key are handled one after another, in the order they were fetched.
+			// When one cannot be finished the rest of its group waits to be tried again after it.
+			for _, m := range group {
+				if !w.process(ctx, m) {
 					return
 				}
+				w.offsets.Finish(m)
 			}
-
-			if updateNotificationErr := w.UpdateNotification(ctx, n.Id, "sended"); updateNotificationErr != nil {
-				w.logger.Error(ctx, "handle updateNotification err",
-					zap.Error(updateNotificationErr),
-					zap.String("id", n.Id),
-					zap.String("status", "sended"))
-				return
-			}
-			w.commit(ctx, m.Message, m.Reader)
-		}(msg)
+		}(group)
 	}
 
 	wg.Wait()
 }
-func (w *Worker) commit(ctx context.Context, msg kafka.Message, reader *kafka.Reader) error {
-	return reader.CommitMessages(ctx, msg)
+
+// requeue puts the messages of the batch that could not be finished back at the head of their
+// queues, in fetch order, so they are tried again with the next batch. It returns how many
+// there were.
+func (w *Worker) requeue(messages []FetchedMessage) int {
+	requeued := 0
+	for _, q := range w.queues {
+		var open []FetchedMessage
+		for _, m := range messages {
+			if m.Reader == q.reader && w.offsets.Open(m) {
+				open = append(open, m)
+			}
+		}
+		q.buffer = append(open, q.buffer...)
+		requeued += len(open)
+	}
+
+	return requeued
 }
-func (w *Worker) shutdown() {
-	w.highReader.Close()
-	w.normalReader.Close()
-	w.lowReader.Close()
+
+// groupByKey splits the batch by message key, keeping the fetch order within each group.
+// Messages without a key are groups of their own.
+func groupByKey(messages []FetchedMessage) [][]FetchedMessage {
+	var groups [][]FetchedMessage
+	index := map[string]int{}
+
+	for _, m := range messages {
+		if len(m.Message.Key) == 0 {
+			groups = append(groups, []FetchedMessage{m})
+			continue
+		}
+
+		key := string(m.Message.Key)
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], m)
+	}
+
+	return groups
 }
 
-func (w *Worker) CheckEvent(ctx context.Context, id string) bool {
-	event, err := w.repo.FetchOutboxEventByAggregateId(ctx, id)
+// process handles one message and reports whether it is finished and its offset may be
+// committed; a message that is not is tried again.
+func (w *Worker) process(ctx context.Context, m FetchedMessage) bool {
+	ctx, span := tracing.Tracer().Start(tracing.Extract(ctx, gkafka.Headers(m.Message)), m.Message.Topic+" process",
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			attribute.String("messaging.system", "kafka"),
+			attribute.String("messaging.destination.name", m.Message.Topic),
+			attribute.Int("messaging.kafka.destination.partition", m.Message.Partition),
+			attribute.Int64("messaging.kafka.message.offset", m.Message.Offset),
+		),
+	)
+	defer span.End()
+	ctx = logging.WithRequestId(ctx, gkafka.Header(m.Message, gkafka.RequestIdHeader))
+
+	var n models.Notification
+	if err := json.Unmarshal(m.Message.Value, &n); err != nil {
+		w.logger.Error(ctx, "handle unmarshal err", zap.Error(err), zap.String("topic", m.Message.Topic), zap.Int64("offset", m.Message.Offset))
+		if dlqErr := w.DeadLetter(ctx, m.Message, "", "", reasonUndecodable, 0, err); dlqErr != nil {
+			w.logger.Error(ctx, "handle deadletter err", zap.Error(dlqErr))
+			return false
+		}
+		metrics.Failures.WithLabelValues(w.channel, strings.TrimPrefix(m.Message.Topic, w.channel+"_"), reasonUndecodable).Inc()
+		return true
+	}
 
-	if err != nil {
-		w.logger.Error(ctx, "Worker checkevent err", zap.Error(err))
+	event, ok := w.CheckEvent(ctx, n.Id)
+	if !ok {
+		return true
+	}
+	preference, preferenceErr := w.Preference(ctx, n)
+	if preferenceErr != nil {
+		w.logger.Error(ctx, "handle preference err", zap.Error(preferenceErr), zap.String("id", n.Id))
 		return false
 	}
-	if event == nil {
-		w.logger.Error(ctx, "Worker checkevent err", zap.Error(errors.New("event not found")), zap.String("id", id))
+	claimed, claimErr := w.repo.ClaimOutboxEvent(ctx, n.Id)
+	if claimErr != nil {
+		w.logger.Error(ctx, "handle claim event err", zap.Error(claimErr), zap.String("id", n.Id))
 		return false
 	}
-	if !strings.EqualFold(event.Status, "published") || event.RetryCount > 6 {
+	if !claimed {
+		return true
+	}
+	if reason := preference.SuppressionReason(n.Category); reason != "" {
+		if suppressErr := w.Suppress(ctx, event, reason); suppressErr != nil {
+			w.logger.Error(ctx, "handle suppress err", zap.Error(suppressErr), zap.String("id", n.Id))
+			w.release(ctx, n.Id)
+			return false
+		}
+		return true
+	}
+	if w.tokens != nil {
+		invalid, tokenErr := w.tokens.IsInvalid(ctx, n.TenantId, n.Recipient, time.Now().Add(-invalidTokenTTL))
+		if tokenErr != nil {
+			w.logger.Error(ctx, "handle device token err", zap.Error(tokenErr), zap.String("id", n.Id))
+			w.release(ctx, n.Id)
+			return false
+		}
+		if invalid {
+			if suppressErr := w.Suppress(ctx, event, models.SuppressedTokenUnregistered); suppressErr != nil {
+				w.logger.Error(ctx, "handle suppress err", zap.Error(suppressErr), zap.String("id", n.Id))
+				w.release(ctx, n.Id)
+				return false
+			}
+			return true
+		}
+	}
+	if until, quiet := QuietHoursOf(n, preference).Until(time.Now()); quiet && n.Priority != "high" {
+		if deferErr := w.Defer(ctx, event, until, reasonQuietHours); deferErr != nil {
+			w.logger.Error(ctx, "handle defer err", zap.Error(deferErr), zap.String("id", n.Id))
+			w.release(ctx, n.Id)
+			return false
+		}
+		return true
+	}
+	// The cap is charged once per notification id, so retries and redeliveries do not use up
+	// the recipient's allowance.
+	if w.caps.Enabled() {
+		allowed, retryAt, capErr := w.caps.Allow(ctx, w.channel, n.Id, FrequencyKeys(n), time.Now())
+		if capErr != nil {
+			w.logger.Error(ctx, "handle frequency cap err", zap.Error(capErr), zap.String("id", n.Id))
+			w.release(ctx, n.Id)
+			return false
+		}
+		if !allowed {
+			if capErr := w.OverCap(ctx, event, retryAt); capErr != nil {
+				w.logger.Error(ctx, "handle over cap err", zap.Error(capErr), zap.String("id", n.Id))
+				w.release(ctx, n.Id)
+				return false
+			}
+			return true
+		}
+	}
+	if updateNotificationErr := w.UpdateNotification(ctx, n.Id, "processing"); updateNotificationErr != nil {
+		w.logger.Error(ctx, "handle updateNotification err",
+			zap.Error(updateNotificationErr),
+			zap.String("id", n.Id),
+			zap.String("status", "processing"))
+		w.release(ctx, n.Id)
 		return false
 	}
+	w.timeline.Record(ctx, models.NotificationEvent{
+		TenantId:       n.TenantId,
+		NotificationId: n.Id,
+		Status:         "processing",
+		Attempt:        event.RetryCount + 1,
+	})
+
+	span.SetAttributes(attribute.String("notification.id", n.Id), attribute.String("notification.priority", n.Priority))
+	if limitErr := w.limiter.Wait(ctx); limitErr != nil {
+		w.logger.Error(ctx, "handle rate limit err", zap.Error(limitErr), zap.String("id", n.Id))
+		w.release(ctx, n.Id)
+		return false
+	}
+	result, sendErr := w.send(ctx, n)
+	if sendErr != nil {
+		w.logger.Error(ctx, "handle send err",
+			zap.Error(sendErr),
+			zap.String("id", n.Id),
+			zap.String("providerCode", result.ProviderCode),
+			zap.Bool("retryable", result.Retryable))
+	}
+	if errors.Is(sendErr, providers.ErrUnregisteredToken) {
+		w.logger.Warn(ctx, "handle device token unregistered", zap.String("id", n.Id), zap.String("recipient", n.Recipient))
+		w.InvalidateToken(ctx, n)
+	}
+
+	// The outbox row is written last: until then it stays claimed, so a failure on the way
+	// releases it and the redelivered message is processed again instead of being lost.
+	status, nextAttemptAt := w.Outcome(event, result, sendErr)
+
+	var reason string
+	if status == "failed" {
+		reason = reasonPermanentFailure
+		if result.Retryable {
+			reason = reasonRetriesExhausted
+		}
+		if dlqErr := w.DeadLetter(ctx, m.Message, n.TenantId, n.Id, reason, event.RetryCount+1, sendErr); dlqErr != nil {
+			w.logger.Error(ctx, "handle deadletter err", zap.Error(dlqErr), zap.String("id", n.Id))
+			w.release(ctx, n.Id)
+			return false
+		}
+	}
+
+	if updateNotificationErr := w.repo.UpdateNotificationDelivery(ctx, n.Id, status, result.ProviderMessageId); updateNotificationErr != nil {
+		w.logger.Error(ctx, "handle updateNotification err",
+			zap.Error(updateNotificationErr),
+			zap.String("id", n.Id),
+			zap.String("status", status))
+		w.release(ctx, n.Id)
+		return false
+	}
+
+	if markErr := w.MarkEvent(ctx, event, status, nextAttemptAt); markErr != nil {
+		w.logger.Error(ctx, "handle markevent err", zap.Error(markErr), zap.String("id", n.Id))
+		w.release(ctx, n.Id)
+		return false
+	}
+
+	metrics.ProviderSends.WithLabelValues(w.channel, n.Priority, status).Inc()
+	if status == "pending" {
+		metrics.Retries.WithLabelValues(w.channel, n.Priority).Inc()
+	}
+	if status == "failed" {
+		metrics.Failures.WithLabelValues(w.channel, n.Priority, reason).Inc()
+	}
+
+	attempt := models.NotificationEvent{
+		TenantId:          n.TenantId,
+		NotificationId:    n.Id,
+		Status:            status,
+		Attempt:           event.RetryCount + 1,
+		ProviderStatus:    string(result.Status),
+		ProviderCode:      result.ProviderCode,
+		ProviderMessageId: result.ProviderMessageId,
+		Reason:            reason,
+		NextAttemptAt:     nextAttemptAt,
+	}
+	if sendErr != nil {
+		attempt.Error = sendErr.Error()
+	}
+	w.timeline.Record(ctx, attempt)
+
 	return true
 }
 
-func (w *Worker) MarkEvent(ctx context.Context, id string, result bool) error {
+// send hands the notification to the provider in a span of its own.
+func (w *Worker) send(ctx context.Context, n models.Notification) (providers.SendResult, error) {
+	ctx, span := tracing.Tracer().Start(ctx, w.channel+" provider send",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("notification.channel", w.channel)),
+	)
+	started := time.Now()
+
+	result, err := w.provider.Send(ctx, n.Id, n.Recipient, n.Content)
+	if err == nil && result.Status == providers.SendRejected {
+		// Some gateways reject with a code alone; the code becomes the error so that the
+		// attempt, the dead letter and the timeline record why the send failed.
+		err = &providers.SendError{
+			Code:       result.ProviderCode,
+			Retryable:  result.Retryable,
+			RetryAfter: result.RetryAfter,
+			Err:        errors.New("provider rejected the message"),
+		}
+	}
+	metrics.ProviderSendDuration.WithLabelValues(w.channel, n.Priority).Observe(time.Since(started).Seconds())
+	span.SetAttributes(
+		attribute.String("provider.status", string(result.Status)),
+		attribute.String("provider.code", result.ProviderCode),
+		attribute.String("provider.message_id", result.ProviderMessageId),
+	)
+	tracing.End(span, err)
+
+	return result, err
+}
+
+// release hands a claimed event back after a failure, so the redelivered message is
+// processed again instead of being dropped as already claimed.
+func (w *Worker) release(ctx context.Context, id string) {
+	if err := w.repo.ReleaseOutboxEvent(ctx, id); err != nil {
+		w.logger.Error(ctx, "Worker release event err", zap.Error(err), zap.String("id", id))
+	}
+}
+
+// commit commits every partition up to its last message that is finished along with all the
+// messages before it.
+func (w *Worker) commit(ctx context.Context) {
+	for _, m := range w.offsets.Committable() {
+		err := m.Reader.CommitMessages(ctx, m.Message)
+		metrics.KafkaCommitted.WithLabelValues(m.Message.Topic, metrics.Result(err)).Inc()
+		if err != nil {
+			w.logger.Error(ctx, "Worker commit err", zap.Error(err),
+				zap.String("topic", m.Message.Topic), zap.Int("partition", m.Message.Partition), zap.Int64("offset", m.Message.Offset))
+		}
+	}
+}
+func (w *Worker) shutdown() {
+	for _, q := range w.queues {
+		q.reader.Close()
+	}
+	w.dlqWriter.Close()
+}
+
+func (w *Worker) CheckEvent(ctx context.Context, id string) (*models.OutboxEvent, bool) {
 	event, err := w.repo.FetchOutboxEventByAggregateId(ctx, id)
-	status := "sended"
-	tryCount := event.RetryCount + 1
+
 	if err != nil {
-		return err
+		w.logger.Error(ctx, "Worker checkevent err", zap.Error(err))
+		return nil, false
+	}
+	if event == nil {
+		w.logger.Warn(ctx, "Worker checkevent event not found", zap.String("id", id))
+		return nil, false
 	}
+	if !strings.EqualFold(event.Status, "published") || event.RetryCount >= w.backoff.MaxAttempts {
+		return nil, false
+	}
+	return event, true
+}
 
-	if !result && event.RetryCount < 6 {
-		status = "pending"
+// Outcome returns the outbox status a send attempt leads to: "sended" on success, "pending"
+// with the time of the next attempt when the provider error can be retried and attempts
+// remain, "failed" otherwise.
+func (w *Worker) Outcome(event *models.OutboxEvent, result providers.SendResult, sendErr error) (string, *time.Time) {
+	if sendErr == nil && result.Status != providers.SendRejected {
+		return "sended", nil
 	}
+	if !result.Retryable || event.RetryCount+1 >= w.backoff.MaxAttempts {
+		return "failed", nil
+	}
+
+	// A provider's Retry-After is honoured up to the backoff cap, so a bogus value cannot
+	// park the notification indefinitely.
+	delay := max(w.backoff.Next(event.RetryCount+1), min(result.RetryAfter, w.backoff.Cap))
+	next := time.Now().UTC().Add(delay)
 
-	if !result && event.RetryCount >= 6 {
-		status = "failed"
+	return "pending", &next
+}
+
+// MarkEvent records the send attempt and its outcome on the outbox row.
+func (w *Worker) MarkEvent(ctx context.Context, event *models.OutboxEvent, status string, nextAttemptAt *time.Time) error {
+	return w.repo.UpdateOutboxEvent(ctx, event.AggregateId, status, event.RetryCount+1, nextAttemptAt)
+}
+
+// Preference loads the recipient's current preferences, which may have changed since the
+// notification was accepted. It returns nil when the recipient has none.
+func (w *Worker) Preference(ctx context.Context, n models.Notification) (*models.RecipientPreference, error) {
+	preference, err := w.preferences.Get(ctx, n.TenantId, n.Recipient, n.Channel)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, nil
 	}
-	updateErr := w.repo.UpdateOutboxEvent(ctx, id, status, tryCount)
 
-	if updateErr != nil {
-		return updateErr
+	return preference, err
+}
+
+// QuietHoursOf returns the quiet hours for the notification: the window sent with the
+// request wins over the stored preference, and so does the request time zone.
+func QuietHoursOf(n models.Notification, preference *models.RecipientPreference) models.QuietHours {
+	quietHours := models.QuietHours{Start: n.QuietHoursStart, End: n.QuietHoursEnd, Timezone: n.Timezone}
+	if preference == nil {
+		return quietHours
+	}
+	if quietHours.Start == "" {
+		quietHours.Start = preference.QuietHoursStart
+		quietHours.End = preference.QuietHoursEnd
+	}
+	if quietHours.Timezone == "" {
+		quietHours.Timezone = preference.Timezone
 	}
 
+	return quietHours
+}
+
+func (w *Worker) Suppress(ctx context.Context, event *models.OutboxEvent, reason string) error {
+	if err := w.repo.UpdateOutboxEvent(ctx, event.AggregateId, "suppressed", event.RetryCount, nil); err != nil {
+		return err
+	}
+
+	if err := w.repo.SuppressNotification(ctx, event.AggregateId, reason); err != nil {
+		return err
+	}
+	w.timeline.Record(ctx, models.NotificationEvent{
+		TenantId:       event.TenantId,
+		NotificationId: event.AggregateId,
+		Status:         "suppressed",
+		Reason:         reason,
+	})
+
+	return nil
+}
+
+// Defer puts the notification back to scheduled until the given time.
+func (w *Worker) Defer(ctx context.Context, event *models.OutboxEvent, until time.Time, reason string) error {
+	if err := w.repo.DeferNotification(ctx, event.AggregateId, until); err != nil {
+		return err
+	}
+	w.timeline.Record(ctx, models.NotificationEvent{
+		TenantId:       event.TenantId,
+		NotificationId: event.AggregateId,
+		Status:         "scheduled",
+		Reason:         reason,
+		NextAttemptAt:  &until,
+	})
+
 	return nil
 }
 
+// InvalidateToken marks the notification's device token dead, so later notifications to it
+// are suppressed instead of sent.
+func (w *Worker) InvalidateToken(ctx context.Context, n models.Notification) {
+	if w.tokens == nil {
+		return
+	}
+	if err := w.tokens.Invalidate(ctx, n.TenantId, n.Recipient, models.SuppressedTokenUnregistered); err != nil {
+		w.logger.Error(ctx, "Worker invalidate token err", zap.Error(err), zap.String("id", n.Id))
+	}
+}
+
+// FrequencyKeys returns the counter key of every frequency cap scope for the notification.
+func FrequencyKeys(n models.Notification) map[string]string {
+	tenant := n.TenantId
+	if tenant == "" {
+		tenant = ratelimit.DefaultTenant
+	}
+
+	return map[string]string{
+		ratelimit.ScopeRecipient: tenant + ":" + n.Recipient,
+		ratelimit.ScopeTenant:    tenant,
+	}
+}
+
+// OverCap applies the frequency cap policy to a notification that exceeded a cap: it is
+// deferred until the cap's window ends, or suppressed when the policy is reject.
+func (w *Worker) OverCap(ctx context.Context, event *models.OutboxEvent, retryAt time.Time) error {
+	if w.caps.Policy == ratelimit.PolicyReject {
+		return w.Suppress(ctx, event, models.SuppressedFrequencyCapped)
+	}
+
+	return w.Defer(ctx, event, retryAt, models.SuppressedFrequencyCapped)
+}
+
 func (w *Worker) UpdateNotification(ctx context.Context, id, status string) error {
 	err := w.repo.UpdateNotificationStatus(ctx, id, status)
 
 	return err
 }
+
+// DeadLetter parks msg on the {channel}_dlq topic with failure metadata headers and
+// records it so it can be listed and replayed through the API.
+func (w *Worker) DeadLetter(ctx context.Context, msg kafka.Message, tenantId, aggregateId, reason string, attempts int, lastErr error) error {
+	lastError := ""
+	if lastErr != nil {
+		lastError = lastErr.Error()
+	}
+
+	dlqMessage := gkafka.Message{
+		Topic: w.channel + "_dlq",
+		Key:   msg.Key,
+		Value: msg.Value,
+		Headers: map[string]string{
+			"x-dlq-reason":             reason,
+			"x-dlq-attempts":           strconv.Itoa(attempts),
+			"x-dlq-last-error":         lastError,
+			"x-dlq-original-topic":     msg.Topic,
+			"x-dlq-original-partition": strconv.Itoa(msg.Partition),
+			"x-dlq-original-offset":    strconv.FormatInt(msg.Offset, 10),
+			"x-dlq-failed-at":          time.Now().UTC().Format(time.RFC3339),
+		},
+	}
+	if requestId := logging.RequestId(ctx); requestId != "" {
+		dlqMessage.Headers[gkafka.RequestIdHeader] = requestId
+	}
+	if err := w.dlqWriter.WriteMessages(ctx, []gkafka.Message{dlqMessage}); err != nil {
+		return err
+	}
+
+	return w.deadLetters.Create(ctx, models.DeadLetter{
+		Id:                uuid.NewString(),
+		AggregateId:       aggregateId,
+		TenantId:          tenantId,
+		Channel:           w.channel,
+		Reason:            reason,
+		Attempts:          attempts,
+		LastError:         lastError,
+		OriginalTopic:     msg.Topic,
+		OriginalPartition: msg.Partition,
+		OriginalOffset:    msg.Offset,
+		Payload:           msg.Value,
+	})
+}
@@ -0,0 +1,202 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"go.uber.org/zap"
+
+	"github.com/HuseyinAsik/Notifications/models"
+	"github.com/HuseyinAsik/Notifications/pkg/logging"
+	"github.com/HuseyinAsik/Notifications/providers"
+	"github.com/HuseyinAsik/Notifications/repository"
+)
+
+// fakeRepo records outbox updates; the methods it does not override panic.
+type fakeRepo struct {
+	repository.NotificationRepository
+
+	id            string
+	status        string
+	retryCount    int
+	nextAttemptAt *time.Time
+}
+
+func (r *fakeRepo) UpdateOutboxEvent(ctx context.Context, id, status string, retryCount int, nextAttemptAt *time.Time) error {
+	r.id, r.status, r.retryCount, r.nextAttemptAt = id, status, retryCount, nextAttemptAt
+	return nil
+}
+
+type fakeProvider struct {
+	result providers.SendResult
+	err    error
+}
+
+func (p fakeProvider) Send(ctx context.Context, id, to, content string) (providers.SendResult, error) {
+	return p.result, p.err
+}
+
+func testLogger() *logging.LogWrapper {
+	return &logging.LogWrapper{ZapLogger: zap.NewNop()}
+}
+
+func TestWorkerOutcome(t *testing.T) {
+	backoff := Backoff{Base: 4 * time.Second, Cap: time.Minute, MaxAttempts: 3}
+	retryable := providers.RetryableError("HTTP_503", errors.New("unavailable"))
+	permanent := providers.PermanentError("INVALID_RECIPIENT", errors.New("bad number"))
+
+	tests := []struct {
+		name       string
+		retryCount int
+		result     providers.SendResult
+		err        error
+		wantStatus string
+		// The next attempt is expected within [minDelay, maxDelay] from now; zero means none.
+		minDelay, maxDelay time.Duration
+	}{
+		{
+			name:       "sent",
+			result:     providers.SendResult{Status: providers.SendAccepted, ProviderMessageId: "m-1"},
+			wantStatus: "sended",
+		},
+		{
+			name:       "retryable",
+			result:     providers.Rejected(retryable),
+			err:        retryable,
+			wantStatus: "pending",
+			minDelay:   2 * time.Second,
+			maxDelay:   4 * time.Second,
+		},
+		{
+			name:       "permanent",
+			result:     providers.Rejected(permanent),
+			err:        permanent,
+			wantStatus: "failed",
+		},
+		{
+			name:       "retry after",
+			result:     providers.SendResult{Status: providers.SendRejected, Retryable: true, RetryAfter: 30 * time.Second},
+			err:        retryable,
+			wantStatus: "pending",
+			minDelay:   30 * time.Second,
+			maxDelay:   30 * time.Second,
+		},
+		{
+			name:       "retry after over the cap",
+			result:     providers.SendResult{Status: providers.SendRejected, Retryable: true, RetryAfter: time.Hour},
+			err:        retryable,
+			wantStatus: "pending",
+			minDelay:   time.Minute,
+			maxDelay:   time.Minute,
+		},
+		{
+			name:       "max attempts",
+			retryCount: 2,
+			result:     providers.Rejected(retryable),
+			err:        retryable,
+			wantStatus: "failed",
+		},
+		{
+			name:       "rejected without an error",
+			result:     providers.SendResult{Status: providers.SendRejected, Retryable: true},
+			wantStatus: "pending",
+			minDelay:   2 * time.Second,
+			maxDelay:   4 * time.Second,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &Worker{backoff: backoff, logger: testLogger()}
+			event := &models.OutboxEvent{AggregateId: "n-1", RetryCount: tt.retryCount}
+
+			before := time.Now().UTC()
+			status, nextAttemptAt := w.Outcome(event, tt.result, tt.err)
+			after := time.Now().UTC()
+
+			if status != tt.wantStatus {
+				t.Errorf("status = %q, want %q", status, tt.wantStatus)
+			}
+			if tt.maxDelay == 0 {
+				if nextAttemptAt != nil {
+					t.Errorf("next attempt = %v, want none", nextAttemptAt)
+				}
+				return
+			}
+			if nextAttemptAt == nil {
+				t.Fatal("next attempt = nil, want one")
+			}
+			if nextAttemptAt.Before(before.Add(tt.minDelay)) || nextAttemptAt.After(after.Add(tt.maxDelay)) {
+				t.Errorf("next attempt in %v, want within [%v, %v]", nextAttemptAt.Sub(before), tt.minDelay, tt.maxDelay)
+			}
+		})
+	}
+}
+
+func TestWorkerMarkEvent(t *testing.T) {
+	repo := &fakeRepo{}
+	w := &Worker{repo: repo, logger: testLogger()}
+	next := time.Now().Add(time.Minute)
+
+	err := w.MarkEvent(context.Background(), &models.OutboxEvent{AggregateId: "n-1", RetryCount: 2}, "pending", &next)
+	if err != nil {
+		t.Fatalf("MarkEvent: %v", err)
+	}
+	if repo.id != "n-1" || repo.status != "pending" || repo.retryCount != 3 || repo.nextAttemptAt != &next {
+		t.Errorf("stored %+v, want n-1 pending with retry count 3", repo)
+	}
+}
+
+func TestWorkerSendRejectedWithoutError(t *testing.T) {
+	w := &Worker{
+		channel:  "sms",
+		provider: fakeProvider{result: providers.SendResult{Status: providers.SendRejected, ProviderCode: "E42"}},
+		logger:   testLogger(),
+	}
+
+	_, err := w.send(context.Background(), models.Notification{Id: "n-1", Priority: "high"})
+
+	var sendErr *providers.SendError
+	if !errors.As(err, &sendErr) || sendErr.Code != "E42" || sendErr.Retryable {
+		t.Fatalf("send error = %v, want a permanent error with code E42", err)
+	}
+}
+
+func TestGroupByKey(t *testing.T) {
+	message := func(key string, offset int64) FetchedMessage {
+		m := FetchedMessage{Message: kafka.Message{Offset: offset}}
+		if key != "" {
+			m.Message.Key = []byte(key)
+		}
+		return m
+	}
+	messages := []FetchedMessage{
+		message("t1:a", 0),
+		message("t1:b", 1),
+		message("", 2),
+		message("t1:a", 3),
+		message("", 4),
+		message("t1:b", 5),
+		message("t1:a", 6),
+	}
+
+	var got [][]int64
+	for _, group := range groupByKey(messages) {
+		var offsets []int64
+		for _, m := range group {
+			offsets = append(offsets, m.Message.Offset)
+		}
+		got = append(got, offsets)
+	}
+
+	// Groups appear in the order of their first message, each in fetch order; unkeyed messages
+	// stand alone.
+	want := [][]int64{{0, 3, 6}, {1, 5}, {2}, {4}}
+	if !slices.EqualFunc(got, want, slices.Equal[[]int64]) {
+		t.Errorf("groupByKey() = %v, want %v", got, want)
+	}
+}
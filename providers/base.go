@@ -1,5 +1,26 @@
 package providers
 
+import (
+	"context"
+	"time"
+)
+
+type SendStatus string
+
+const (
+	SendAccepted SendStatus = "accepted"
+	SendQueued   SendStatus = "queued"
+	SendRejected SendStatus = "rejected"
+)
+
+type SendResult struct {
+	ProviderMessageId string
+	Status            SendStatus
+	Retryable         bool
+	RetryAfter        time.Duration
+	ProviderCode      string
+}
+
 type Provider interface {
-	Send(id, to string, content string) error
+	Send(ctx context.Context, id, to, content string) (SendResult, error)
 }
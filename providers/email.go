@@ -1,7 +1,207 @@
 package providers
 
-type EmailProvider struct{}
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
 
-func (e *EmailProvider) Send(id, to, content string) error {
-	return nil
+	"github.com/HuseyinAsik/Notifications/pkg/settings"
+)
+
+type EmailProvider struct {
+	config    *settings.Smtp
+	tlsConfig *tls.Config
+}
+
+type EmailMessage struct {
+	Subject     string            `json:"subject"`
+	Text        string            `json:"text"`
+	Html        string            `json:"html"`
+	Attachments []EmailAttachment `json:"attachments"`
+}
+
+type EmailAttachment struct {
+	Filename    string `json:"filename"`
+	ContentType string `json:"contentType"`
+	Content     []byte `json:"content"`
+}
+
+func NewEmailProvider(config *settings.Smtp) *EmailProvider {
+	return &EmailProvider{
+		config:    config,
+		tlsConfig: &tls.Config{ServerName: config.Host, MinVersion: tls.VersionTLS12},
+	}
+}
+
+func (e *EmailProvider) Send(ctx context.Context, id, to, content string) (SendResult, error) {
+	from, err := mail.ParseAddress(e.config.From)
+	if err != nil {
+		err = PermanentError("INVALID_SENDER", fmt.Errorf("invalid from address: %w", err))
+		return Rejected(err), err
+	}
+	rcpt, err := mail.ParseAddress(to)
+	if err != nil {
+		err = PermanentError("INVALID_RECIPIENT", fmt.Errorf("invalid recipient address: %w", err))
+		return Rejected(err), err
+	}
+
+	message := ParseEmailMessage(content, e.config.DefaultSubject)
+	body, err := buildMimeMessage(id, from, rcpt, e.config.ReplyTo, message)
+	if err != nil {
+		err = PermanentError("INVALID_MESSAGE", err)
+		return Rejected(err), err
+	}
+
+	if err := e.deliver(ctx, from.Address, rcpt.Address, body); err != nil {
+		err = classifySMTPError(err)
+		return Rejected(err), err
+	}
+
+	return SendResult{ProviderMessageId: messageIdOf(id, from.Address), Status: SendQueued}, nil
+}
+
+// ParseEmailMessage accepts either a JSON encoded EmailMessage or a plain text body.
+func ParseEmailMessage(content, defaultSubject string) EmailMessage {
+	var message EmailMessage
+	trimmed := strings.TrimSpace(content)
+	if strings.HasPrefix(trimmed, "{") && json.Unmarshal([]byte(trimmed), &message) == nil &&
+		(message.Text != "" || message.Html != "") {
+		if message.Subject == "" {
+			message.Subject = defaultSubject
+		}
+		return message
+	}
+
+	return EmailMessage{Subject: defaultSubject, Text: content}
+}
+
+func (e *EmailProvider) deliver(ctx context.Context, from, to string, body []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, e.config.Timeout)
+	defer cancel()
+
+	addr := net.JoinHostPort(e.config.Host, strconv.Itoa(e.config.Port))
+	var conn net.Conn
+	var err error
+	if e.config.TLSMode == "tls" {
+		dialer := &tls.Dialer{Config: e.tlsConfig}
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	} else {
+		dialer := &net.Dialer{}
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, e.config.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if e.config.TLSMode == "starttls" {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return errors.New("smtp server does not support STARTTLS")
+		}
+		if err := client.StartTLS(e.tlsConfig); err != nil {
+			return err
+		}
+	}
+
+	if e.config.Username != "" {
+		if err := client.Auth(e.auth()); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(from); err != nil {
+		return err
+	}
+	if err := client.Rcpt(to); err != nil {
+		return err
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(body); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
+
+func (e *EmailProvider) auth() smtp.Auth {
+	if e.config.AuthMechanism == "login" {
+		return &loginAuth{username: e.config.Username, password: e.config.Password, host: e.config.Host}
+	}
+	return smtp.PlainAuth("", e.config.Username, e.config.Password, e.config.Host)
+}
+
+type loginAuth struct {
+	username string
+	password string
+	host     string
+}
+
+func (a *loginAuth) Start(server *smtp.ServerInfo) (string, []byte, error) {
+	// Same rule as smtp.PlainAuth: never send credentials in clear text to a remote host.
+	if !server.TLS && !isLocalhost(server.Name) {
+		return "", nil, errors.New("unencrypted connection")
+	}
+	if server.Name != a.host {
+		return "", nil, errors.New("wrong host name")
+	}
+	return "LOGIN", nil, nil
+}
+
+func (a *loginAuth) Next(fromServer []byte, more bool) ([]byte, error) {
+	if !more {
+		return nil, nil
+	}
+	switch strings.ToLower(strings.TrimSpace(string(fromServer))) {
+	case "username:":
+		return []byte(a.username), nil
+	case "password:":
+		return []byte(a.password), nil
+	default:
+		return nil, fmt.Errorf("unexpected LOGIN challenge: %s", fromServer)
+	}
+}
+
+// classifySMTPError treats 4xx replies and connection problems as transient and 5xx replies as permanent.
+func classifySMTPError(err error) error {
+	var smtpErr *textproto.Error
+	if errors.As(err, &smtpErr) {
+		code := strconv.Itoa(smtpErr.Code)
+		if smtpErr.Code >= 500 {
+			return PermanentError(code, err)
+		}
+		return RetryableError(code, err)
+	}
+	return RetryableError("CONNECTION_ERROR", err)
+}
+
+func messageIdOf(id, from string) string {
+	return fmt.Sprintf("<%s@%s>", id, domainOf(from))
+}
+
+func isLocalhost(name string) bool {
+	return name == "localhost" || name == "127.0.0.1" || name == "::1"
 }
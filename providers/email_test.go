@@ -0,0 +1,237 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/HuseyinAsik/Notifications/pkg/settings"
+)
+
+// smtpStub is a single-connection SMTP server that records what the client sent.
+// rcptReply, when set, is answered to RCPT TO instead of 250.
+type smtpStub struct {
+	listener  net.Listener
+	rcptReply string
+
+	auth string
+	from string
+	rcpt string
+	data string
+	done chan struct{}
+}
+
+func newSMTPStub(t *testing.T, rcptReply string) *smtpStub {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	stub := &smtpStub{listener: listener, rcptReply: rcptReply, done: make(chan struct{})}
+	go stub.serve()
+
+	return stub
+}
+
+func (s *smtpStub) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (s *smtpStub) serve() {
+	defer close(s.done)
+
+	conn, err := s.listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	text := textproto.NewConn(conn)
+	reply := func(line string) { _ = text.PrintfLine("%s", line) }
+
+	reply("220 stub ESMTP")
+	for {
+		line, err := text.ReadLine()
+		if err != nil {
+			return
+		}
+		verb := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+
+		switch verb {
+		case "EHLO":
+			reply("250-stub")
+			reply("250 AUTH PLAIN LOGIN")
+		case "AUTH":
+			s.auth = line
+			reply("235 2.7.0 Authentication successful")
+		case "MAIL":
+			s.from = line
+			reply("250 OK")
+		case "RCPT":
+			s.rcpt = line
+			if s.rcptReply != "" {
+				reply(s.rcptReply)
+				continue
+			}
+			reply("250 OK")
+		case "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			lines, err := text.ReadDotLines()
+			if err != nil {
+				return
+			}
+			s.data = strings.Join(lines, "\n")
+			reply("250 OK queued")
+		case "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+func (s *smtpStub) wait(t *testing.T) {
+	t.Helper()
+
+	select {
+	case <-s.done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("smtp stub did not finish")
+	}
+}
+
+func smtpConfig(port int) *settings.Smtp {
+	return &settings.Smtp{
+		Host:           "127.0.0.1",
+		Port:           port,
+		Username:       "mailer",
+		Password:       "secret",
+		From:           "Notifications <noreply@example.com>",
+		TLSMode:        "none",
+		AuthMechanism:  "plain",
+		DefaultSubject: "Notification",
+		Timeout:        5 * time.Second,
+	}
+}
+
+func TestEmailProviderSend(t *testing.T) {
+	stub := newSMTPStub(t, "")
+	provider := NewEmailProvider(smtpConfig(stub.port()))
+
+	content := `{"subject":"Your code","text":"Code: 1234","html":"<p>Code: 1234</p>"}`
+	result, err := provider.Send(context.Background(), "0b6f3c5e", "Jane <jane@example.com>", content)
+	if err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	stub.wait(t)
+
+	if result.Status != SendQueued {
+		t.Errorf("status = %q, want %q", result.Status, SendQueued)
+	}
+	if result.ProviderMessageId != "<0b6f3c5e@example.com>" {
+		t.Errorf("message id = %q", result.ProviderMessageId)
+	}
+	if !strings.HasPrefix(stub.auth, "AUTH PLAIN ") {
+		t.Errorf("auth = %q, want AUTH PLAIN", stub.auth)
+	}
+	if stub.from != "MAIL FROM:<noreply@example.com>" {
+		t.Errorf("mail from = %q", stub.from)
+	}
+	if stub.rcpt != "RCPT TO:<jane@example.com>" {
+		t.Errorf("rcpt to = %q", stub.rcpt)
+	}
+	for _, want := range []string{"Subject: Your code", "X-Notification-Id: 0b6f3c5e", "multipart/alternative"} {
+		if !strings.Contains(stub.data, want) {
+			t.Errorf("message does not contain %q:\n%s", want, stub.data)
+		}
+	}
+}
+
+func TestEmailProviderSendClassifiesReplies(t *testing.T) {
+	tests := []struct {
+		reply     string
+		code      string
+		retryable bool
+	}{
+		{reply: "550 5.1.1 No such user", code: "550", retryable: false},
+		{reply: "451 4.7.1 Try again later", code: "451", retryable: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			stub := newSMTPStub(t, tt.reply)
+			provider := NewEmailProvider(smtpConfig(stub.port()))
+
+			result, err := provider.Send(context.Background(), "id", "jane@example.com", "hello")
+			if err == nil {
+				t.Fatal("send succeeded, want an error")
+			}
+			if result.Status != SendRejected {
+				t.Errorf("status = %q, want %q", result.Status, SendRejected)
+			}
+			if result.ProviderCode != tt.code || result.Retryable != tt.retryable {
+				t.Errorf("code = %q retryable = %v, want %q %v", result.ProviderCode, result.Retryable, tt.code, tt.retryable)
+			}
+		})
+	}
+}
+
+func TestEmailProviderSendConnectionError(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	result, err := NewEmailProvider(smtpConfig(port)).Send(context.Background(), "id", "jane@example.com", "hello")
+	if err == nil {
+		t.Fatal("send succeeded, want an error")
+	}
+	if !result.Retryable || result.ProviderCode != "CONNECTION_ERROR" {
+		t.Errorf("code = %q retryable = %v, want CONNECTION_ERROR true", result.ProviderCode, result.Retryable)
+	}
+}
+
+func TestEmailProviderSendInvalidRecipient(t *testing.T) {
+	result, err := NewEmailProvider(smtpConfig(25)).Send(context.Background(), "id", "not an address", "hello")
+
+	var sendErr *SendError
+	if !errors.As(err, &sendErr) || sendErr.Code != "INVALID_RECIPIENT" {
+		t.Fatalf("err = %v, want INVALID_RECIPIENT", err)
+	}
+	if result.Retryable {
+		t.Error("invalid recipient is retryable")
+	}
+}
+
+func TestParseEmailMessage(t *testing.T) {
+	tests := []struct {
+		content string
+		want    EmailMessage
+	}{
+		{content: "plain text", want: EmailMessage{Subject: "Default", Text: "plain text"}},
+		{content: `{"text":"hi"}`, want: EmailMessage{Subject: "Default", Text: "hi"}},
+		{content: `{"subject":"Hello","html":"<b>hi</b>"}`, want: EmailMessage{Subject: "Hello", Html: "<b>hi</b>"}},
+		{content: `{"subject":"no body"}`, want: EmailMessage{Subject: "Default", Text: `{"subject":"no body"}`}},
+	}
+
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			got := ParseEmailMessage(tt.content, "Default")
+			if got.Subject != tt.want.Subject || got.Text != tt.want.Text || got.Html != tt.want.Html {
+				t.Errorf("ParseEmailMessage(%q) = %+v, want %+v", tt.content, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package providers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type SendError struct {
+	Code       string
+	Retryable  bool
+	RetryAfter time.Duration
+	Err        error
+}
+
+func (e *SendError) Error() string {
+	if e.Code != "" {
+		return fmt.Sprintf("%s (code: %s)", e.Err.Error(), e.Code)
+	}
+	return e.Err.Error()
+}
+
+func (e *SendError) Unwrap() error {
+	return e.Err
+}
+
+func RetryableError(code string, err error) error {
+	return &SendError{Code: code, Retryable: true, Err: err}
+}
+
+func PermanentError(code string, err error) error {
+	return &SendError{Code: code, Retryable: false, Err: err}
+}
+
+// IsRetryable reports whether err may succeed on a later attempt. Errors that were
+// not classified by a provider are treated as retryable.
+func IsRetryable(err error) bool {
+	var sendErr *SendError
+	if errors.As(err, &sendErr) {
+		return sendErr.Retryable
+	}
+	return err != nil
+}
+
+// Rejected builds the result reported alongside a failed send.
+func Rejected(err error) SendResult {
+	result := SendResult{Status: SendRejected, Retryable: IsRetryable(err)}
+
+	var sendErr *SendError
+	if errors.As(err, &sendErr) {
+		result.ProviderCode = sendErr.Code
+		result.RetryAfter = sendErr.RetryAfter
+	}
+
+	return result
+}
+
+func withRetryAfter(err error, header http.Header) error {
+	var sendErr *SendError
+	if errors.As(err, &sendErr) {
+		sendErr.RetryAfter = parseRetryAfter(header)
+	}
+	return err
+}
+
+func parseRetryAfter(header http.Header) time.Duration {
+	value := header.Get("Retry-After")
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return time.Until(at)
+	}
+	return 0
+}
+
+func retryableStatus(status int) bool {
+	return status == 408 || status == 425 || status == 429 || status >= 500
+}
@@ -1,7 +1,159 @@
 package providers
 
-type PushProvider struct{}
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
 
-func (p *PushProvider) Send(id, to, content string) error {
-	return nil
+	"golang.org/x/sync/singleflight"
+
+	"github.com/HuseyinAsik/Notifications/pkg/httpx"
+	"github.com/HuseyinAsik/Notifications/pkg/settings"
+)
+
+// ErrUnregisteredToken is returned when FCM or APNs report that a device token is no
+// longer valid, so callers can stop sending to it.
+var ErrUnregisteredToken = errors.New("device token is no longer registered")
+
+type PushProvider struct {
+	client httpx.HTTPClient
+	config *settings.Push
+	fcm    *fcmSender
+	apns   *apnsSender
+}
+
+type PushMessage struct {
+	Platform    string            `json:"platform"`
+	Title       string            `json:"title"`
+	Body        string            `json:"body"`
+	Badge       *int              `json:"badge,omitempty"`
+	Sound       string            `json:"sound"`
+	Data        map[string]string `json:"data"`
+	CollapseKey string            `json:"collapseKey"`
+	TTL         int               `json:"ttl"`
+}
+
+func NewPushProvider(client httpx.HTTPClient, config *settings.Push) (*PushProvider, error) {
+	provider := &PushProvider{client: client, config: config}
+
+	if config.FCMCredentialsFile != "" {
+		fcm, err := newFCMSender(client, config)
+		if err != nil {
+			return nil, err
+		}
+		provider.fcm = fcm
+	}
+
+	if config.APNsKeyFile != "" {
+		apns, err := newAPNsSender(client, config)
+		if err != nil {
+			return nil, err
+		}
+		provider.apns = apns
+	}
+
+	return provider, nil
+}
+
+func (p *PushProvider) Send(ctx context.Context, id, to, content string) (SendResult, error) {
+	message := ParsePushMessage(content)
+	platform, token := p.resolvePlatform(to, message.Platform)
+
+	ctx, cancel := context.WithTimeout(ctx, p.config.Timeout)
+	defer cancel()
+
+	var messageId string
+	var err error
+	switch {
+	case token == "":
+		err = PermanentError("INVALID_RECIPIENT", errors.New("empty device token"))
+	case platform == "apns" && p.apns != nil:
+		messageId, err = p.apns.send(ctx, id, token, message)
+	case platform == "fcm" && p.fcm != nil:
+		messageId, err = p.fcm.send(ctx, token, message)
+	default:
+		err = PermanentError("PLATFORM_NOT_CONFIGURED", fmt.Errorf("push platform %q is not configured", platform))
+	}
+	if err != nil {
+		return Rejected(err), err
+	}
+
+	return SendResult{ProviderMessageId: messageId, Status: SendAccepted}, nil
+}
+
+// ParsePushMessage accepts either a JSON encoded PushMessage or a plain text body.
+func ParsePushMessage(content string) PushMessage {
+	var message PushMessage
+	trimmed := strings.TrimSpace(content)
+	if strings.HasPrefix(trimmed, "{") && json.Unmarshal([]byte(trimmed), &message) == nil {
+		return message
+	}
+
+	return PushMessage{Body: content}
+}
+
+// resolvePlatform allows recipients in the form "apns:<token>" or "fcm:<token>".
+func (p *PushProvider) resolvePlatform(to, platform string) (string, string) {
+	if prefix, token, ok := strings.Cut(to, ":"); ok && (prefix == "fcm" || prefix == "apns") {
+		return prefix, token
+	}
+	if platform == "" {
+		platform = p.config.DefaultPlatform
+	}
+	return strings.ToLower(platform), to
+}
+
+// tokenCache keeps a bearer token until shortly before it expires. Concurrent callers that
+// find no usable token share a single mint, which runs without holding the mutex.
+type tokenCache struct {
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+	mint      func(ctx context.Context) (string, time.Time, error)
+	minting   singleflight.Group
+}
+
+func (c *tokenCache) get(ctx context.Context) (string, error) {
+	if token, ok := c.cached(); ok {
+		return token, nil
+	}
+
+	// The mint is shared, so it must not fail because the caller that started it went away.
+	token, err, _ := c.minting.Do("token", func() (any, error) {
+		if token, ok := c.cached(); ok {
+			return token, nil
+		}
+
+		token, expiresAt, err := c.mint(context.WithoutCancel(ctx))
+		if err != nil {
+			return "", err
+		}
+		c.mu.Lock()
+		c.token, c.expiresAt = token, expiresAt
+		c.mu.Unlock()
+
+		return token, nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return token.(string), nil
+}
+
+func (c *tokenCache) cached() (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.token, c.token != "" && time.Now().Add(time.Minute).Before(c.expiresAt)
+}
+
+func (c *tokenCache) invalidate() {
+	c.mu.Lock()
+	c.token = ""
+	c.mu.Unlock()
 }
@@ -0,0 +1,148 @@
+package providers
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+
+	"github.com/HuseyinAsik/Notifications/pkg/httpx"
+	"github.com/HuseyinAsik/Notifications/pkg/settings"
+)
+
+// APNs rejects provider tokens older than an hour and throttles refreshes more
+// frequent than every 20 minutes.
+const apnsTokenLifetime = 50 * time.Minute
+
+type apnsSender struct {
+	client     httpx.HTTPClient
+	endpoint   string
+	topic      string
+	keyId      string
+	teamId     string
+	privateKey *ecdsa.PrivateKey
+	tokens     *tokenCache
+}
+
+func newAPNsSender(client httpx.HTTPClient, config *settings.Push) (*apnsSender, error) {
+	raw, err := os.ReadFile(config.APNsKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("read apns key: %w", err)
+	}
+
+	key, err := jwt.ParseECPrivateKeyFromPEM(raw)
+	if err != nil {
+		return nil, fmt.Errorf("parse apns key: %w", err)
+	}
+
+	sender := &apnsSender{
+		client:     client,
+		endpoint:   config.APNsEndpoint,
+		topic:      config.APNsTopic,
+		keyId:      config.APNsKeyId,
+		teamId:     config.APNsTeamId,
+		privateKey: key,
+	}
+	sender.tokens = &tokenCache{mint: sender.mintToken}
+
+	return sender, nil
+}
+
+func (a *apnsSender) mintToken(_ context.Context) (string, time.Time, error) {
+	now := time.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"iss": a.teamId,
+		"iat": now.Unix(),
+	})
+	token.Header["kid"] = a.keyId
+
+	signed, err := token.SignedString(a.privateKey)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return signed, now.Add(apnsTokenLifetime), nil
+}
+
+func (a *apnsSender) send(ctx context.Context, id, token string, message PushMessage) (string, error) {
+	bearer, err := a.tokens.get(ctx)
+	if err != nil {
+		return "", PermanentError("AUTH_FAILED", err)
+	}
+
+	headers := map[string]string{
+		"authorization":  "bearer " + bearer,
+		"apns-topic":     a.topic,
+		"apns-push-type": "alert",
+		"apns-priority":  "10",
+	}
+	// apns-id must be a UUID; APNs generates one otherwise.
+	if _, parseErr := uuid.Parse(id); parseErr == nil {
+		headers["apns-id"] = id
+	}
+	if message.CollapseKey != "" {
+		headers["apns-collapse-id"] = message.CollapseKey
+	}
+	if message.TTL > 0 {
+		headers["apns-expiration"] = strconv.FormatInt(time.Now().Add(time.Duration(message.TTL)*time.Second).Unix(), 10)
+	}
+
+	var response struct {
+		Reason string `json:"reason"`
+	}
+	status, header, err := a.client.DoJSONWithHeaders(ctx, http.MethodPost, a.endpoint+"/3/device/"+token, a.payload(message), headers, &response)
+	if err != nil && status == 0 {
+		return "", RetryableError("NETWORK_ERROR", err)
+	}
+
+	if status == http.StatusOK {
+		return header.Get("apns-id"), nil
+	}
+
+	code := response.Reason
+	if code == "" {
+		code = "HTTP_" + strconv.Itoa(status)
+	}
+	sendErr := fmt.Errorf("apns responded with status %d: %s", status, code)
+
+	switch {
+	case status == http.StatusGone || code == "Unregistered" || code == "BadDeviceToken":
+		return "", PermanentError(code, fmt.Errorf("%w: %w", ErrUnregisteredToken, sendErr))
+	case code == "ExpiredProviderToken" || code == "InvalidProviderToken":
+		a.tokens.invalidate()
+		return "", withRetryAfter(RetryableError(code, sendErr), header)
+	case retryableStatus(status):
+		return "", withRetryAfter(RetryableError(code, sendErr), header)
+	default:
+		return "", PermanentError(code, sendErr)
+	}
+}
+
+func (a *apnsSender) payload(message PushMessage) map[string]any {
+	aps := map[string]any{
+		"alert": map[string]string{
+			"title": message.Title,
+			"body":  message.Body,
+		},
+	}
+	if message.Badge != nil {
+		aps["badge"] = *message.Badge
+	}
+	if message.Sound != "" {
+		aps["sound"] = message.Sound
+	}
+
+	payload := map[string]any{}
+	for key, value := range message.Data {
+		payload[key] = value
+	}
+	payload["aps"] = aps
+
+	return payload
+}
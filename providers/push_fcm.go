@@ -0,0 +1,199 @@
+package providers
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/HuseyinAsik/Notifications/pkg/httpx"
+	"github.com/HuseyinAsik/Notifications/pkg/settings"
+)
+
+const fcmScope = "https://www.googleapis.com/auth/firebase.messaging"
+
+type fcmSender struct {
+	client      httpx.HTTPClient
+	endpoint    string
+	tokenURL    string
+	clientEmail string
+	privateKey  *rsa.PrivateKey
+	tokens      *tokenCache
+}
+
+type fcmServiceAccount struct {
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURI    string `json:"token_uri"`
+}
+
+type fcmError struct {
+	Error struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+		Status  string `json:"status"`
+		Details []struct {
+			ErrorCode string `json:"errorCode"`
+		} `json:"details"`
+	} `json:"error"`
+	Name string `json:"name"`
+}
+
+func newFCMSender(client httpx.HTTPClient, config *settings.Push) (*fcmSender, error) {
+	raw, err := os.ReadFile(config.FCMCredentialsFile)
+	if err != nil {
+		return nil, fmt.Errorf("read fcm credentials: %w", err)
+	}
+
+	var account fcmServiceAccount
+	if err := json.Unmarshal(raw, &account); err != nil {
+		return nil, fmt.Errorf("parse fcm credentials: %w", err)
+	}
+
+	key, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(account.PrivateKey))
+	if err != nil {
+		return nil, fmt.Errorf("parse fcm private key: %w", err)
+	}
+
+	tokenURL := config.FCMTokenURL
+	if tokenURL == "" {
+		tokenURL = account.TokenURI
+	}
+	if tokenURL == "" {
+		tokenURL = "https://oauth2.googleapis.com/token"
+	}
+
+	sender := &fcmSender{
+		client:      client,
+		endpoint:    fmt.Sprintf("%s/v1/projects/%s/messages:send", config.FCMEndpoint, config.FCMProjectId),
+		tokenURL:    tokenURL,
+		clientEmail: account.ClientEmail,
+		privateKey:  key,
+	}
+	sender.tokens = &tokenCache{mint: sender.mintToken}
+
+	return sender, nil
+}
+
+// mintToken exchanges a signed service account assertion for an OAuth access token.
+func (f *fcmSender) mintToken(ctx context.Context) (string, time.Time, error) {
+	now := time.Now()
+	assertion, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":   f.clientEmail,
+		"scope": fcmScope,
+		"aud":   f.tokenURL,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	}).SignedString(f.privateKey)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	var response struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	status, err := f.client.DoForm(ctx, f.tokenURL, url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}, nil, &response)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	if status != http.StatusOK || response.AccessToken == "" {
+		return "", time.Time{}, fmt.Errorf("fcm token endpoint responded with status %d", status)
+	}
+
+	return response.AccessToken, now.Add(time.Duration(response.ExpiresIn) * time.Second), nil
+}
+
+func (f *fcmSender) send(ctx context.Context, token string, message PushMessage) (string, error) {
+	accessToken, err := f.tokens.get(ctx)
+	if err != nil {
+		return "", RetryableError("AUTH_FAILED", err)
+	}
+
+	var response fcmError
+	status, header, err := f.client.DoJSONWithHeaders(ctx, http.MethodPost, f.endpoint, f.payload(token, message),
+		map[string]string{"Authorization": "Bearer " + accessToken}, &response)
+	if err != nil && status == 0 {
+		return "", RetryableError("NETWORK_ERROR", err)
+	}
+
+	if status == http.StatusOK {
+		return response.Name, nil
+	}
+
+	code := response.Error.Status
+	for _, detail := range response.Error.Details {
+		if detail.ErrorCode != "" {
+			code = detail.ErrorCode
+		}
+	}
+	if code == "" {
+		code = "HTTP_" + strconv.Itoa(status)
+	}
+	sendErr := fmt.Errorf("fcm responded with status %d: %s", status, response.Error.Message)
+
+	switch {
+	case code == "UNREGISTERED" || (status == http.StatusNotFound && code == "NOT_FOUND"):
+		return "", PermanentError(code, fmt.Errorf("%w: %w", ErrUnregisteredToken, sendErr))
+	case status == http.StatusUnauthorized:
+		f.tokens.invalidate()
+		return "", withRetryAfter(RetryableError(code, sendErr), header)
+	case code == "UNAVAILABLE" || code == "INTERNAL" || code == "QUOTA_EXCEEDED" || retryableStatus(status):
+		return "", withRetryAfter(RetryableError(code, sendErr), header)
+	default:
+		return "", PermanentError(code, sendErr)
+	}
+}
+
+func (f *fcmSender) payload(token string, message PushMessage) map[string]any {
+	android := map[string]any{}
+	androidNotification := map[string]any{}
+	aps := map[string]any{}
+
+	if message.CollapseKey != "" {
+		android["collapse_key"] = message.CollapseKey
+	}
+	if message.TTL > 0 {
+		android["ttl"] = strconv.Itoa(message.TTL) + "s"
+	}
+	if message.Sound != "" {
+		androidNotification["sound"] = message.Sound
+		aps["sound"] = message.Sound
+	}
+	if message.Badge != nil {
+		androidNotification["notification_count"] = *message.Badge
+		aps["badge"] = *message.Badge
+	}
+	if len(androidNotification) > 0 {
+		android["notification"] = androidNotification
+	}
+
+	msg := map[string]any{
+		"token": token,
+		"notification": map[string]string{
+			"title": message.Title,
+			"body":  message.Body,
+		},
+	}
+	if len(message.Data) > 0 {
+		msg["data"] = message.Data
+	}
+	if len(android) > 0 {
+		msg["android"] = android
+	}
+	if len(aps) > 0 {
+		msg["apns"] = map[string]any{"payload": map[string]any{"aps": aps}}
+	}
+
+	return map[string]any{"message": msg}
+}
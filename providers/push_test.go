@@ -0,0 +1,413 @@
+package providers
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/zap"
+
+	"github.com/HuseyinAsik/Notifications/pkg/httpx"
+	"github.com/HuseyinAsik/Notifications/pkg/logging"
+	"github.com/HuseyinAsik/Notifications/pkg/settings"
+)
+
+// pushFake plays the Google OAuth token endpoint, FCM and APNs on one server. Each send is
+// answered with the next queued reply, or 200 when none is queued.
+type pushFake struct {
+	server   *httptest.Server
+	fcmKey   *rsa.PrivateKey
+	apnsKey  *ecdsa.PrivateKey
+	mu       sync.Mutex
+	replies  []pushReply
+	minted   int
+	requests []*http.Request
+	bodies   []map[string]any
+}
+
+type pushReply struct {
+	status int
+	body   string
+	header http.Header
+}
+
+func newPushFake(t *testing.T) *pushFake {
+	t.Helper()
+
+	fcmKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate rsa key: %v", err)
+	}
+	apnsKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate ec key: %v", err)
+	}
+
+	fake := &pushFake{fcmKey: fcmKey, apnsKey: apnsKey}
+	fake.server = httptest.NewServer(http.HandlerFunc(fake.handle))
+	t.Cleanup(fake.server.Close)
+
+	return fake
+}
+
+func (f *pushFake) handle(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if r.URL.Path == "/token" {
+		f.mint(w, r)
+		return
+	}
+
+	var body map[string]any
+	_ = json.NewDecoder(r.Body).Decode(&body)
+	f.requests = append(f.requests, r)
+	f.bodies = append(f.bodies, body)
+
+	reply := pushReply{status: http.StatusOK, body: `{"name":"projects/demo/messages/1"}`}
+	if len(f.replies) > 0 {
+		reply, f.replies = f.replies[0], f.replies[1:]
+	}
+	for key, values := range reply.header {
+		w.Header()[key] = values
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(reply.status)
+	_, _ = w.Write([]byte(reply.body))
+}
+
+// mint checks the service account assertion and hands out access-1, access-2, ...
+func (f *pushFake) mint(w http.ResponseWriter, r *http.Request) {
+	_, err := jwt.Parse(r.FormValue("assertion"), func(*jwt.Token) (any, error) {
+		return &f.fcmKey.PublicKey, nil
+	}, jwt.WithValidMethods([]string{"RS256"}), jwt.WithAudience(f.server.URL+"/token"))
+	if err != nil || r.FormValue("grant_type") != "urn:ietf:params:oauth:grant-type:jwt-bearer" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	f.minted++
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{"access_token": "access-" + strconv.Itoa(f.minted), "expires_in": 3600})
+}
+
+func (f *pushFake) reply(status int, body string, header http.Header) {
+	f.mu.Lock()
+	f.replies = append(f.replies, pushReply{status: status, body: body, header: header})
+	f.mu.Unlock()
+}
+
+func (f *pushFake) mintedTokens() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.minted
+}
+
+func (f *pushFake) last() (*http.Request, map[string]any) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.requests[len(f.requests)-1], f.bodies[len(f.bodies)-1]
+}
+
+// provider writes the fake's keys to files the way they are deployed and builds a provider
+// that sends to the fake.
+func (f *pushFake) provider(t *testing.T) *PushProvider {
+	t.Helper()
+	dir := t.TempDir()
+
+	rsaDer := x509.MarshalPKCS1PrivateKey(f.fcmKey)
+	account, _ := json.Marshal(fcmServiceAccount{
+		ClientEmail: "notifications@demo.iam.gserviceaccount.com",
+		PrivateKey:  string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: rsaDer})),
+		TokenURI:    f.server.URL + "/token",
+	})
+	credentials := filepath.Join(dir, "fcm.json")
+	if err := os.WriteFile(credentials, account, 0o600); err != nil {
+		t.Fatalf("write credentials: %v", err)
+	}
+
+	ecDer, err := x509.MarshalPKCS8PrivateKey(f.apnsKey)
+	if err != nil {
+		t.Fatalf("marshal ec key: %v", err)
+	}
+	apnsKeyFile := filepath.Join(dir, "apns.p8")
+	if err := os.WriteFile(apnsKeyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: ecDer}), 0o600); err != nil {
+		t.Fatalf("write apns key: %v", err)
+	}
+
+	config := &settings.Push{
+		FCMEndpoint:        f.server.URL,
+		FCMProjectId:       "demo",
+		FCMCredentialsFile: credentials,
+		APNsEndpoint:       f.server.URL,
+		APNsKeyFile:        apnsKeyFile,
+		APNsKeyId:          "KEY123",
+		APNsTeamId:         "TEAM123",
+		APNsTopic:          "com.example.app",
+	}
+	config.Load()
+
+	client := httpx.NewHTTPClient(&http.Client{}, &logging.LogWrapper{ZapLogger: zap.NewNop()})
+	provider, err := NewPushProvider(client, config)
+	if err != nil {
+		t.Fatalf("new push provider: %v", err)
+	}
+
+	return provider
+}
+
+func TestPushProviderSendFCM(t *testing.T) {
+	fake := newPushFake(t)
+	provider := fake.provider(t)
+
+	content := `{"title":"Order shipped","body":"Your order is on its way","collapseKey":"order-1","data":{"orderId":"1"}}`
+	for range 2 {
+		result, err := provider.Send(context.Background(), "n-1", "device-token", content)
+		if err != nil {
+			t.Fatalf("send: %v", err)
+		}
+		if result.Status != SendAccepted || result.ProviderMessageId != "projects/demo/messages/1" {
+			t.Errorf("result = %+v", result)
+		}
+	}
+
+	if minted := fake.mintedTokens(); minted != 1 {
+		t.Errorf("minted %d access tokens, want the first one reused", minted)
+	}
+	request, body := fake.last()
+	if request.URL.Path != "/v1/projects/demo/messages:send" {
+		t.Errorf("path = %q", request.URL.Path)
+	}
+	if got := request.Header.Get("Authorization"); got != "Bearer access-1" {
+		t.Errorf("Authorization = %q", got)
+	}
+	message, _ := body["message"].(map[string]any)
+	notification, _ := message["notification"].(map[string]any)
+	android, _ := message["android"].(map[string]any)
+	if message["token"] != "device-token" || notification["title"] != "Order shipped" || android["collapse_key"] != "order-1" {
+		t.Errorf("payload = %v", body)
+	}
+}
+
+func TestPushProviderSendFCMErrors(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       int
+		body         string
+		header       http.Header
+		code         string
+		retryable    bool
+		unregistered bool
+	}{
+		{
+			name:         "unregistered",
+			status:       404,
+			body:         `{"error":{"code":404,"status":"NOT_FOUND","details":[{"errorCode":"UNREGISTERED"}]}}`,
+			code:         "UNREGISTERED",
+			unregistered: true,
+		},
+		{
+			name:      "quota exceeded",
+			status:    429,
+			body:      `{"error":{"code":429,"status":"RESOURCE_EXHAUSTED","details":[{"errorCode":"QUOTA_EXCEEDED"}]}}`,
+			header:    http.Header{"Retry-After": {"10"}},
+			code:      "QUOTA_EXCEEDED",
+			retryable: true,
+		},
+		{
+			name:   "invalid argument",
+			status: 400,
+			body:   `{"error":{"code":400,"status":"INVALID_ARGUMENT","details":[{"errorCode":"INVALID_ARGUMENT"}]}}`,
+			code:   "INVALID_ARGUMENT",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := newPushFake(t)
+			fake.reply(tt.status, tt.body, tt.header)
+
+			result, err := fake.provider(t).Send(context.Background(), "n-1", "fcm:device-token", "hello")
+			if err == nil {
+				t.Fatal("send succeeded, want an error")
+			}
+			if result.ProviderCode != tt.code || result.Retryable != tt.retryable {
+				t.Errorf("result = %+v, want code %s retryable %v", result, tt.code, tt.retryable)
+			}
+			if errors.Is(err, ErrUnregisteredToken) != tt.unregistered {
+				t.Errorf("errors.Is(err, ErrUnregisteredToken) = %v, want %v", !tt.unregistered, tt.unregistered)
+			}
+			if tt.header != nil && result.RetryAfter != 10*time.Second {
+				t.Errorf("retry after = %s, want 10s", result.RetryAfter)
+			}
+		})
+	}
+}
+
+func TestPushProviderSendFCMRefreshesRejectedToken(t *testing.T) {
+	fake := newPushFake(t)
+	provider := fake.provider(t)
+	fake.reply(http.StatusUnauthorized, `{"error":{"code":401,"status":"UNAUTHENTICATED"}}`, nil)
+
+	result, err := provider.Send(context.Background(), "n-1", "device-token", "hello")
+	if err == nil || !result.Retryable {
+		t.Fatalf("result = %+v err = %v, want a retryable error", result, err)
+	}
+	if _, err := provider.Send(context.Background(), "n-1", "device-token", "hello"); err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+
+	request, _ := fake.last()
+	if got := request.Header.Get("Authorization"); got != "Bearer access-2" {
+		t.Errorf("Authorization = %q, want a fresh token", got)
+	}
+}
+
+func TestTokenCacheMintsOnceForConcurrentCallers(t *testing.T) {
+	release := make(chan struct{})
+	var mu sync.Mutex
+	mints := 0
+	cache := &tokenCache{mint: func(ctx context.Context) (string, time.Time, error) {
+		mu.Lock()
+		mints++
+		mu.Unlock()
+		<-release
+		return "token-1", time.Now().Add(time.Hour), nil
+	}}
+
+	var wg sync.WaitGroup
+	tokens := make([]string, 8)
+	for i := range tokens {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tokens[i], _ = cache.get(context.Background())
+		}()
+	}
+	// A caller waiting for the mint must not keep others from reading the cache.
+	time.Sleep(50 * time.Millisecond)
+	if _, ok := cache.cached(); ok {
+		t.Fatal("cached a token before the mint finished")
+	}
+	close(release)
+	wg.Wait()
+
+	if mints != 1 {
+		t.Errorf("minted %d times, want 1", mints)
+	}
+	for i, token := range tokens {
+		if token != "token-1" {
+			t.Errorf("caller %d got %q, want token-1", i, token)
+		}
+	}
+}
+
+func TestPushProviderSendAPNs(t *testing.T) {
+	fake := newPushFake(t)
+	id := "6f1c9a8e-1f0e-4a43-9d57-1d2f1b6c1a11"
+	fake.reply(http.StatusOK, ``, http.Header{"Apns-Id": {id}})
+
+	content := `{"title":"Hi","body":"There","badge":3,"sound":"default","data":{"orderId":"1"}}`
+	result, err := fake.provider(t).Send(context.Background(), id, "apns:abc123", content)
+	if err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if result.ProviderMessageId != id {
+		t.Errorf("message id = %q, want %q", result.ProviderMessageId, id)
+	}
+
+	request, body := fake.last()
+	if request.URL.Path != "/3/device/abc123" {
+		t.Errorf("path = %q", request.URL.Path)
+	}
+	for header, want := range map[string]string{"Apns-Topic": "com.example.app", "Apns-Push-Type": "alert", "Apns-Id": id} {
+		if got := request.Header.Get(header); got != want {
+			t.Errorf("%s = %q, want %q", header, got, want)
+		}
+	}
+
+	bearer, _ := strings.CutPrefix(request.Header.Get("Authorization"), "bearer ")
+	token, err := jwt.Parse(bearer, func(*jwt.Token) (any, error) {
+		return &fake.apnsKey.PublicKey, nil
+	}, jwt.WithValidMethods([]string{"ES256"}), jwt.WithIssuer("TEAM123"))
+	if err != nil {
+		t.Fatalf("provider token: %v", err)
+	}
+	if token.Header["kid"] != "KEY123" {
+		t.Errorf("kid = %v", token.Header["kid"])
+	}
+
+	aps, _ := body["aps"].(map[string]any)
+	if aps["badge"] != float64(3) || aps["sound"] != "default" || body["orderId"] != "1" {
+		t.Errorf("payload = %v", body)
+	}
+}
+
+func TestPushProviderSendAPNsErrors(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       int
+		body         string
+		code         string
+		retryable    bool
+		unregistered bool
+	}{
+		{name: "unregistered", status: 410, body: `{"reason":"Unregistered"}`, code: "Unregistered", unregistered: true},
+		{name: "bad device token", status: 400, body: `{"reason":"BadDeviceToken"}`, code: "BadDeviceToken", unregistered: true},
+		{name: "too many requests", status: 429, body: `{"reason":"TooManyRequests"}`, code: "TooManyRequests", retryable: true},
+		{name: "expired provider token", status: 403, body: `{"reason":"ExpiredProviderToken"}`, code: "ExpiredProviderToken", retryable: true},
+		{name: "bad topic", status: 400, body: `{"reason":"BadTopic"}`, code: "BadTopic"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := newPushFake(t)
+			fake.reply(tt.status, tt.body, nil)
+
+			result, err := fake.provider(t).Send(context.Background(), "n-1", "apns:abc123", "hello")
+			if err == nil {
+				t.Fatal("send succeeded, want an error")
+			}
+			if result.ProviderCode != tt.code || result.Retryable != tt.retryable {
+				t.Errorf("result = %+v, want code %s retryable %v", result, tt.code, tt.retryable)
+			}
+			if errors.Is(err, ErrUnregisteredToken) != tt.unregistered {
+				t.Errorf("errors.Is(err, ErrUnregisteredToken) = %v, want %v", !tt.unregistered, tt.unregistered)
+			}
+		})
+	}
+}
+
+func TestPushProviderSendPlatformNotConfigured(t *testing.T) {
+	config := &settings.Push{}
+	config.Load()
+	provider, err := NewPushProvider(nil, config)
+	if err != nil {
+		t.Fatalf("new push provider: %v", err)
+	}
+
+	result, err := provider.Send(context.Background(), "n-1", "apns:abc123", "hello")
+
+	var sendErr *SendError
+	if !errors.As(err, &sendErr) || sendErr.Code != "PLATFORM_NOT_CONFIGURED" || result.Retryable {
+		t.Errorf("result = %+v err = %v, want permanent PLATFORM_NOT_CONFIGURED", result, err)
+	}
+}
@@ -0,0 +1,48 @@
+package providers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	ReceiptDelivered  = "delivered"
+	ReceiptBounced    = "bounced"
+	ReceiptComplained = "complained"
+	ReceiptRead       = "read"
+)
+
+var ErrInvalidSignature = errors.New("invalid receipt signature")
+
+// Receipt is a delivery report of a provider about a message it accepted earlier.
+type Receipt struct {
+	ProviderMessageId string
+	Status            string
+	Reason            string
+	OccurredAt        time.Time
+	Payload           json.RawMessage
+}
+
+// ReceiptParser verifies the signature of a provider's delivery report callback and reads
+// the receipts it carries. Events without a tracked status are dropped.
+type ReceiptParser interface {
+	Channel() string
+	Parse(header http.Header, body []byte) ([]Receipt, error)
+}
+
+// checkSignedAt rejects a signed unix timestamp further than tolerance from now, so a captured
+// callback cannot be replayed later.
+func checkSignedAt(timestamp string, tolerance time.Duration) error {
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	if age := time.Since(time.Unix(seconds, 0)); age > tolerance || age < -tolerance {
+		return fmt.Errorf("%w: timestamp is outside the %s tolerance", ErrInvalidSignature, tolerance)
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package providers
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type mailgunWebhook struct {
+	Signature struct {
+		Timestamp string `json:"timestamp"`
+		Token     string `json:"token"`
+		Signature string `json:"signature"`
+	} `json:"signature"`
+	EventData struct {
+		Event     string  `json:"event"`
+		Timestamp float64 `json:"timestamp"`
+		Severity  string  `json:"severity"`
+		Reason    string  `json:"reason"`
+		Message   struct {
+			Headers struct {
+				MessageId string `json:"message-id"`
+			} `json:"headers"`
+		} `json:"message"`
+		DeliveryStatus struct {
+			Message     string `json:"message"`
+			Description string `json:"description"`
+		} `json:"delivery-status"`
+	} `json:"event-data"`
+}
+
+// MailgunReceiptParser reads Mailgun webhooks, signed with the account's HTTP webhook signing
+// key over the timestamp and token of the request. Webhooks signed more than tolerance
+// away from now are rejected.
+type MailgunReceiptParser struct {
+	signingKey string
+	tolerance  time.Duration
+}
+
+func NewMailgunReceiptParser(signingKey string, tolerance time.Duration) *MailgunReceiptParser {
+	return &MailgunReceiptParser{signingKey: signingKey, tolerance: tolerance}
+}
+
+func (p *MailgunReceiptParser) Channel() string {
+	return "email"
+}
+
+func (p *MailgunReceiptParser) Parse(header http.Header, body []byte) ([]Receipt, error) {
+	var webhook mailgunWebhook
+	if err := json.Unmarshal(body, &webhook); err != nil {
+		return nil, fmt.Errorf("parse mailgun receipt: %w", err)
+	}
+
+	expected, err := hex.DecodeString(webhook.Signature.Signature)
+	if err != nil {
+		return nil, ErrInvalidSignature
+	}
+	mac := hmac.New(sha256.New, []byte(p.signingKey))
+	mac.Write([]byte(webhook.Signature.Timestamp + webhook.Signature.Token))
+	if !hmac.Equal(expected, mac.Sum(nil)) {
+		return nil, ErrInvalidSignature
+	}
+	if err := checkSignedAt(webhook.Signature.Timestamp, p.tolerance); err != nil {
+		return nil, err
+	}
+
+	event := webhook.EventData
+	var status string
+	switch event.Event {
+	case "delivered":
+		status = ReceiptDelivered
+	case "failed":
+		// Temporary failures are retried by Mailgun.
+		if event.Severity != "permanent" {
+			return nil, nil
+		}
+		status = ReceiptBounced
+	case "complained":
+		status = ReceiptComplained
+	case "opened":
+		status = ReceiptRead
+	default:
+		return nil, nil
+	}
+
+	messageId := strings.Trim(event.Message.Headers.MessageId, "<>")
+	if messageId == "" {
+		return nil, nil
+	}
+
+	reason := event.DeliveryStatus.Description
+	if reason == "" {
+		reason = event.DeliveryStatus.Message
+	}
+	if reason == "" {
+		reason = event.Reason
+	}
+
+	return []Receipt{{
+		ProviderMessageId: "<" + messageId + ">",
+		Status:            status,
+		Reason:            reason,
+		OccurredAt:        parseReceiptTime(strconv.FormatFloat(event.Timestamp, 'f', -1, 64)),
+		Payload:           body,
+	}}, nil
+}
@@ -0,0 +1,120 @@
+package providers
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	sendgridSignatureHeader = "X-Twilio-Email-Event-Webhook-Signature"
+	sendgridTimestampHeader = "X-Twilio-Email-Event-Webhook-Timestamp"
+)
+
+type sendgridEvent struct {
+	Event     string `json:"event"`
+	Timestamp int64  `json:"timestamp"`
+	SmtpId    string `json:"smtp-id"`
+	Reason    string `json:"reason"`
+	Type      string `json:"type"`
+}
+
+// SendgridReceiptParser reads SendGrid Event Webhook batches, signed with ECDSA over the
+// timestamp header and the body. Events are correlated by their smtp-id, the Message-Id
+// header the email provider set. Batches signed more than tolerance away from now are
+// rejected.
+type SendgridReceiptParser struct {
+	publicKey *ecdsa.PublicKey
+	tolerance time.Duration
+}
+
+// NewSendgridReceiptParser takes the base64 verification key shown in the SendGrid console.
+func NewSendgridReceiptParser(publicKey string, tolerance time.Duration) (*SendgridReceiptParser, error) {
+	der, err := base64.StdEncoding.DecodeString(publicKey)
+	if err != nil {
+		return nil, fmt.Errorf("sendgrid public key: %w", err)
+	}
+	key, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("sendgrid public key: %w", err)
+	}
+	ecKey, ok := key.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, errors.New("sendgrid public key is not an ECDSA key")
+	}
+
+	return &SendgridReceiptParser{publicKey: ecKey, tolerance: tolerance}, nil
+}
+
+func (p *SendgridReceiptParser) Channel() string {
+	return "email"
+}
+
+func (p *SendgridReceiptParser) Parse(header http.Header, body []byte) ([]Receipt, error) {
+	signature, err := base64.StdEncoding.DecodeString(header.Get(sendgridSignatureHeader))
+	if err != nil {
+		return nil, ErrInvalidSignature
+	}
+	timestamp := header.Get(sendgridTimestampHeader)
+	digest := sha256.Sum256(append([]byte(timestamp), body...))
+	if !ecdsa.VerifyASN1(p.publicKey, digest[:], signature) {
+		return nil, ErrInvalidSignature
+	}
+	if err := checkSignedAt(timestamp, p.tolerance); err != nil {
+		return nil, err
+	}
+
+	var raw []json.RawMessage
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, fmt.Errorf("parse sendgrid receipt: %w", err)
+	}
+
+	var receipts []Receipt
+	for _, item := range raw {
+		var event sendgridEvent
+		if err := json.Unmarshal(item, &event); err != nil {
+			return nil, fmt.Errorf("parse sendgrid receipt: %w", err)
+		}
+
+		var status string
+		switch event.Event {
+		case "delivered":
+			status = ReceiptDelivered
+		case "bounce", "dropped":
+			// Blocked messages are temporary and retried by SendGrid.
+			if event.Type == "blocked" {
+				continue
+			}
+			status = ReceiptBounced
+		case "spamreport":
+			status = ReceiptComplained
+		case "open":
+			status = ReceiptRead
+		default:
+			continue
+		}
+
+		messageId := strings.Trim(event.SmtpId, "<>")
+		if messageId == "" {
+			continue
+		}
+
+		receipts = append(receipts, Receipt{
+			ProviderMessageId: "<" + messageId + ">",
+			Status:            status,
+			Reason:            event.Reason,
+			OccurredAt:        parseReceiptTime(strconv.FormatInt(event.Timestamp, 10)),
+			Payload:           item,
+		})
+	}
+
+	return receipts, nil
+}
@@ -0,0 +1,100 @@
+package providers
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/HuseyinAsik/Notifications/pkg/settings"
+)
+
+// RESTSMSReceiptParser reads the delivery reports of generic JSON REST gateways whose fields
+// are described by settings.Receipt. The body is signed with a hex HMAC-SHA256 of a shared
+// secret, optionally prefixed with "sha256=". Gateways that send a unix timestamp header sign
+// "{timestamp}.{body}" instead, and reports signed more than the tolerance away from now are
+// rejected. A body may hold one report or an array.
+type RESTSMSReceiptParser struct {
+	config *settings.Receipt
+}
+
+func NewRESTSMSReceiptParser(config *settings.Receipt) *RESTSMSReceiptParser {
+	return &RESTSMSReceiptParser{config: config}
+}
+
+func (p *RESTSMSReceiptParser) Channel() string {
+	return "sms"
+}
+
+func (p *RESTSMSReceiptParser) Parse(header http.Header, body []byte) ([]Receipt, error) {
+	signature := strings.TrimPrefix(header.Get(p.config.SmsSignatureHeader), "sha256=")
+	expected, err := hex.DecodeString(signature)
+	if err != nil {
+		return nil, ErrInvalidSignature
+	}
+	var timestamp string
+	if p.config.SmsTimestampHeader != "" {
+		timestamp = header.Get(p.config.SmsTimestampHeader)
+	}
+	mac := hmac.New(sha256.New, []byte(p.config.SmsSecret))
+	if timestamp != "" {
+		mac.Write([]byte(timestamp + "."))
+	}
+	mac.Write(body)
+	if !hmac.Equal(expected, mac.Sum(nil)) {
+		return nil, ErrInvalidSignature
+	}
+	if p.config.SmsTimestampHeader != "" {
+		if err := checkSignedAt(timestamp, p.config.Tolerance); err != nil {
+			return nil, err
+		}
+	}
+
+	var reports []map[string]any
+	if trimmed := strings.TrimSpace(string(body)); strings.HasPrefix(trimmed, "[") {
+		err = json.Unmarshal(body, &reports)
+	} else {
+		var report map[string]any
+		err = json.Unmarshal(body, &report)
+		reports = append(reports, report)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parse sms receipt: %w", err)
+	}
+
+	var receipts []Receipt
+	for _, report := range reports {
+		status, ok := p.config.SmsStatusMap[strings.ToUpper(fieldString(report, p.config.SmsStatusField))]
+		messageId := fieldString(report, p.config.SmsMessageIdField)
+		if !ok || messageId == "" {
+			continue
+		}
+
+		payload, _ := json.Marshal(report)
+		receipts = append(receipts, Receipt{
+			ProviderMessageId: messageId,
+			Status:            status,
+			Reason:            fieldString(report, p.config.SmsReasonField),
+			OccurredAt:        parseReceiptTime(fieldString(report, p.config.SmsTimestampField)),
+			Payload:           payload,
+		})
+	}
+
+	return receipts, nil
+}
+
+// parseReceiptTime reads unix seconds or an RFC 3339 time; anything else means now.
+func parseReceiptTime(value string) time.Time {
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds > 0 {
+		return time.Unix(0, int64(seconds*float64(time.Second))).UTC()
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.UTC()
+	}
+	return time.Now().UTC()
+}
@@ -0,0 +1,258 @@
+package providers
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/HuseyinAsik/Notifications/pkg/settings"
+)
+
+func hmacHex(secret, message string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(message))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func unixString(t time.Time) string {
+	return strconv.FormatInt(t.Unix(), 10)
+}
+
+func newSMSReceiptConfig(timestampHeader string) *settings.Receipt {
+	config := &settings.Receipt{SmsSecret: "dlr-secret", SmsTimestampHeader: timestampHeader}
+	config.Load()
+	return config
+}
+
+func TestRESTSMSReceiptParser(t *testing.T) {
+	body := `[{"messageId":"m1","status":"DELIVRD","timestamp":1767225600},{"messageId":"m2","status":"undeliv","error":"absent"},{"messageId":"m3","status":"ENROUTE"}]`
+
+	parser := NewRESTSMSReceiptParser(newSMSReceiptConfig(""))
+	header := http.Header{"X-Signature": {"sha256=" + hmacHex("dlr-secret", body)}}
+	receipts, err := parser.Parse(header, []byte(body))
+	if err != nil {
+		t.Fatalf("Parse() err = %v", err)
+	}
+	if len(receipts) != 2 {
+		t.Fatalf("Parse() = %d receipts, want 2 (unmapped statuses are dropped)", len(receipts))
+	}
+	if r := receipts[0]; r.ProviderMessageId != "m1" || r.Status != ReceiptDelivered || !r.OccurredAt.Equal(time.Unix(1767225600, 0)) {
+		t.Errorf("receipts[0] = %+v", r)
+	}
+	if r := receipts[1]; r.ProviderMessageId != "m2" || r.Status != ReceiptBounced || r.Reason != "absent" {
+		t.Errorf("receipts[1] = %+v", r)
+	}
+}
+
+func TestRESTSMSReceiptParserRejectsTamperedBody(t *testing.T) {
+	body := `{"messageId":"m1","status":"DELIVRD"}`
+	header := http.Header{"X-Signature": {hmacHex("dlr-secret", body)}}
+
+	parser := NewRESTSMSReceiptParser(newSMSReceiptConfig(""))
+	tampered := `{"messageId":"m2","status":"DELIVRD"}`
+	if _, err := parser.Parse(header, []byte(tampered)); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Parse(tampered body) err = %v, want ErrInvalidSignature", err)
+	}
+	if _, err := parser.Parse(http.Header{}, []byte(body)); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Parse(unsigned) err = %v, want ErrInvalidSignature", err)
+	}
+}
+
+func TestRESTSMSReceiptParserSignedTimestamp(t *testing.T) {
+	body := `{"messageId":"m1","status":"DELIVRD"}`
+	parser := NewRESTSMSReceiptParser(newSMSReceiptConfig("X-Timestamp"))
+
+	signed := func(at time.Time) http.Header {
+		timestamp := unixString(at)
+		return http.Header{
+			"X-Timestamp": {timestamp},
+			"X-Signature": {hmacHex("dlr-secret", timestamp+"."+body)},
+		}
+	}
+
+	if _, err := parser.Parse(signed(time.Now()), []byte(body)); err != nil {
+		t.Errorf("Parse(fresh) err = %v", err)
+	}
+	if _, err := parser.Parse(signed(time.Now().Add(-time.Hour)), []byte(body)); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Parse(stale) err = %v, want ErrInvalidSignature", err)
+	}
+
+	// A fresh timestamp swapped into a captured callback breaks the signature.
+	header := signed(time.Now().Add(-time.Hour))
+	header.Set("X-Timestamp", unixString(time.Now()))
+	if _, err := parser.Parse(header, []byte(body)); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Parse(replaced timestamp) err = %v, want ErrInvalidSignature", err)
+	}
+}
+
+func mailgunBody(t *testing.T, signingKey string, at time.Time, event string) []byte {
+	t.Helper()
+
+	timestamp := unixString(at)
+	webhook := map[string]any{
+		"signature": map[string]any{
+			"timestamp": timestamp,
+			"token":     "a1b2c3",
+			"signature": hmacHex(signingKey, timestamp+"a1b2c3"),
+		},
+		"event-data": map[string]any{
+			"event":     event,
+			"timestamp": 1767225600.5,
+			"severity":  "permanent",
+			"message":   map[string]any{"headers": map[string]any{"message-id": "20260101.abc@mg.example.com"}},
+			"delivery-status": map[string]any{
+				"description": "mailbox does not exist",
+			},
+		},
+	}
+	body, err := json.Marshal(webhook)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return body
+}
+
+func TestMailgunReceiptParser(t *testing.T) {
+	parser := NewMailgunReceiptParser("mg-key", 5*time.Minute)
+
+	receipts, err := parser.Parse(http.Header{}, mailgunBody(t, "mg-key", time.Now(), "failed"))
+	if err != nil {
+		t.Fatalf("Parse() err = %v", err)
+	}
+	if len(receipts) != 1 {
+		t.Fatalf("Parse() = %d receipts, want 1", len(receipts))
+	}
+	r := receipts[0]
+	if r.ProviderMessageId != "<20260101.abc@mg.example.com>" || r.Status != ReceiptBounced || r.Reason != "mailbox does not exist" {
+		t.Errorf("receipt = %+v", r)
+	}
+	if !r.OccurredAt.Equal(time.Unix(1767225600, 500_000_000)) {
+		t.Errorf("OccurredAt = %v", r.OccurredAt)
+	}
+
+	receipts, err = parser.Parse(http.Header{}, mailgunBody(t, "mg-key", time.Now(), "accepted"))
+	if err != nil || len(receipts) != 0 {
+		t.Errorf("Parse(untracked event) = %v, %v; want no receipts", receipts, err)
+	}
+}
+
+func TestMailgunReceiptParserRejectsTamperedSignature(t *testing.T) {
+	parser := NewMailgunReceiptParser("mg-key", 5*time.Minute)
+
+	if _, err := parser.Parse(http.Header{}, mailgunBody(t, "other-key", time.Now(), "delivered")); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Parse(wrong key) err = %v, want ErrInvalidSignature", err)
+	}
+
+	var webhook map[string]any
+	if err := json.Unmarshal(mailgunBody(t, "mg-key", time.Now().Add(-time.Hour), "delivered"), &webhook); err != nil {
+		t.Fatal(err)
+	}
+	webhook["signature"].(map[string]any)["timestamp"] = unixString(time.Now())
+	body, _ := json.Marshal(webhook)
+	if _, err := parser.Parse(http.Header{}, body); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Parse(replaced timestamp) err = %v, want ErrInvalidSignature", err)
+	}
+}
+
+func TestMailgunReceiptParserRejectsStaleTimestamp(t *testing.T) {
+	parser := NewMailgunReceiptParser("mg-key", 5*time.Minute)
+
+	for _, at := range []time.Time{time.Now().Add(-10 * time.Minute), time.Now().Add(10 * time.Minute)} {
+		if _, err := parser.Parse(http.Header{}, mailgunBody(t, "mg-key", at, "delivered")); !errors.Is(err, ErrInvalidSignature) {
+			t.Errorf("Parse(signed at %v) err = %v, want ErrInvalidSignature", at, err)
+		}
+	}
+}
+
+func newSendgridParser(t *testing.T) (*SendgridReceiptParser, *ecdsa.PrivateKey) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parser, err := NewSendgridReceiptParser(base64.StdEncoding.EncodeToString(der), 5*time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parser, key
+}
+
+func signSendgrid(t *testing.T, key *ecdsa.PrivateKey, at time.Time, body string) http.Header {
+	t.Helper()
+
+	timestamp := unixString(at)
+	digest := sha256.Sum256([]byte(timestamp + body))
+	signature, err := ecdsa.SignASN1(rand.Reader, key, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return http.Header{
+		sendgridSignatureHeader: {base64.StdEncoding.EncodeToString(signature)},
+		sendgridTimestampHeader: {timestamp},
+	}
+}
+
+const sendgridBatch = `[
+	{"event":"delivered","timestamp":1767225600,"smtp-id":"<m1@example.com>"},
+	{"event":"bounce","timestamp":1767225601,"smtp-id":"<m2@example.com>","reason":"550 no such user"},
+	{"event":"bounce","type":"blocked","timestamp":1767225602,"smtp-id":"<m3@example.com>"},
+	{"event":"processed","timestamp":1767225603,"smtp-id":"<m4@example.com>"}
+]`
+
+func TestSendgridReceiptParser(t *testing.T) {
+	parser, key := newSendgridParser(t)
+
+	receipts, err := parser.Parse(signSendgrid(t, key, time.Now(), sendgridBatch), []byte(sendgridBatch))
+	if err != nil {
+		t.Fatalf("Parse() err = %v", err)
+	}
+	if len(receipts) != 2 {
+		t.Fatalf("Parse() = %d receipts, want 2 (blocked and untracked events are dropped)", len(receipts))
+	}
+	if r := receipts[0]; r.ProviderMessageId != "<m1@example.com>" || r.Status != ReceiptDelivered || !r.OccurredAt.Equal(time.Unix(1767225600, 0)) {
+		t.Errorf("receipts[0] = %+v", r)
+	}
+	if r := receipts[1]; r.ProviderMessageId != "<m2@example.com>" || r.Status != ReceiptBounced || r.Reason != "550 no such user" {
+		t.Errorf("receipts[1] = %+v", r)
+	}
+}
+
+func TestSendgridReceiptParserRejectsTamperedSignature(t *testing.T) {
+	parser, key := newSendgridParser(t)
+	header := signSendgrid(t, key, time.Now(), sendgridBatch)
+
+	tampered := `[{"event":"delivered","timestamp":1767225600,"smtp-id":"<other@example.com>"}]`
+	if _, err := parser.Parse(header, []byte(tampered)); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Parse(tampered body) err = %v, want ErrInvalidSignature", err)
+	}
+
+	_, otherKey := newSendgridParser(t)
+	if _, err := parser.Parse(signSendgrid(t, otherKey, time.Now(), sendgridBatch), []byte(sendgridBatch)); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Parse(other key) err = %v, want ErrInvalidSignature", err)
+	}
+}
+
+func TestSendgridReceiptParserRejectsStaleTimestamp(t *testing.T) {
+	parser, key := newSendgridParser(t)
+
+	header := signSendgrid(t, key, time.Now().Add(-time.Hour), sendgridBatch)
+	if _, err := parser.Parse(header, []byte(sendgridBatch)); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Parse(stale) err = %v, want ErrInvalidSignature", err)
+	}
+}
@@ -1,8 +1,98 @@
 package providers
 
-type SMSProvider struct{}
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
 
-func (s *SMSProvider) Send(id, to, content string) error {
+	"github.com/HuseyinAsik/Notifications/pkg/httpx"
+)
 
-	return nil
+var e164Pattern = regexp.MustCompile(`^\+[1-9][0-9]{7,14}$`)
+
+type SMSProvider struct {
+	client             httpx.HTTPClient
+	adapter            SMSAdapter
+	defaultCountryCode string
+	timeout            time.Duration
+}
+
+func NewSMSProvider(client httpx.HTTPClient, adapter SMSAdapter, defaultCountryCode string, timeout time.Duration) *SMSProvider {
+	return &SMSProvider{
+		client:             client,
+		adapter:            adapter,
+		defaultCountryCode: defaultCountryCode,
+		timeout:            timeout,
+	}
+}
+
+func (s *SMSProvider) Send(ctx context.Context, id, to, content string) (SendResult, error) {
+	recipient, err := NormalizeE164(to, s.defaultCountryCode)
+	if err != nil {
+		err = PermanentError("INVALID_RECIPIENT", err)
+		return Rejected(err), err
+	}
+
+	req, err := s.adapter.BuildRequest(id, recipient, content)
+	if err != nil {
+		err = PermanentError("INVALID_REQUEST", err)
+		return Rejected(err), err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	var response map[string]any
+	status, header, doErr := s.client.DoJSONWithHeaders(ctx, req.Method, req.URL, req.Body, req.Headers, &response)
+	if doErr != nil && status == 0 {
+		err = RetryableError("NETWORK_ERROR", doErr)
+		return Rejected(err), err
+	}
+
+	result, err := s.adapter.ParseResponse(status, response)
+	if err != nil {
+		err = withRetryAfter(err, header)
+		return Rejected(err), err
+	}
+
+	return result, nil
+}
+
+// NormalizeE164 converts a phone number to E.164. Only a number with a leading "+" or "00"
+// is international; any other number is national and gets defaultCountryCode.
+func NormalizeE164(number, defaultCountryCode string) (string, error) {
+	var digits strings.Builder
+	for i, r := range strings.TrimSpace(number) {
+		switch {
+		case r >= '0' && r <= '9':
+			digits.WriteRune(r)
+		case r == '+' && i == 0:
+			digits.WriteRune(r)
+		case r == ' ' || r == '-' || r == '(' || r == ')' || r == '.':
+		default:
+			return "", fmt.Errorf("invalid character %q in phone number", r)
+		}
+	}
+
+	normalized := digits.String()
+	switch {
+	case strings.HasPrefix(normalized, "+"):
+	case strings.HasPrefix(normalized, "00"):
+		normalized = "+" + normalized[2:]
+	case defaultCountryCode == "":
+		return "", errors.New("phone number has no country code")
+	case strings.HasPrefix(normalized, "0"):
+		normalized = "+" + defaultCountryCode + strings.TrimLeft(normalized, "0")
+	default:
+		normalized = "+" + defaultCountryCode + normalized
+	}
+
+	if !e164Pattern.MatchString(normalized) {
+		return "", fmt.Errorf("phone number %q is not a valid E.164 number", normalized)
+	}
+
+	return normalized, nil
 }
@@ -0,0 +1,164 @@
+package providers
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/HuseyinAsik/Notifications/pkg/settings"
+)
+
+type SMSRequest struct {
+	Method  string
+	URL     string
+	Body    any
+	Headers map[string]string
+}
+
+// SMSAdapter translates a message into a gateway specific request and interprets its response.
+type SMSAdapter interface {
+	BuildRequest(id, to, content string) (SMSRequest, error)
+	ParseResponse(status int, response map[string]any) (SendResult, error)
+}
+
+// RESTSMSAdapter talks to generic JSON REST gateways whose request and response
+// fields are described by settings.Sms.
+type RESTSMSAdapter struct {
+	config *settings.Sms
+}
+
+func NewRESTSMSAdapter(config *settings.Sms) *RESTSMSAdapter {
+	return &RESTSMSAdapter{config: config}
+}
+
+func (a *RESTSMSAdapter) BuildRequest(id, to, content string) (SMSRequest, error) {
+	replacer := strings.NewReplacer(
+		"{to}", url.QueryEscape(to),
+		"{id}", url.QueryEscape(id),
+		"{sender}", url.QueryEscape(a.config.Sender),
+		"{message}", url.QueryEscape(content),
+	)
+	req := SMSRequest{
+		Method:  a.config.Method,
+		URL:     replacer.Replace(a.config.GatewayURL),
+		Headers: map[string]string{"Accept": "application/json"},
+	}
+	if a.config.AuthHeader != "" {
+		req.Headers[a.config.AuthHeader] = a.config.AuthValue
+	}
+
+	if a.config.Method == "GET" {
+		// A GET gateway has no body, so the message travels in the URL: either through the
+		// {message} placeholder or as query parameters named after the configured fields.
+		if strings.Contains(a.config.GatewayURL, "{message}") {
+			return req, nil
+		}
+		gatewayURL, err := url.Parse(req.URL)
+		if err != nil {
+			return SMSRequest{}, fmt.Errorf("invalid sms gateway url: %w", err)
+		}
+		query := gatewayURL.Query()
+		query.Set(a.config.ToField, to)
+		query.Set(a.config.MessageField, content)
+		if a.config.Sender != "" {
+			query.Set(a.config.SenderField, a.config.Sender)
+		}
+		if a.config.ReferenceField != "" {
+			query.Set(a.config.ReferenceField, id)
+		}
+		gatewayURL.RawQuery = query.Encode()
+		req.URL = gatewayURL.String()
+		return req, nil
+	}
+
+	body := map[string]any{}
+	setField(body, a.config.ToField, to)
+	setField(body, a.config.MessageField, content)
+	if a.config.Sender != "" {
+		setField(body, a.config.SenderField, a.config.Sender)
+	}
+	if a.config.ReferenceField != "" {
+		setField(body, a.config.ReferenceField, id)
+	}
+	req.Body = body
+
+	return req, nil
+}
+
+func (a *RESTSMSAdapter) ParseResponse(status int, response map[string]any) (SendResult, error) {
+	code := fieldString(response, a.config.ErrorCodeField)
+	success := code == "" || slices.Contains(a.config.SuccessCodes, code)
+	if status >= 200 && status < 300 && success {
+		result := SendResult{
+			ProviderMessageId: fieldString(response, a.config.MessageIdField),
+			Status:            SendAccepted,
+			ProviderCode:      strconv.Itoa(status),
+		}
+		if status == 202 {
+			result.Status = SendQueued
+		}
+		return result, nil
+	}
+
+	err := fmt.Errorf("sms gateway responded with status %d", status)
+	if success {
+		code = fmt.Sprintf("HTTP_%d", status)
+	}
+
+	switch {
+	case slices.Contains(a.config.RetryableCodes, code):
+		return SendResult{}, RetryableError(code, err)
+	case slices.Contains(a.config.PermanentCodes, code):
+		return SendResult{}, PermanentError(code, err)
+	case status >= 200 && status < 300:
+		return SendResult{}, PermanentError(code, errors.New("sms gateway rejected the message"))
+	case retryableStatus(status):
+		return SendResult{}, RetryableError(code, err)
+	default:
+		return SendResult{}, PermanentError(code, err)
+	}
+}
+
+// setField assigns value at a dotted path such as "message.text".
+func setField(body map[string]any, path string, value any) {
+	keys := strings.Split(path, ".")
+	current := body
+	for _, key := range keys[:len(keys)-1] {
+		next, ok := current[key].(map[string]any)
+		if !ok {
+			next = map[string]any{}
+			current[key] = next
+		}
+		current = next
+	}
+	current[keys[len(keys)-1]] = value
+}
+
+// fieldString reads a dotted path such as "data.id" from a decoded JSON response.
+func fieldString(response map[string]any, path string) string {
+	if path == "" {
+		return ""
+	}
+	var current any = response
+	for _, key := range strings.Split(path, ".") {
+		object, ok := current.(map[string]any)
+		if !ok {
+			return ""
+		}
+		current = object[key]
+	}
+
+	switch value := current.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	default:
+		return fmt.Sprint(value)
+	}
+}
@@ -0,0 +1,235 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/HuseyinAsik/Notifications/pkg/httpx"
+	"github.com/HuseyinAsik/Notifications/pkg/logging"
+	"github.com/HuseyinAsik/Notifications/pkg/settings"
+	"go.uber.org/zap"
+)
+
+// gatewayRequest is what the stub SMS gateway received.
+type gatewayRequest struct {
+	Method string
+	Path   string
+	Query  map[string]string
+	Header http.Header
+	Body   map[string]any
+}
+
+// newSMSGateway starts a gateway that records each request and answers with status and
+// response.
+func newSMSGateway(t *testing.T, status int, response string, header http.Header) (*httptest.Server, *gatewayRequest) {
+	t.Helper()
+
+	received := &gatewayRequest{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received.Method = r.Method
+		received.Path = r.URL.Path
+		received.Header = r.Header.Clone()
+		received.Query = map[string]string{}
+		for key := range r.URL.Query() {
+			received.Query[key] = r.URL.Query().Get(key)
+		}
+		if r.ContentLength > 0 {
+			_ = json.NewDecoder(r.Body).Decode(&received.Body)
+		}
+
+		for key, values := range header {
+			w.Header()[key] = values
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(response))
+	}))
+	t.Cleanup(server.Close)
+
+	return server, received
+}
+
+func smsConfig(gatewayURL, method string) *settings.Sms {
+	config := &settings.Sms{
+		GatewayURL:         gatewayURL,
+		Method:             method,
+		AuthHeader:         "Authorization",
+		AuthValue:          "Bearer token",
+		Sender:             "NOTIFY",
+		DefaultCountryCode: "90",
+		ReferenceField:     "reference",
+		MessageIdField:     "data.id",
+		RetryableCodesStr:  "THROTTLED",
+		PermanentCodesStr:  "BLOCKED",
+	}
+	config.Load()
+
+	return config
+}
+
+func newTestSMSProvider(config *settings.Sms) *SMSProvider {
+	client := httpx.NewHTTPClient(&http.Client{}, &logging.LogWrapper{ZapLogger: zap.NewNop()})
+	return NewSMSProvider(client, NewRESTSMSAdapter(config), config.DefaultCountryCode, 5*time.Second)
+}
+
+func TestSMSProviderSendPost(t *testing.T) {
+	server, received := newSMSGateway(t, http.StatusOK, `{"data":{"id":"gw-1"}}`, nil)
+	provider := newTestSMSProvider(smsConfig(server.URL+"/v1/messages", "POST"))
+
+	result, err := provider.Send(context.Background(), "n-1", "0532 123 45 67", "Your code is 1234")
+	if err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	if result.Status != SendAccepted || result.ProviderMessageId != "gw-1" {
+		t.Errorf("result = %+v, want accepted gw-1", result)
+	}
+	if received.Method != "POST" || received.Path != "/v1/messages" {
+		t.Errorf("request = %s %s", received.Method, received.Path)
+	}
+	if got := received.Header.Get("Authorization"); got != "Bearer token" {
+		t.Errorf("Authorization = %q", got)
+	}
+	want := map[string]any{"to": "+905321234567", "message": "Your code is 1234", "from": "NOTIFY", "reference": "n-1"}
+	for key, value := range want {
+		if received.Body[key] != value {
+			t.Errorf("body[%s] = %v, want %v", key, received.Body[key], value)
+		}
+	}
+}
+
+func TestSMSProviderSendGetQuery(t *testing.T) {
+	server, received := newSMSGateway(t, http.StatusAccepted, `{"data":{"id":"gw-2"}}`, nil)
+	provider := newTestSMSProvider(smsConfig(server.URL+"/send?apikey=k", "GET"))
+
+	result, err := provider.Send(context.Background(), "n-2", "+905321234567", "Hello & welcome")
+	if err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	if result.Status != SendQueued {
+		t.Errorf("status = %q, want %q", result.Status, SendQueued)
+	}
+	want := map[string]string{"apikey": "k", "to": "+905321234567", "message": "Hello & welcome", "from": "NOTIFY", "reference": "n-2"}
+	for key, value := range want {
+		if received.Query[key] != value {
+			t.Errorf("query[%s] = %q, want %q", key, received.Query[key], value)
+		}
+	}
+}
+
+func TestSMSProviderSendGetPlaceholders(t *testing.T) {
+	server, received := newSMSGateway(t, http.StatusOK, `{}`, nil)
+	provider := newTestSMSProvider(smsConfig(server.URL+"/send?dst={to}&text={message}&ref={id}", "GET"))
+
+	if _, err := provider.Send(context.Background(), "n-3", "+905321234567", "50% off & more"); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	want := map[string]string{"dst": "+905321234567", "text": "50% off & more", "ref": "n-3"}
+	for key, value := range want {
+		if received.Query[key] != value {
+			t.Errorf("query[%s] = %q, want %q", key, received.Query[key], value)
+		}
+	}
+	if _, ok := received.Query["message"]; ok {
+		t.Error("message was also sent as a query parameter")
+	}
+}
+
+func TestSMSProviderSendClassifiesResponses(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		response   string
+		header     http.Header
+		wantErr    bool
+		code       string
+		retryable  bool
+		retryAfter time.Duration
+	}{
+		{name: "numeric success code", status: 200, response: `{"errorCode":0,"data":{"id":"gw"}}`},
+		{name: "string success code", status: 200, response: `{"errorCode":"0","data":{"id":"gw"}}`},
+		{name: "retryable code", status: 200, response: `{"errorCode":"THROTTLED"}`, wantErr: true, code: "THROTTLED", retryable: true},
+		{name: "permanent code", status: 400, response: `{"errorCode":"BLOCKED"}`, wantErr: true, code: "BLOCKED"},
+		{name: "unknown code on 2xx", status: 200, response: `{"errorCode":"E42"}`, wantErr: true, code: "E42"},
+		{name: "success code on 5xx", status: 503, response: `{"errorCode":0}`, wantErr: true, code: "HTTP_503", retryable: true},
+		{name: "rate limited", status: 429, response: `{}`, header: http.Header{"Retry-After": {"30"}}, wantErr: true, code: "HTTP_429", retryable: true, retryAfter: 30 * time.Second},
+		{name: "bad request", status: 400, response: `{}`, wantErr: true, code: "HTTP_400"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, _ := newSMSGateway(t, tt.status, tt.response, tt.header)
+			provider := newTestSMSProvider(smsConfig(server.URL, "POST"))
+
+			result, err := provider.Send(context.Background(), "n", "+905321234567", "hi")
+			if !tt.wantErr {
+				if err != nil || result.Status != SendAccepted {
+					t.Fatalf("result = %+v err = %v, want accepted", result, err)
+				}
+				return
+			}
+
+			if err == nil {
+				t.Fatalf("send succeeded, want %s", tt.code)
+			}
+			if result.ProviderCode != tt.code || result.Retryable != tt.retryable || result.RetryAfter != tt.retryAfter {
+				t.Errorf("result = %+v, want code %s retryable %v retryAfter %s", result, tt.code, tt.retryable, tt.retryAfter)
+			}
+		})
+	}
+}
+
+func TestSMSProviderSendNetworkError(t *testing.T) {
+	server, _ := newSMSGateway(t, http.StatusOK, `{}`, nil)
+	server.Close()
+	provider := newTestSMSProvider(smsConfig(server.URL, "POST"))
+
+	result, err := provider.Send(context.Background(), "n", "+905321234567", "hi")
+	if err == nil {
+		t.Fatal("send succeeded, want an error")
+	}
+	if result.ProviderCode != "NETWORK_ERROR" || !result.Retryable {
+		t.Errorf("result = %+v, want retryable NETWORK_ERROR", result)
+	}
+}
+
+func TestNormalizeE164(t *testing.T) {
+	tests := []struct {
+		number  string
+		country string
+		want    string
+		wantErr bool
+	}{
+		{number: "+90 532 123 45 67", want: "+905321234567"},
+		{number: "0090 532 123 45 67", want: "+905321234567"},
+		{number: "0532 123 45 67", country: "90", want: "+905321234567"},
+		{number: "532 123 45 67", country: "90", want: "+905321234567"},
+		{number: "905321234567", country: "90", want: "+90905321234567"},
+		{number: "00905321234567", country: "90", want: "+905321234567"},
+		{number: "(212) 555-0100", country: "1", want: "+12125550100"},
+		{number: "532 123 45 67", wantErr: true},
+		{number: "+90 532 abc", wantErr: true},
+		{number: "+1234", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.number, func(t *testing.T) {
+			got, err := NormalizeE164(tt.number, tt.country)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("NormalizeE164(%q) = %q, want an error", tt.number, got)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("NormalizeE164(%q) = %q, %v, want %q", tt.number, got, err, tt.want)
+			}
+		})
+	}
+}
@@ -10,12 +10,97 @@ import (
 type NotificationRepository interface {
 	Create(ctx context.Context, notification models.Notification, event *models.OutboxEvent) error
 	BulkInsertWithOutbox(ctx context.Context, notifications []models.Notification, events []*models.OutboxEvent) error
-	FetchPendingOutbox(ctx context.Context, limit int) ([]models.OutboxEvent, error)
+	ClaimPendingOutbox(ctx context.Context, limit int) ([]models.OutboxEvent, error)
+	CountPendingOutbox(ctx context.Context) (int, error)
 	FetchOutboxEventByAggregateId(ctx context.Context, Id string) (*models.OutboxEvent, error)
-	MarkOutboxPublished(ctx context.Context, ids []string) error
 	MarkOutboxPending(ctx context.Context, ids []string) error
-	UpdateOutboxEvent(ctx context.Context, Id, status string, retryCount int) error
+	ClaimOutboxEvent(ctx context.Context, Id string) (bool, error)
+	ReleaseOutboxEvent(ctx context.Context, Id string) error
+	RequeueStaleOutbox(ctx context.Context, lease time.Duration, limit int) (int, error)
+	UpdateOutboxEvent(ctx context.Context, Id, status string, retryCount int, nextAttemptAt *time.Time) error
 	UpdateNotificationStatus(ctx context.Context, Id, status string) error
-	ListNotifications(ctx context.Context, status, channel string, startDate, endDate *time.Time, limit, offset int) ([]models.Notification, int, error)
-	FindById(ctx context.Context, id string) (*models.Notification, error)
+	UpdateNotificationDelivery(ctx context.Context, Id, status, providerMessageId string) error
+	SuppressNotification(ctx context.Context, Id, reason string) error
+	DeferNotification(ctx context.Context, Id string, until time.Time) error
+	ListNotifications(ctx context.Context, tenantId, status, channel string, startDate, endDate *time.Time, limit, offset int) ([]models.Notification, int, error)
+	FindById(ctx context.Context, tenantId, id string) (*models.Notification, error)
+	GroupStatusCounts(ctx context.Context, tenantId, groupId string) (map[string]int, error)
+	ListGroup(ctx context.Context, tenantId, groupId string, limit, offset int) ([]models.Notification, error)
+	CancelNotification(ctx context.Context, tenantId, id string) error
+	RescheduleNotification(ctx context.Context, tenantId, id string, scheduledAt time.Time, timezone string) error
+	DispatchDueScheduled(ctx context.Context, now time.Time, limit int, buildEvent func(models.Notification) *models.OutboxEvent) (int, error)
+}
+
+type DeadLetterRepository interface {
+	Create(ctx context.Context, deadLetter models.DeadLetter) error
+	List(ctx context.Context, tenantId, channel string, limit, offset int) ([]models.DeadLetter, int, error)
+	Replay(ctx context.Context, ids []string) ([]string, error)
+}
+
+type IdempotencyRepository interface {
+	Reserve(ctx context.Context, tenantId, key, endpoint, requestHash string, lockedUntil, expiresAt time.Time) (bool, error)
+	Find(ctx context.Context, tenantId, key, endpoint string) (*models.IdempotencyKey, error)
+	Complete(ctx context.Context, tenantId, key, endpoint string, statusCode int, response []byte) error
+	Release(ctx context.Context, tenantId, key, endpoint string) error
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
+
+type TemplateRepository interface {
+	Create(ctx context.Context, template models.Template) error
+	AddVersion(ctx context.Context, template models.Template) (*models.Template, error)
+	FindLatest(ctx context.Context, tenantId, id, locale string) (*models.Template, error)
+	ListVersions(ctx context.Context, tenantId, id string) ([]models.Template, error)
+	List(ctx context.Context, tenantId, channel string, limit, offset int) ([]models.Template, int, error)
+	Delete(ctx context.Context, tenantId, id string) error
+}
+
+type PreferenceRepository interface {
+	Upsert(ctx context.Context, preference models.RecipientPreference) error
+	Get(ctx context.Context, tenantId, recipient, channel string) (*models.RecipientPreference, error)
+	ListByRecipient(ctx context.Context, tenantId, recipient string) ([]models.RecipientPreference, error)
+	FindMany(ctx context.Context, tenantId string, recipients, channels []string) ([]models.RecipientPreference, error)
+	Delete(ctx context.Context, tenantId, recipient, channel string) error
+}
+
+type DeviceTokenRepository interface {
+	Invalidate(ctx context.Context, tenantId, token, reason string) error
+	Register(ctx context.Context, tenantId, token string) error
+	IsInvalid(ctx context.Context, tenantId, token string, since time.Time) (bool, error)
+}
+
+type CounterRepository interface {
+	Increment(ctx context.Context, key, notificationId string, windowStart, expiresAt, chargedUntil time.Time, limit int) (bool, bool, error)
+	Decrement(ctx context.Context, key, notificationId string) error
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
+
+type TokenBucketRepository interface {
+	Take(ctx context.Context, key string, ratePerSecond float64, burst int) (bool, error)
+}
+
+type TenantRepository interface {
+	CreateTenant(ctx context.Context, tenant models.Tenant) error
+	ListTenants(ctx context.Context, limit, offset int) ([]models.Tenant, int, error)
+	FindTenant(ctx context.Context, id string) (*models.Tenant, error)
+	CreateApiKey(ctx context.Context, apiKey models.ApiKey) error
+	ListApiKeys(ctx context.Context, tenantId string) ([]models.ApiKey, error)
+	RevokeApiKey(ctx context.Context, id string) error
+	FindApiKeyByHash(ctx context.Context, keyHash string) (*models.ApiKey, error)
+	UpdateWebhook(ctx context.Context, id, url, secret string) error
+}
+
+type WebhookRepository interface {
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]models.WebhookDelivery, error)
+	RecordAttempt(ctx context.Context, delivery models.WebhookDelivery, attempt models.WebhookAttempt) error
+	ListByNotification(ctx context.Context, tenantId, notificationId string) ([]models.WebhookDelivery, error)
+}
+
+type ReceiptRepository interface {
+	Record(ctx context.Context, channel string, receipt models.StatusHistory) error
+	List(ctx context.Context, tenantId, notificationId string) ([]models.StatusHistory, error)
+}
+
+type NotificationEventRepository interface {
+	Append(ctx context.Context, events []models.NotificationEvent) error
+	List(ctx context.Context, tenantId, notificationId string) ([]models.NotificationEvent, error)
 }
@@ -0,0 +1,8 @@
+package repository
+
+import "errors"
+
+var (
+	ErrNotFound      = errors.New("record not found")
+	ErrNotModifiable = errors.New("record can no longer be modified")
+)
@@ -0,0 +1,116 @@
+package postgre
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/HuseyinAsik/Notifications/pkg/gpostgresql"
+	"github.com/jackc/pgx/v5"
+)
+
+type PostgresCounterRepository struct {
+	db *gpostgresql.Pool
+}
+
+func NewPostgresCounterRepository(db *gpostgresql.Pool) *PostgresCounterRepository {
+	return &PostgresCounterRepository{db: db}
+}
+
+// Increment counts the notification against the counter of the window unless it already
+// reached limit. It reports whether the notification is within the limit and whether this
+// call counted it: a notification counted before, until chargedUntil, is within the limit
+// without being counted again.
+func (r *PostgresCounterRepository) Increment(ctx context.Context, key, notificationId string, windowStart, expiresAt, chargedUntil time.Time, limit int) (bool, bool, error) {
+
+	tx, err := r.db.Write.Begin(ctx)
+	if err != nil {
+		return false, false, err
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `
+		INSERT INTO frequency_charges (key, notification_id, window_start, expires_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (key, notification_id) DO NOTHING
+	`, key, notificationId, windowStart, chargedUntil)
+	if err != nil {
+		return false, false, err
+	}
+	if tag.RowsAffected() == 0 {
+		return true, false, nil
+	}
+
+	tag, err = tx.Exec(ctx, `
+		INSERT INTO frequency_counters (key, window_start, count, expires_at)
+		VALUES ($1, $2, 1, $3)
+		ON CONFLICT (key, window_start) DO UPDATE
+		SET count = frequency_counters.count + 1
+		WHERE frequency_counters.count < $4
+	`, key, windowStart, expiresAt, limit)
+	if err != nil {
+		return false, false, err
+	}
+	if tag.RowsAffected() == 0 {
+		return false, false, nil
+	}
+
+	return true, true, tx.Commit(ctx)
+}
+
+// Decrement gives back the count the notification took from the counter.
+func (r *PostgresCounterRepository) Decrement(ctx context.Context, key, notificationId string) error {
+
+	tx, err := r.db.Write.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var windowStart time.Time
+	err = tx.QueryRow(ctx, `
+		DELETE FROM frequency_charges
+		WHERE key = $1
+		  AND notification_id = $2
+		RETURNING window_start
+	`, key, notificationId).Scan(&windowStart)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE frequency_counters
+		SET count = count - 1
+		WHERE key = $1
+		  AND window_start = $2
+		  AND count > 0
+	`, key, windowStart)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *PostgresCounterRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	tag, err := r.db.Write.Exec(ctx, `
+		DELETE FROM frequency_counters
+		WHERE expires_at <= $1
+	`, now)
+	if err != nil {
+		return 0, err
+	}
+
+	charges, err := r.db.Write.Exec(ctx, `
+		DELETE FROM frequency_charges
+		WHERE expires_at <= $1
+	`, now)
+	if err != nil {
+		return 0, err
+	}
+
+	return tag.RowsAffected() + charges.RowsAffected(), nil
+}
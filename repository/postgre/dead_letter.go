@@ -0,0 +1,169 @@
+package postgre
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/HuseyinAsik/Notifications/models"
+	"github.com/HuseyinAsik/Notifications/pkg/gpostgresql"
+)
+
+type PostgresDeadLetterRepository struct {
+	db *gpostgresql.Pool
+}
+
+func NewPostgresDeadLetterRepository(db *gpostgresql.Pool) *PostgresDeadLetterRepository {
+	return &PostgresDeadLetterRepository{db: db}
+}
+
+func (r *PostgresDeadLetterRepository) Create(ctx context.Context, deadLetter models.DeadLetter) error {
+	_, err := r.db.Write.Exec(ctx, `
+		INSERT INTO dead_letters (
+			id,
+			aggregate_id,
+			tenant_id,
+			channel,
+			reason,
+			attempts,
+			last_error,
+			original_topic,
+			original_partition,
+			original_offset,
+			payload,
+			created_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''), $8, $9, $10, $11, NOW())
+	`,
+		deadLetter.Id,
+		nullableUUID(deadLetter.AggregateId),
+		nullableUUID(deadLetter.TenantId),
+		deadLetter.Channel,
+		deadLetter.Reason,
+		deadLetter.Attempts,
+		deadLetter.LastError,
+		deadLetter.OriginalTopic,
+		deadLetter.OriginalPartition,
+		deadLetter.OriginalOffset,
+		deadLetter.Payload,
+	)
+
+	return err
+}
+
+// List returns the dead letters of tenantId, or of every tenant when it is empty. Entries
+// recorded without a tenant, such as undecodable payloads, are only listed across tenants.
+func (r *PostgresDeadLetterRepository) List(ctx context.Context, tenantId, channel string, limit, offset int) ([]models.DeadLetter, int, error) {
+	deadLetters := []models.DeadLetter{}
+	args := []interface{}{}
+	where := "WHERE TRUE"
+
+	if tenantId != "" {
+		args = append(args, tenantId)
+		where += " AND tenant_id = $" + strconv.Itoa(len(args))
+	}
+	if channel != "" {
+		args = append(args, channel)
+		where += " AND channel = $" + strconv.Itoa(len(args))
+	}
+
+	var total int
+	err := r.db.Read.QueryRow(ctx, "SELECT COUNT(*) FROM dead_letters "+where, args...).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	args = append(args, limit, offset)
+	query := `SELECT id, COALESCE(aggregate_id::text, ''), COALESCE(tenant_id::text, ''), channel, reason, attempts, COALESCE(last_error, ''),
+		original_topic, original_partition, original_offset, payload, created_at, replayed_at
+		FROM dead_letters ` + where + " ORDER BY created_at DESC LIMIT $" + strconv.Itoa(len(args)-1) + " OFFSET $" + strconv.Itoa(len(args))
+
+	rows, err := r.db.Read.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var d models.DeadLetter
+		err := rows.Scan(
+			&d.Id, &d.AggregateId, &d.TenantId, &d.Channel, &d.Reason, &d.Attempts, &d.LastError,
+			&d.OriginalTopic, &d.OriginalPartition, &d.OriginalOffset, &d.Payload, &d.CreatedAt, &d.ReplayedAt,
+		)
+		if err != nil {
+			return nil, 0, err
+		}
+		deadLetters = append(deadLetters, d)
+	}
+
+	return deadLetters, total, rows.Err()
+}
+
+// Replay resets the outbox rows of the given dead letters to pending so the outbox
+// publisher sends them again. Entries without an aggregate or already replayed are skipped.
+func (r *PostgresDeadLetterRepository) Replay(ctx context.Context, ids []string) ([]string, error) {
+	tx, err := r.db.Write.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, `
+		UPDATE dead_letters
+		SET replayed_at = NOW()
+		WHERE id = ANY($1)
+		  AND replayed_at IS NULL
+		  AND aggregate_id IS NOT NULL
+		RETURNING id, aggregate_id
+	`, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	var replayed, aggregateIds []string
+	for rows.Next() {
+		var id, aggregateId string
+		if err := rows.Scan(&id, &aggregateId); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		replayed = append(replayed, id)
+		aggregateIds = append(aggregateIds, aggregateId)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(aggregateIds) == 0 {
+		return replayed, nil
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE outbox
+		SET status = 'pending',
+		    retry_count = 0,
+		    next_attempt_at = NULL
+		WHERE aggregate_id = ANY($1)
+	`, aggregateIds)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE notifications
+		SET status = 'pending'
+		WHERE id = ANY($1)
+	`, aggregateIds)
+	if err != nil {
+		return nil, err
+	}
+
+	return replayed, tx.Commit(ctx)
+}
+
+func nullableUUID(id string) interface{} {
+	if id == "" {
+		return nil
+	}
+	return id
+}
@@ -0,0 +1,56 @@
+package postgre
+
+import (
+	"context"
+	"time"
+
+	"github.com/HuseyinAsik/Notifications/pkg/gpostgresql"
+)
+
+type PostgresDeviceTokenRepository struct {
+	db *gpostgresql.Pool
+}
+
+func NewPostgresDeviceTokenRepository(db *gpostgresql.Pool) *PostgresDeviceTokenRepository {
+	return &PostgresDeviceTokenRepository{db: db}
+}
+
+// Invalidate marks the tenant's device token dead. Marking it again restarts its TTL.
+func (r *PostgresDeviceTokenRepository) Invalidate(ctx context.Context, tenantId, token, reason string) error {
+	_, err := r.db.Write.Exec(ctx, `
+		INSERT INTO invalid_device_tokens (tenant_id, token, reason, created_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (tenant_id, token) DO UPDATE
+		SET reason = EXCLUDED.reason,
+		    created_at = NOW()
+	`, tenantId, token, reason)
+
+	return err
+}
+
+// Register clears the dead mark of a token the app registered again.
+func (r *PostgresDeviceTokenRepository) Register(ctx context.Context, tenantId, token string) error {
+	_, err := r.db.Write.Exec(ctx, `
+		DELETE FROM invalid_device_tokens
+		WHERE tenant_id = $1
+		  AND token = $2
+	`, tenantId, token)
+
+	return err
+}
+
+// IsInvalid reports whether the token was marked dead after since; older marks have expired.
+func (r *PostgresDeviceTokenRepository) IsInvalid(ctx context.Context, tenantId, token string, since time.Time) (bool, error) {
+	var invalid bool
+	err := r.db.Read.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1
+			FROM invalid_device_tokens
+			WHERE tenant_id = $1
+			  AND token = $2
+			  AND created_at > $3
+		)
+	`, tenantId, token, since).Scan(&invalid)
+
+	return invalid, err
+}
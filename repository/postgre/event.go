@@ -0,0 +1,94 @@
+package postgre
+
+import (
+	"context"
+
+	"github.com/HuseyinAsik/Notifications/models"
+	"github.com/HuseyinAsik/Notifications/pkg/gpostgresql"
+	"github.com/jackc/pgx/v5"
+)
+
+type PostgresNotificationEventRepository struct {
+	db *gpostgresql.Pool
+}
+
+func NewPostgresNotificationEventRepository(db *gpostgresql.Pool) *PostgresNotificationEventRepository {
+	return &PostgresNotificationEventRepository{db: db}
+}
+
+func (r *PostgresNotificationEventRepository) Append(ctx context.Context, events []models.NotificationEvent) error {
+
+	_, err := r.db.Write.CopyFrom(
+		ctx,
+		pgx.Identifier{"notification_events"},
+		[]string{
+			"id", "tenant_id", "notification_id", "status", "source", "hostname", "attempt",
+			"provider_status", "provider_code", "provider_message_id", "reason", "error",
+			"next_attempt_at", "created_at",
+		},
+		pgx.CopyFromSlice(len(events), func(i int) ([]interface{}, error) {
+			e := events[i]
+			return []interface{}{
+				e.Id,
+				e.TenantId,
+				e.NotificationId,
+				e.Status,
+				e.Source,
+				e.Hostname,
+				nullableInt(e.Attempt),
+				nullableString(e.ProviderStatus),
+				nullableString(e.ProviderCode),
+				nullableString(e.ProviderMessageId),
+				nullableString(e.Reason),
+				nullableString(e.Error),
+				e.NextAttemptAt,
+				e.CreatedAt,
+			}, nil
+		}),
+	)
+
+	return err
+}
+
+func (r *PostgresNotificationEventRepository) List(ctx context.Context, tenantId, notificationId string) ([]models.NotificationEvent, error) {
+	events := []models.NotificationEvent{}
+
+	rows, err := r.db.Read.Query(ctx, `
+		SELECT id, tenant_id, notification_id, status, source, hostname, COALESCE(attempt, 0),
+		       COALESCE(provider_status, ''), COALESCE(provider_code, ''), COALESCE(provider_message_id, ''),
+		       COALESCE(reason, ''), COALESCE(error, ''), next_attempt_at, created_at
+		FROM notification_events
+		WHERE tenant_id = $1
+		  AND notification_id = $2
+		ORDER BY created_at, id
+	`, tenantId, notificationId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var e models.NotificationEvent
+		if err := rows.Scan(
+			&e.Id,
+			&e.TenantId,
+			&e.NotificationId,
+			&e.Status,
+			&e.Source,
+			&e.Hostname,
+			&e.Attempt,
+			&e.ProviderStatus,
+			&e.ProviderCode,
+			&e.ProviderMessageId,
+			&e.Reason,
+			&e.Error,
+			&e.NextAttemptAt,
+			&e.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+
+	return events, rows.Err()
+}
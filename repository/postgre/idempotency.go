@@ -0,0 +1,108 @@
+package postgre
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/HuseyinAsik/Notifications/models"
+	"github.com/HuseyinAsik/Notifications/pkg/gpostgresql"
+	"github.com/HuseyinAsik/Notifications/repository"
+	"github.com/jackc/pgx/v5"
+)
+
+type PostgresIdempotencyRepository struct {
+	db *gpostgresql.Pool
+}
+
+func NewPostgresIdempotencyRepository(db *gpostgresql.Pool) *PostgresIdempotencyRepository {
+	return &PostgresIdempotencyRepository{db: db}
+}
+
+// Reserve claims the key for a new request until lockedUntil. It reports false when the key
+// is already held; an expired key, or an in-progress one whose lock ran out, is taken over.
+func (r *PostgresIdempotencyRepository) Reserve(ctx context.Context, tenantId, key, endpoint, requestHash string, lockedUntil, expiresAt time.Time) (bool, error) {
+	tag, err := r.db.Write.Exec(ctx, `
+		INSERT INTO idempotency_keys (tenant_id, key, endpoint, request_hash, created_at, locked_until, expires_at)
+		VALUES ($1, $2, $3, $4, NOW(), $5, $6)
+		ON CONFLICT (tenant_id, endpoint, key) DO UPDATE
+		SET request_hash = EXCLUDED.request_hash,
+		    status_code = NULL,
+		    response = NULL,
+		    created_at = NOW(),
+		    locked_until = EXCLUDED.locked_until,
+		    expires_at = EXCLUDED.expires_at
+		WHERE idempotency_keys.expires_at <= NOW()
+		   OR (idempotency_keys.status_code IS NULL AND idempotency_keys.locked_until <= NOW())
+	`, tenantId, key, endpoint, requestHash, lockedUntil, expiresAt)
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r *PostgresIdempotencyRepository) Find(ctx context.Context, tenantId, key, endpoint string) (*models.IdempotencyKey, error) {
+	var k models.IdempotencyKey
+	err := r.db.Write.QueryRow(ctx, `
+		SELECT key, endpoint, request_hash, COALESCE(status_code, 0), response, created_at, expires_at
+		FROM idempotency_keys
+		WHERE tenant_id = $1
+		  AND endpoint = $2
+		  AND key = $3
+	`, tenantId, endpoint, key).Scan(
+		&k.Key,
+		&k.Endpoint,
+		&k.RequestHash,
+		&k.StatusCode,
+		&k.Response,
+		&k.CreatedAt,
+		&k.ExpiresAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, repository.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &k, nil
+}
+
+func (r *PostgresIdempotencyRepository) Complete(ctx context.Context, tenantId, key, endpoint string, statusCode int, response []byte) error {
+	_, err := r.db.Write.Exec(ctx, `
+		UPDATE idempotency_keys
+		SET status_code = $1,
+		    response = $2,
+		    locked_until = NULL
+		WHERE tenant_id = $3
+		  AND endpoint = $4
+		  AND key = $5
+	`, statusCode, response, tenantId, endpoint, key)
+
+	return err
+}
+
+func (r *PostgresIdempotencyRepository) Release(ctx context.Context, tenantId, key, endpoint string) error {
+	_, err := r.db.Write.Exec(ctx, `
+		DELETE FROM idempotency_keys
+		WHERE tenant_id = $1
+		  AND endpoint = $2
+		  AND key = $3
+		  AND status_code IS NULL
+	`, tenantId, endpoint, key)
+
+	return err
+}
+
+func (r *PostgresIdempotencyRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	tag, err := r.db.Write.Exec(ctx, `
+		DELETE FROM idempotency_keys
+		WHERE expires_at <= $1
+	`, now)
+	if err != nil {
+		return 0, err
+	}
+
+	return tag.RowsAffected(), nil
+}
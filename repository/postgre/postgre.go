@@ -2,11 +2,14 @@ package postgre
 
 import (
 	"context"
+	"errors"
+	"slices"
 	"strconv"
 	"time"
 
 	"github.com/HuseyinAsik/Notifications/models"
 	"github.com/HuseyinAsik/Notifications/pkg/gpostgresql"
+	"github.com/HuseyinAsik/Notifications/repository"
 	"github.com/jackc/pgx/v5"
 )
 
@@ -29,6 +32,7 @@ func (r *PostgresNotificationRepository) Create(ctx context.Context, notificatio
 	_, err = tx.Exec(ctx, `
 		INSERT INTO notifications (
 			id,
+			tenant_id,
 			group_id,
 			recipient,
 			channel,
@@ -36,11 +40,24 @@ func (r *PostgresNotificationRepository) Create(ctx context.Context, notificatio
 			status,
 			priority,
 			scheduled_at,
+			timezone,
+			template_id,
+			template_version,
+			locale,
+			category,
+			suppression_reason,
+			quiet_hours_start,
+			quiet_hours_end,
+			callback_url,
+			request_id,
+			ordering_key,
 			created_at
 		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW())
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NULLIF($10, ''), $11, NULLIF($12, 0), NULLIF($13, ''), NULLIF($14, ''), NULLIF($15, ''),
+		        NULLIF($16, ''), NULLIF($17, ''), NULLIF($18, ''), NULLIF($19, ''), NULLIF($20, ''), NOW())
 	`,
 		notification.Id,
+		notification.TenantId,
 		notification.GroupId,
 		notification.Recipient,
 		notification.Channel,
@@ -48,29 +65,57 @@ func (r *PostgresNotificationRepository) Create(ctx context.Context, notificatio
 		notification.Status,
 		notification.Priority,
 		notification.ScheduledAt,
+		notification.Timezone,
+		nullableUUID(notification.TemplateId),
+		notification.TemplateVersion,
+		notification.Locale,
+		notification.Category,
+		notification.SuppressionReason,
+		notification.QuietHoursStart,
+		notification.QuietHoursEnd,
+		notification.CallbackUrl,
+		notification.RequestId,
+		notification.OrderingKey,
 	)
+	if err != nil {
+		return err
+	}
+
+	if notification.Status == "suppressed" {
+		if err := enqueueWebhooks(ctx, tx, []string{notification.Id}, ""); err != nil {
+			return err
+		}
+	}
 
 	if event != nil {
 		_, err = tx.Exec(ctx, `
 		INSERT INTO outbox (
 			id,
 			aggregate_id,
+			tenant_id,
 			group_id,
 			event_type,
 			topic,
 			payload,
+			trace_context,
+			request_id,
+			message_key,
 			status,
 			retry_count,
 			created_at
 		)
-		VALUES ($1, $2, $3, $4, $5, $6, 'pending', 0, NOW())
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NULLIF($9, ''), NULLIF($10, ''), 'pending', 0, NOW())
 	`,
 			event.Id,
 			event.AggregateId,
+			event.TenantId,
 			event.GroupId,
 			event.EventType,
 			event.Topic,
 			event.Payload,
+			event.TraceContext,
+			event.RequestId,
+			event.MessageKey,
 		)
 		if err != nil {
 			return err
@@ -92,6 +137,18 @@ func (r *PostgresNotificationRepository) BulkInsertWithOutbox(ctx context.Contex
 		return err
 	}
 
+	var suppressed []string
+	for _, n := range notifications {
+		if n.Status == "suppressed" {
+			suppressed = append(suppressed, n.Id)
+		}
+	}
+	if len(suppressed) > 0 {
+		if err := enqueueWebhooks(ctx, tx, suppressed, ""); err != nil {
+			return err
+		}
+	}
+
 	if len(events) > 0 {
 		if err := copyOutbox(ctx, tx, events); err != nil {
 			return err
@@ -101,16 +158,34 @@ func (r *PostgresNotificationRepository) BulkInsertWithOutbox(ctx context.Contex
 	return tx.Commit(ctx)
 }
 
-func (r *PostgresNotificationRepository) FetchPendingOutbox(ctx context.Context, limit int) ([]models.OutboxEvent, error) {
-
-	rows, err := r.db.Read.Query(ctx, `
-		SELECT id, aggregate_id, event_type,
-		       topic, payload, retry_count, created_at
-		FROM outbox
-		WHERE status = 'pending'
+// ClaimPendingOutbox marks up to limit due pending events published in one statement and
+// returns them oldest first. Rows locked by another publisher are skipped, so concurrent
+// publishers never pick up the same event.
+func (r *PostgresNotificationRepository) ClaimPendingOutbox(ctx context.Context, limit int) ([]models.OutboxEvent, error) {
+
+	rows, err := r.db.Write.Query(ctx, `
+		WITH claimed AS (
+			UPDATE outbox
+			SET status = 'published',
+			    published_at = NOW()
+			WHERE id IN (
+				SELECT id
+				FROM outbox
+				WHERE status = 'pending'
+				  AND (next_attempt_at IS NULL OR next_attempt_at <= NOW())
+				ORDER BY created_at
+				LIMIT $1
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING id, aggregate_id, tenant_id, event_type,
+			          topic, payload, trace_context, request_id, message_key,
+			          retry_count, created_at
+		)
+		SELECT id, aggregate_id, tenant_id, event_type,
+		       topic, payload, trace_context, COALESCE(request_id, ''), COALESCE(message_key, ''),
+		       retry_count, created_at
+		FROM claimed
 		ORDER BY created_at
-		LIMIT $1
-		FOR UPDATE SKIP LOCKED
 	`, limit)
 	if err != nil {
 		return nil, err
@@ -124,9 +199,13 @@ func (r *PostgresNotificationRepository) FetchPendingOutbox(ctx context.Context,
 		err := rows.Scan(
 			&e.Id,
 			&e.AggregateId,
+			&e.TenantId,
 			&e.EventType,
 			&e.Topic,
 			&e.Payload,
+			&e.TraceContext,
+			&e.RequestId,
+			&e.MessageKey,
 			&e.RetryCount,
 			&e.CreatedAt,
 		)
@@ -136,19 +215,16 @@ func (r *PostgresNotificationRepository) FetchPendingOutbox(ctx context.Context,
 		events = append(events, e)
 	}
 
-	return events, nil
+	return events, rows.Err()
 }
 
-func (r *PostgresNotificationRepository) MarkOutboxPublished(ctx context.Context, ids []string) error {
+// CountPendingOutbox returns the number of outbox events not yet published, including the
+// ones waiting for their next attempt.
+func (r *PostgresNotificationRepository) CountPendingOutbox(ctx context.Context) (int, error) {
+	var count int
+	err := r.db.Read.QueryRow(ctx, `SELECT COUNT(*) FROM outbox WHERE status = 'pending'`).Scan(&count)
 
-	_, err := r.db.Write.Exec(ctx, `
-		UPDATE outbox
-		SET status = 'published',
-		    published_at = now()
-		WHERE id = ANY($1)
-	`, ids)
-
-	return err
+	return count, err
 }
 
 func (r *PostgresNotificationRepository) MarkOutboxPending(ctx context.Context, ids []string) error {
@@ -164,7 +240,7 @@ func (r *PostgresNotificationRepository) MarkOutboxPending(ctx context.Context,
 
 func (r *PostgresNotificationRepository) FetchOutboxEventByAggregateId(ctx context.Context, Id string) (*models.OutboxEvent, error) {
 	query := `
-	SELECT id, aggregate_id, status, retry_count
+	SELECT id, aggregate_id, tenant_id, status, retry_count, next_attempt_at, created_at, published_at
 	FROM outbox
 	WHERE aggregate_id = $1
 `
@@ -175,42 +251,258 @@ func (r *PostgresNotificationRepository) FetchOutboxEventByAggregateId(ctx conte
 	if err := row.Scan(
 		&n.Id,
 		&n.AggregateId,
+		&n.TenantId,
 		&n.Status,
 		&n.RetryCount,
-	); err != nil {
+		&n.NextAttemptAt,
+		&n.CreatedAt,
+		&n.PublishedAt,
+	); errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	} else if err != nil {
 		return nil, err
 	}
 
 	return &n, nil
 }
 
-func (r *PostgresNotificationRepository) UpdateOutboxEvent(ctx context.Context, Id, status string, retryCount int) error {
+// ClaimOutboxEvent moves a published outbox row to processing. It reports false when the
+// row was cancelled, rescheduled or claimed by another consumer in the meantime.
+func (r *PostgresNotificationRepository) ClaimOutboxEvent(ctx context.Context, Id string) (bool, error) {
+	tag, err := r.db.Write.Exec(ctx, `
+    UPDATE outbox
+    SET status = 'processing',
+        claimed_at = NOW()
+    WHERE aggregate_id = $1
+      AND status = 'published'
+`, Id)
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
+
+// ReleaseOutboxEvent hands a claimed row back to published when the worker could not finish
+// it, so the redelivered message is processed again. Rows that already left processing are
+// left alone.
+func (r *PostgresNotificationRepository) ReleaseOutboxEvent(ctx context.Context, Id string) error {
+	_, err := r.db.Write.Exec(ctx, `
+    UPDATE outbox
+    SET status = 'published',
+        claimed_at = NULL
+    WHERE aggregate_id = $1
+      AND status = 'processing'
+`, Id)
+
+	return err
+}
+
+// RequeueStaleOutbox moves rows claimed longer than lease ago, e.g. by a worker that crashed,
+// back to pending so the publisher publishes them again, and their notifications with them.
+func (r *PostgresNotificationRepository) RequeueStaleOutbox(ctx context.Context, lease time.Duration, limit int) (int, error) {
+	tx, err := r.db.Write.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, `
+		UPDATE outbox
+		SET status = 'pending',
+		    claimed_at = NULL,
+		    next_attempt_at = NULL
+		WHERE id IN (
+			SELECT id
+			FROM outbox
+			WHERE status = 'processing'
+			  AND claimed_at < NOW() - make_interval(secs => $1)
+			ORDER BY claimed_at
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING aggregate_id
+	`, lease.Seconds(), limit)
+	if err != nil {
+		return 0, err
+	}
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE notifications
+		SET status = 'pending'
+		WHERE id = ANY($1)
+		  AND status = 'processing'
+	`, ids)
+	if err != nil {
+		return 0, err
+	}
+
+	return len(ids), tx.Commit(ctx)
+}
+
+func (r *PostgresNotificationRepository) UpdateOutboxEvent(ctx context.Context, Id, status string, retryCount int, nextAttemptAt *time.Time) error {
 	_, err := r.db.Write.Exec(ctx, `
     UPDATE outbox
     SET
         status = $1,
-        retry_count = $2
-    WHERE aggregate_id = $3
-`, status, retryCount, Id)
+        retry_count = $2,
+        next_attempt_at = $3
+    WHERE aggregate_id = $4
+`, status, retryCount, nextAttemptAt, Id)
 
 	return err
 }
 
 func (r *PostgresNotificationRepository) UpdateNotificationStatus(ctx context.Context, Id, status string) error {
-	_, err := r.db.Write.Exec(ctx, `
+	return r.updateWithWebhook(ctx, Id, `
     UPDATE notifications
     SET
         status = $1
     WHERE id = $2
 `, status, Id)
+}
+
+func (r *PostgresNotificationRepository) UpdateNotificationDelivery(ctx context.Context, Id, status, providerMessageId string) error {
+	return r.updateWithWebhook(ctx, Id, `
+    UPDATE notifications
+    SET
+        status = $1,
+        provider_message_id = NULLIF($2, '')
+    WHERE id = $3
+`, status, providerMessageId, Id)
+}
+
+// DeferNotification puts a claimed notification back to scheduled until the given time. Its
+// outbox row is parked as scheduled, keeping its retry count, and the scheduler puts it back
+// in line when the time comes.
+func (r *PostgresNotificationRepository) DeferNotification(ctx context.Context, Id string, until time.Time) error {
+
+	tx, err := r.db.Write.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+		UPDATE outbox
+		SET status = 'scheduled',
+		    next_attempt_at = $1,
+		    claimed_at = NULL
+		WHERE aggregate_id = $2
+		  AND status = 'processing'
+	`, until, Id)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE notifications
+		SET status = 'scheduled',
+		    scheduled_at = $1
+		WHERE id = $2
+	`, until, Id)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *PostgresNotificationRepository) SuppressNotification(ctx context.Context, Id, reason string) error {
+	return r.updateWithWebhook(ctx, Id, `
+    UPDATE notifications
+    SET
+        status = 'suppressed',
+        suppression_reason = $1
+    WHERE id = $2
+`, reason, Id)
+}
+
+// updateWithWebhook runs a status update of notification Id and, when the status changed,
+// queues its delivery status webhook in the same transaction.
+func (r *PostgresNotificationRepository) updateWithWebhook(ctx context.Context, Id, query string, args ...any) error {
+
+	tx, err := r.db.Write.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var previous string
+	err = tx.QueryRow(ctx, "SELECT status FROM notifications WHERE id = $1 FOR UPDATE", Id).Scan(&previous)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, query, args...); err != nil {
+		return err
+	}
+
+	if err := enqueueWebhooks(ctx, tx, []string{Id}, previous); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// enqueueWebhooks queues a delivery status webhook for each of the notifications whose
+// current status is reported to clients. Only notifications with a callback URL of their
+// own or of their tenant, and whose tenant has a signing secret, get one. Notifications still
+// in the previous status, when given, are skipped.
+func enqueueWebhooks(ctx context.Context, tx pgx.Tx, ids []string, previous string) error {
+	_, err := tx.Exec(ctx, `
+		INSERT INTO webhook_deliveries (
+			tenant_id, notification_id, event, url, payload, status, attempts, next_attempt_at, created_at
+		)
+		SELECT n.tenant_id, n.id, n.status, COALESCE(n.callback_url, t.webhook_url),
+		       jsonb_build_object(
+		           'notificationId', n.id,
+		           'groupId', n.group_id,
+		           'status', n.status,
+		           'channel', n.channel,
+		           'recipient', n.recipient,
+		           'providerMessageId', n.provider_message_id,
+		           'suppressionReason', n.suppression_reason,
+		           'occurredAt', NOW()
+		       ),
+		       'pending', 0, NOW(), NOW()
+		FROM notifications n
+		JOIN tenants t ON t.id = n.tenant_id
+		WHERE n.id = ANY($1)
+		  AND n.status IN ('processing', 'sended', 'failed', 'suppressed')
+		  AND t.webhook_secret IS NOT NULL
+		  AND COALESCE(n.callback_url, t.webhook_url) IS NOT NULL
+		  AND n.status IS DISTINCT FROM NULLIF($2, '')
+	`, ids, previous)
 
 	return err
 }
 
-func (r *PostgresNotificationRepository) ListNotifications(ctx context.Context, status, channel string, startDate, endDate *time.Time, limit, offset int) ([]models.Notification, int, error) {
+func (r *PostgresNotificationRepository) ListNotifications(ctx context.Context, tenantId, status, channel string, startDate, endDate *time.Time, limit, offset int) ([]models.Notification, int, error) {
 	notifications := []models.Notification{}
-	args := []interface{}{}
-	where := "WHERE 1=1"
+	args := []interface{}{tenantId}
+	where := "WHERE tenant_id = $1"
 
 	if status != "" {
 		args = append(args, status)
@@ -239,7 +531,7 @@ func (r *PostgresNotificationRepository) ListNotifications(ctx context.Context,
 
 	// Pagination
 	args = append(args, limit, offset)
-	query := "SELECT id, recipient, channel, priority, content, status, scheduled_at, created_at FROM notifications " +
+	query := "SELECT id, tenant_id, recipient, channel, priority, content, status, COALESCE(provider_message_id, ''), scheduled_at, created_at FROM notifications " +
 		where + " ORDER BY created_at DESC LIMIT $" + strconv.Itoa(len(args)-1) + " OFFSET $" + strconv.Itoa(len(args))
 
 	rows, err := r.db.Read.Query(ctx, query, args...)
@@ -251,8 +543,8 @@ func (r *PostgresNotificationRepository) ListNotifications(ctx context.Context,
 	for rows.Next() {
 		var n models.Notification
 		err := rows.Scan(
-			&n.Id, &n.Recipient, &n.Channel, &n.Priority,
-			&n.Content, &n.Status, &n.ScheduledAt, &n.CreatedAt,
+			&n.Id, &n.TenantId, &n.Recipient, &n.Channel, &n.Priority,
+			&n.Content, &n.Status, &n.ProviderMessageId, &n.ScheduledAt, &n.CreatedAt,
 		)
 		if err != nil {
 			return nil, 0, err
@@ -263,26 +555,275 @@ func (r *PostgresNotificationRepository) ListNotifications(ctx context.Context,
 	return notifications, total, nil
 }
 
+// CancelNotification cancels a pending or scheduled notification. Its outbox row is marked
+// cancelled so a message already published to Kafka is skipped by the worker.
+func (r *PostgresNotificationRepository) CancelNotification(ctx context.Context, tenantId, id string) error {
+
+	tx, err := r.db.Write.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	status, err := lockModifiable(ctx, tx, tenantId, id)
+	if err != nil {
+		return err
+	}
+
+	tag, err := tx.Exec(ctx, `
+		UPDATE outbox
+		SET status = 'cancelled'
+		WHERE aggregate_id = $1
+		  AND status IN ('pending', 'scheduled', 'published')
+	`, id)
+	if err != nil {
+		return err
+	}
+	if status == "pending" && tag.RowsAffected() == 0 {
+		return repository.ErrNotModifiable
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE notifications
+		SET status = 'cancelled'
+		WHERE id = $1
+	`, id)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// RescheduleNotification moves a pending or scheduled notification to a new time. The
+// outbox row is removed and the scheduler creates a fresh one when the time comes. A row the
+// publisher already handed to Kafka may be claimed by a worker at any moment, so a
+// notification whose row is published or processing cannot be rescheduled.
+func (r *PostgresNotificationRepository) RescheduleNotification(ctx context.Context, tenantId, id string, scheduledAt time.Time, timezone string) error {
+
+	tx, err := r.db.Write.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	status, err := lockModifiable(ctx, tx, tenantId, id)
+	if err != nil {
+		return err
+	}
+
+	tag, err := tx.Exec(ctx, `
+		DELETE FROM outbox
+		WHERE aggregate_id = $1
+		  AND status IN ('pending', 'scheduled')
+	`, id)
+	if err != nil {
+		return err
+	}
+
+	var inFlight bool
+	err = tx.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1
+			FROM outbox
+			WHERE aggregate_id = $1
+			  AND status IN ('published', 'processing')
+		)
+	`, id).Scan(&inFlight)
+	if err != nil {
+		return err
+	}
+	if inFlight || (status == "pending" && tag.RowsAffected() == 0) {
+		return repository.ErrNotModifiable
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE notifications
+		SET status = 'scheduled',
+		    scheduled_at = $1,
+		    timezone = NULLIF($2, '')
+		WHERE id = $3
+	`, scheduledAt, timezone, id)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// lockModifiable locks the tenant's notification row and returns its status when it is
+// still pending or scheduled.
+func lockModifiable(ctx context.Context, tx pgx.Tx, tenantId, id string) (string, error) {
+	var status string
+	err := tx.QueryRow(ctx, `
+		SELECT status
+		FROM notifications
+		WHERE id = $1
+		  AND tenant_id = $2
+		FOR UPDATE
+	`, id, tenantId).Scan(&status)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", repository.ErrNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	if status != "pending" && status != "scheduled" {
+		return "", repository.ErrNotModifiable
+	}
+
+	return status, nil
+}
+
+// DispatchDueScheduled moves scheduled notifications whose time has come to pending and
+// inserts their outbox events in the same transaction; notifications deferred by a worker
+// get their parked outbox row back instead. Rows locked by another scheduler instance are
+// skipped.
+func (r *PostgresNotificationRepository) DispatchDueScheduled(ctx context.Context, now time.Time, limit int, buildEvent func(models.Notification) *models.OutboxEvent) (int, error) {
+
+	tx, err := r.db.Write.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, `
+		SELECT id, tenant_id, group_id, recipient, channel, content, priority, COALESCE(category, ''),
+		       scheduled_at, COALESCE(timezone, ''), COALESCE(quiet_hours_start, ''),
+		       COALESCE(quiet_hours_end, ''), COALESCE(callback_url, ''), COALESCE(request_id, ''),
+		       COALESCE(ordering_key, ''), created_at
+		FROM notifications
+		WHERE status = 'scheduled'
+		  AND scheduled_at <= $1
+		ORDER BY scheduled_at
+		LIMIT $2
+		FOR UPDATE SKIP LOCKED
+	`, now, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	var due []models.Notification
+	var dueIds []string
+	for rows.Next() {
+		var n models.Notification
+		if err := rows.Scan(
+			&n.Id, &n.TenantId, &n.GroupId, &n.Recipient, &n.Channel, &n.Content, &n.Priority, &n.Category,
+			&n.ScheduledAt, &n.Timezone, &n.QuietHoursStart, &n.QuietHoursEnd, &n.CallbackUrl, &n.RequestId,
+			&n.OrderingKey, &n.CreatedAt,
+		); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		n.Status = "pending"
+		due = append(due, n)
+		dueIds = append(dueIds, n.Id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	if len(due) == 0 {
+		return 0, nil
+	}
+
+	// A deferred notification still has its outbox row, retry count included; it only has to
+	// be put back in line.
+	rows, err = tx.Query(ctx, `
+		UPDATE outbox
+		SET status = 'pending',
+		    next_attempt_at = NULL
+		WHERE aggregate_id = ANY($1)
+		  AND status = 'scheduled'
+		RETURNING aggregate_id
+	`, dueIds)
+	if err != nil {
+		return 0, err
+	}
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	var events []*models.OutboxEvent
+	for _, n := range due {
+		if slices.Contains(ids, n.Id) {
+			continue
+		}
+		if event := buildEvent(n); event != nil {
+			ids = append(ids, n.Id)
+			events = append(events, event)
+		}
+	}
+
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	if len(events) > 0 {
+		if err := copyOutbox(ctx, tx, events); err != nil {
+			return 0, err
+		}
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE notifications
+		SET status = 'pending'
+		WHERE id = ANY($1)
+	`, ids)
+	if err != nil {
+		return 0, err
+	}
+
+	return len(ids), tx.Commit(ctx)
+}
+
 func copyNotifications(ctx context.Context, tx pgx.Tx, list []models.Notification) error {
 
 	_, err := tx.CopyFrom(
 		ctx,
 		pgx.Identifier{"notifications"},
 		[]string{
-			"id", "group_id", "channel", "recipient",
+			"id", "tenant_id", "group_id", "channel", "recipient",
 			"content", "priority",
-			"scheduled_at", "status", "created_at",
+			"scheduled_at", "timezone", "template_id", "template_version",
+			"locale", "category", "suppression_reason", "quiet_hours_start",
+			"quiet_hours_end", "callback_url", "request_id", "ordering_key", "status",
+			"created_at",
 		},
 		pgx.CopyFromSlice(len(list), func(i int) ([]interface{}, error) {
 			n := list[i]
 			return []interface{}{
 				n.Id,
+				n.TenantId,
 				n.GroupId,
 				n.Channel,
 				n.Recipient,
 				n.Content,
 				n.Priority,
 				n.ScheduledAt,
+				nullableString(n.Timezone),
+				nullableUUID(n.TemplateId),
+				nullableInt(n.TemplateVersion),
+				nullableString(n.Locale),
+				nullableString(n.Category),
+				nullableString(n.SuppressionReason),
+				nullableString(n.QuietHoursStart),
+				nullableString(n.QuietHoursEnd),
+				nullableString(n.CallbackUrl),
+				nullableString(n.RequestId),
+				nullableString(n.OrderingKey),
 				n.Status,
 				n.CreatedAt,
 			}, nil
@@ -298,9 +839,10 @@ func copyOutbox(ctx context.Context, tx pgx.Tx, list []*models.OutboxEvent) erro
 		ctx,
 		pgx.Identifier{"outbox"},
 		[]string{
-			"id", "aggregate_id",
+			"id", "aggregate_id", "tenant_id",
 			"group_id", "event_type", "topic",
-			"payload", "status", "retry_count",
+			"payload", "trace_context", "request_id",
+			"message_key", "status", "retry_count",
 			"created_at",
 		},
 		pgx.CopyFromSlice(len(list), func(i int) ([]interface{}, error) {
@@ -308,10 +850,14 @@ func copyOutbox(ctx context.Context, tx pgx.Tx, list []*models.OutboxEvent) erro
 			return []interface{}{
 				e.Id,
 				e.AggregateId,
+				e.TenantId,
 				e.GroupId,
 				e.EventType,
 				e.Topic,
 				e.Payload,
+				e.TraceContext,
+				nullableString(e.RequestId),
+				nullableString(e.MessageKey),
 				"pending",
 				e.RetryCount,
 				e.CreatedAt,
@@ -324,28 +870,124 @@ func copyOutbox(ctx context.Context, tx pgx.Tx, list []*models.OutboxEvent) erro
 
 func (r *PostgresNotificationRepository) FindById(
 	ctx context.Context,
-	id string,
+	tenantId, id string,
 ) (*models.Notification, error) {
 
 	query := `
-		SELECT id, recipient, channel, content, status, created_at
+		SELECT id, tenant_id, group_id, recipient, channel, content, status, priority,
+		       COALESCE(category, ''), COALESCE(suppression_reason, ''),
+		       COALESCE(provider_message_id, ''), COALESCE(delivery_status, ''), delivery_status_at,
+		       scheduled_at, COALESCE(timezone, ''),
+		       COALESCE(template_id::text, ''), COALESCE(template_version, 0), COALESCE(locale, ''),
+		       COALESCE(callback_url, ''), COALESCE(request_id, ''), COALESCE(ordering_key, ''), created_at
 		FROM notifications
 		WHERE id = $1
+		  AND tenant_id = $2
 	`
 
-	row := r.db.Read.QueryRow(ctx, query, id)
+	row := r.db.Read.QueryRow(ctx, query, id, tenantId)
 
 	var n models.Notification
 	if err := row.Scan(
 		&n.Id,
+		&n.TenantId,
+		&n.GroupId,
 		&n.Recipient,
 		&n.Channel,
 		&n.Content,
 		&n.Status,
+		&n.Priority,
+		&n.Category,
+		&n.SuppressionReason,
+		&n.ProviderMessageId,
+		&n.DeliveryStatus,
+		&n.DeliveryStatusAt,
+		&n.ScheduledAt,
+		&n.Timezone,
+		&n.TemplateId,
+		&n.TemplateVersion,
+		&n.Locale,
+		&n.CallbackUrl,
+		&n.RequestId,
+		&n.OrderingKey,
 		&n.CreatedAt,
-	); err != nil {
+	); errors.Is(err, pgx.ErrNoRows) {
+		return nil, repository.ErrNotFound
+	} else if err != nil {
 		return nil, err
 	}
 
 	return &n, nil
 }
+
+func (r *PostgresNotificationRepository) GroupStatusCounts(ctx context.Context, tenantId, groupId string) (map[string]int, error) {
+	rows, err := r.db.Read.Query(ctx, `
+		SELECT status, COUNT(*)
+		FROM notifications
+		WHERE tenant_id = $1
+		  AND group_id = $2
+		GROUP BY status
+	`, tenantId, groupId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := map[string]int{}
+	for rows.Next() {
+		var status string
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, err
+		}
+		counts[status] = count
+	}
+
+	return counts, rows.Err()
+}
+
+func (r *PostgresNotificationRepository) ListGroup(ctx context.Context, tenantId, groupId string, limit, offset int) ([]models.Notification, error) {
+	notifications := []models.Notification{}
+
+	rows, err := r.db.Read.Query(ctx, `
+		SELECT id, tenant_id, group_id, recipient, channel, priority, content, status,
+		       COALESCE(provider_message_id, ''), scheduled_at, created_at
+		FROM notifications
+		WHERE tenant_id = $1
+		  AND group_id = $2
+		ORDER BY created_at, id
+		LIMIT $3 OFFSET $4
+	`, tenantId, groupId, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var n models.Notification
+		err := rows.Scan(
+			&n.Id, &n.TenantId, &n.GroupId, &n.Recipient, &n.Channel, &n.Priority,
+			&n.Content, &n.Status, &n.ProviderMessageId, &n.ScheduledAt, &n.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, n)
+	}
+
+	return notifications, rows.Err()
+}
+
+func nullableString(value string) interface{} {
+	if value == "" {
+		return nil
+	}
+	return value
+}
+
+func nullableInt(value int) interface{} {
+	if value == 0 {
+		return nil
+	}
+	return value
+}
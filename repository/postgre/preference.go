@@ -0,0 +1,158 @@
+package postgre
+
+import (
+	"context"
+	"errors"
+
+	"github.com/HuseyinAsik/Notifications/models"
+	"github.com/HuseyinAsik/Notifications/pkg/gpostgresql"
+	"github.com/HuseyinAsik/Notifications/repository"
+	"github.com/jackc/pgx/v5"
+)
+
+const preferenceColumns = `tenant_id, recipient, channel, opted_out, COALESCE(quiet_hours_start, ''),
+	COALESCE(quiet_hours_end, ''), COALESCE(timezone, ''), categories, updated_at`
+
+type PostgresPreferenceRepository struct {
+	db *gpostgresql.Pool
+}
+
+func NewPostgresPreferenceRepository(db *gpostgresql.Pool) *PostgresPreferenceRepository {
+	return &PostgresPreferenceRepository{db: db}
+}
+
+func (r *PostgresPreferenceRepository) Upsert(ctx context.Context, preference models.RecipientPreference) error {
+	categories := preference.Categories
+	if categories == nil {
+		categories = map[string]bool{}
+	}
+
+	_, err := r.db.Write.Exec(ctx, `
+		INSERT INTO recipient_preferences (
+			tenant_id, recipient, channel, opted_out, quiet_hours_start, quiet_hours_end, timezone, categories, updated_at
+		)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), NULLIF($6, ''), NULLIF($7, ''), $8, NOW())
+		ON CONFLICT (tenant_id, recipient, channel) DO UPDATE
+		SET opted_out = EXCLUDED.opted_out,
+		    quiet_hours_start = EXCLUDED.quiet_hours_start,
+		    quiet_hours_end = EXCLUDED.quiet_hours_end,
+		    timezone = EXCLUDED.timezone,
+		    categories = EXCLUDED.categories,
+		    updated_at = NOW()
+	`,
+		preference.TenantId,
+		preference.Recipient,
+		preference.Channel,
+		preference.OptedOut,
+		preference.QuietHoursStart,
+		preference.QuietHoursEnd,
+		preference.Timezone,
+		categories,
+	)
+
+	return err
+}
+
+func (r *PostgresPreferenceRepository) Get(ctx context.Context, tenantId, recipient, channel string) (*models.RecipientPreference, error) {
+	row := r.db.Read.QueryRow(ctx, `
+		SELECT `+preferenceColumns+`
+		FROM recipient_preferences
+		WHERE tenant_id = $1
+		  AND recipient = $2
+		  AND channel = $3
+	`, tenantId, recipient, channel)
+
+	p, err := scanPreference(row)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, repository.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+func (r *PostgresPreferenceRepository) ListByRecipient(ctx context.Context, tenantId, recipient string) ([]models.RecipientPreference, error) {
+	rows, err := r.db.Read.Query(ctx, `
+		SELECT `+preferenceColumns+`
+		FROM recipient_preferences
+		WHERE tenant_id = $1
+		  AND recipient = $2
+		ORDER BY channel
+	`, tenantId, recipient)
+	if err != nil {
+		return nil, err
+	}
+
+	return collectPreferences(rows)
+}
+
+// FindMany returns the tenant's stored preferences for the given recipient/channel pairs; the
+// slices are read pairwise. Pairs without preferences are left out.
+func (r *PostgresPreferenceRepository) FindMany(ctx context.Context, tenantId string, recipients, channels []string) ([]models.RecipientPreference, error) {
+	rows, err := r.db.Read.Query(ctx, `
+		SELECT `+preferenceColumns+`
+		FROM recipient_preferences
+		WHERE tenant_id = $1
+		  AND (recipient, channel) IN (
+			SELECT * FROM unnest($2::text[], $3::text[])
+		)
+	`, tenantId, recipients, channels)
+	if err != nil {
+		return nil, err
+	}
+
+	return collectPreferences(rows)
+}
+
+func (r *PostgresPreferenceRepository) Delete(ctx context.Context, tenantId, recipient, channel string) error {
+	tag, err := r.db.Write.Exec(ctx, `
+		DELETE FROM recipient_preferences
+		WHERE tenant_id = $1
+		  AND recipient = $2
+		  AND channel = $3
+	`, tenantId, recipient, channel)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return repository.ErrNotFound
+	}
+
+	return nil
+}
+
+func scanPreference(row pgx.Row) (*models.RecipientPreference, error) {
+	var p models.RecipientPreference
+	if err := row.Scan(
+		&p.TenantId,
+		&p.Recipient,
+		&p.Channel,
+		&p.OptedOut,
+		&p.QuietHoursStart,
+		&p.QuietHoursEnd,
+		&p.Timezone,
+		&p.Categories,
+		&p.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	return &p, nil
+}
+
+func collectPreferences(rows pgx.Rows) ([]models.RecipientPreference, error) {
+	defer rows.Close()
+
+	preferences := []models.RecipientPreference{}
+	for rows.Next() {
+		p, err := scanPreference(rows)
+		if err != nil {
+			return nil, err
+		}
+		preferences = append(preferences, *p)
+	}
+
+	return preferences, rows.Err()
+}
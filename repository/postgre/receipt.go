@@ -0,0 +1,147 @@
+package postgre
+
+import (
+	"context"
+	"errors"
+
+	"github.com/HuseyinAsik/Notifications/models"
+	"github.com/HuseyinAsik/Notifications/pkg/gpostgresql"
+	"github.com/HuseyinAsik/Notifications/repository"
+	"github.com/jackc/pgx/v5"
+)
+
+type PostgresReceiptRepository struct {
+	db *gpostgresql.Pool
+}
+
+func NewPostgresReceiptRepository(db *gpostgresql.Pool) *PostgresReceiptRepository {
+	return &PostgresReceiptRepository{db: db}
+}
+
+// Record appends a provider receipt to the history of the notification sent on channel with
+// the receipt's provider message id, and moves its delivery status forward unless a later
+// receipt arrived first. Redelivered receipts are ignored.
+func (r *PostgresReceiptRepository) Record(ctx context.Context, channel string, receipt models.StatusHistory) error {
+
+	tx, err := r.db.Write.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, `
+		SELECT id, tenant_id
+		FROM notifications
+		WHERE channel = $1
+		  AND provider_message_id = $2
+		ORDER BY created_at DESC
+		LIMIT 1
+		FOR UPDATE
+	`, channel, receipt.ProviderMessageId).Scan(&receipt.NotificationId, &receipt.TenantId)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return repository.ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	tag, err := tx.Exec(ctx, `
+		INSERT INTO notification_status_history (
+			id, tenant_id, notification_id, status, provider, provider_message_id, reason, payload, occurred_at, received_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''), $8, $9, NOW())
+		ON CONFLICT (notification_id, status, occurred_at) DO NOTHING
+	`,
+		receipt.Id,
+		receipt.TenantId,
+		receipt.NotificationId,
+		receipt.Status,
+		receipt.Provider,
+		receipt.ProviderMessageId,
+		receipt.Reason,
+		receipt.Payload,
+		receipt.OccurredAt,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return tx.Commit(ctx)
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE notifications
+		SET delivery_status = $1,
+		    delivery_status_at = $2
+		WHERE id = $3
+		  AND (delivery_status_at IS NULL OR delivery_status_at <= $2)
+	`, receipt.Status, receipt.OccurredAt, receipt.NotificationId)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO webhook_deliveries (
+			tenant_id, notification_id, event, url, payload, status, attempts, next_attempt_at, created_at
+		)
+		SELECT n.tenant_id, n.id, $2, COALESCE(n.callback_url, t.webhook_url),
+		       jsonb_build_object(
+		           'notificationId', n.id,
+		           'groupId', n.group_id,
+		           'status', $2::text,
+		           'channel', n.channel,
+		           'recipient', n.recipient,
+		           'providerMessageId', n.provider_message_id,
+		           'reason', NULLIF($3, ''),
+		           'occurredAt', $4::timestamp
+		       ),
+		       'pending', 0, NOW(), NOW()
+		FROM notifications n
+		JOIN tenants t ON t.id = n.tenant_id
+		WHERE n.id = $1
+		  AND t.webhook_secret IS NOT NULL
+		  AND COALESCE(n.callback_url, t.webhook_url) IS NOT NULL
+	`, receipt.NotificationId, receipt.Status, receipt.Reason, receipt.OccurredAt)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *PostgresReceiptRepository) List(ctx context.Context, tenantId, notificationId string) ([]models.StatusHistory, error) {
+	history := []models.StatusHistory{}
+
+	rows, err := r.db.Read.Query(ctx, `
+		SELECT id, tenant_id, notification_id, status, provider, provider_message_id,
+		       COALESCE(reason, ''), occurred_at, received_at
+		FROM notification_status_history
+		WHERE tenant_id = $1
+		  AND notification_id = $2
+		ORDER BY occurred_at, received_at
+	`, tenantId, notificationId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var h models.StatusHistory
+		if err := rows.Scan(
+			&h.Id,
+			&h.TenantId,
+			&h.NotificationId,
+			&h.Status,
+			&h.Provider,
+			&h.ProviderMessageId,
+			&h.Reason,
+			&h.OccurredAt,
+			&h.ReceivedAt,
+		); err != nil {
+			return nil, err
+		}
+		history = append(history, h)
+	}
+
+	return history, rows.Err()
+}
@@ -0,0 +1,184 @@
+package postgre
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/HuseyinAsik/Notifications/models"
+	"github.com/HuseyinAsik/Notifications/pkg/gpostgresql"
+	"github.com/HuseyinAsik/Notifications/repository"
+	"github.com/jackc/pgx/v5"
+)
+
+type PostgresTemplateRepository struct {
+	db *gpostgresql.Pool
+}
+
+func NewPostgresTemplateRepository(db *gpostgresql.Pool) *PostgresTemplateRepository {
+	return &PostgresTemplateRepository{db: db}
+}
+
+func (r *PostgresTemplateRepository) Create(ctx context.Context, template models.Template) error {
+	_, err := r.db.Write.Exec(ctx, `
+		INSERT INTO templates (id, tenant_id, locale, version, name, channel, subject, body, created_at)
+		VALUES ($1, $2, $3, 1, $4, $5, NULLIF($6, ''), $7, $8)
+	`,
+		template.Id,
+		template.TenantId,
+		template.Locale,
+		template.Name,
+		template.Channel,
+		template.Subject,
+		template.Body,
+		template.CreatedAt,
+	)
+
+	return err
+}
+
+// AddVersion stores a new version of the template for the given locale. A locale that the
+// template does not have yet starts at version 1. Name and channel are kept from the template.
+func (r *PostgresTemplateRepository) AddVersion(ctx context.Context, template models.Template) (*models.Template, error) {
+	t := template
+	err := r.db.Write.QueryRow(ctx, `
+		INSERT INTO templates (id, tenant_id, locale, version, name, channel, subject, body, created_at)
+		SELECT $1, $2, $3,
+		       COALESCE((SELECT MAX(version) FROM templates WHERE id = $1 AND locale = $3), 0) + 1,
+		       name, channel, NULLIF($4, ''), $5, NOW()
+		FROM templates
+		WHERE id = $1
+		  AND tenant_id = $2
+		  AND deleted_at IS NULL
+		LIMIT 1
+		RETURNING version, name, channel, created_at
+	`, template.Id, template.TenantId, template.Locale, template.Subject, template.Body).Scan(
+		&t.Version,
+		&t.Name,
+		&t.Channel,
+		&t.CreatedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, repository.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &t, nil
+}
+
+func (r *PostgresTemplateRepository) FindLatest(ctx context.Context, tenantId, id, locale string) (*models.Template, error) {
+	var t models.Template
+	err := r.db.Read.QueryRow(ctx, `
+		SELECT id, tenant_id, name, channel, locale, version, COALESCE(subject, ''), body, created_at
+		FROM templates
+		WHERE id = $1
+		  AND tenant_id = $2
+		  AND locale = $3
+		  AND deleted_at IS NULL
+		ORDER BY version DESC
+		LIMIT 1
+	`, id, tenantId, locale).Scan(
+		&t.Id, &t.TenantId, &t.Name, &t.Channel, &t.Locale, &t.Version, &t.Subject, &t.Body, &t.CreatedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, repository.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &t, nil
+}
+
+func (r *PostgresTemplateRepository) ListVersions(ctx context.Context, tenantId, id string) ([]models.Template, error) {
+	templates := []models.Template{}
+
+	rows, err := r.db.Read.Query(ctx, `
+		SELECT id, tenant_id, name, channel, locale, version, COALESCE(subject, ''), body, created_at
+		FROM templates
+		WHERE id = $1
+		  AND tenant_id = $2
+		  AND deleted_at IS NULL
+		ORDER BY locale, version DESC
+	`, id, tenantId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var t models.Template
+		if err := rows.Scan(
+			&t.Id, &t.TenantId, &t.Name, &t.Channel, &t.Locale, &t.Version, &t.Subject, &t.Body, &t.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		templates = append(templates, t)
+	}
+
+	return templates, rows.Err()
+}
+
+// List returns the latest version of every template locale.
+func (r *PostgresTemplateRepository) List(ctx context.Context, tenantId, channel string, limit, offset int) ([]models.Template, int, error) {
+	templates := []models.Template{}
+	args := []interface{}{tenantId}
+	where := "WHERE tenant_id = $1 AND deleted_at IS NULL"
+
+	if channel != "" {
+		args = append(args, channel)
+		where += " AND channel = $" + strconv.Itoa(len(args))
+	}
+
+	latest := `SELECT DISTINCT ON (id, locale) id, name, channel, locale, version, COALESCE(subject, '') AS subject, body, created_at
+		FROM templates ` + where + " ORDER BY id, locale, version DESC"
+
+	var total int
+	err := r.db.Read.QueryRow(ctx, "SELECT COUNT(*) FROM ("+latest+") latest", args...).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	args = append(args, limit, offset)
+	query := "SELECT * FROM (" + latest + ") latest ORDER BY name, locale LIMIT $" + strconv.Itoa(len(args)-1) + " OFFSET $" + strconv.Itoa(len(args))
+
+	rows, err := r.db.Read.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var t models.Template
+		if err := rows.Scan(
+			&t.Id, &t.TenantId, &t.Name, &t.Channel, &t.Locale, &t.Version, &t.Subject, &t.Body, &t.CreatedAt,
+		); err != nil {
+			return nil, 0, err
+		}
+		templates = append(templates, t)
+	}
+
+	return templates, total, rows.Err()
+}
+
+// Delete hides every version of the template; rows are kept so notifications can still
+// refer to the version they were rendered with.
+func (r *PostgresTemplateRepository) Delete(ctx context.Context, tenantId, id string) error {
+	tag, err := r.db.Write.Exec(ctx, `
+		UPDATE templates
+		SET deleted_at = NOW()
+		WHERE id = $1
+		  AND tenant_id = $2
+		  AND deleted_at IS NULL
+	`, id, tenantId)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return repository.ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,172 @@
+package postgre
+
+import (
+	"context"
+	"errors"
+
+	"github.com/HuseyinAsik/Notifications/models"
+	"github.com/HuseyinAsik/Notifications/pkg/gpostgresql"
+	"github.com/HuseyinAsik/Notifications/repository"
+	"github.com/jackc/pgx/v5"
+)
+
+type PostgresTenantRepository struct {
+	db *gpostgresql.Pool
+}
+
+func NewPostgresTenantRepository(db *gpostgresql.Pool) *PostgresTenantRepository {
+	return &PostgresTenantRepository{db: db}
+}
+
+func (r *PostgresTenantRepository) CreateTenant(ctx context.Context, tenant models.Tenant) error {
+	_, err := r.db.Write.Exec(ctx, `
+		INSERT INTO tenants (id, name, created_at)
+		VALUES ($1, $2, $3)
+	`, tenant.Id, tenant.Name, tenant.CreatedAt)
+
+	return err
+}
+
+func (r *PostgresTenantRepository) ListTenants(ctx context.Context, limit, offset int) ([]models.Tenant, int, error) {
+	tenants := []models.Tenant{}
+
+	var total int
+	err := r.db.Read.QueryRow(ctx, "SELECT COUNT(*) FROM tenants").Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := r.db.Read.Query(ctx, `
+		SELECT id, name, COALESCE(webhook_url, ''), created_at
+		FROM tenants
+		ORDER BY created_at, id
+		LIMIT $1 OFFSET $2
+	`, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var t models.Tenant
+		if err := rows.Scan(&t.Id, &t.Name, &t.WebhookUrl, &t.CreatedAt); err != nil {
+			return nil, 0, err
+		}
+		tenants = append(tenants, t)
+	}
+
+	return tenants, total, rows.Err()
+}
+
+func (r *PostgresTenantRepository) FindTenant(ctx context.Context, id string) (*models.Tenant, error) {
+	var t models.Tenant
+	err := r.db.Read.QueryRow(ctx, `
+		SELECT id, name, COALESCE(webhook_url, ''), COALESCE(webhook_secret, ''), created_at
+		FROM tenants
+		WHERE id = $1
+	`, id).Scan(&t.Id, &t.Name, &t.WebhookUrl, &t.WebhookSecret, &t.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, repository.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &t, nil
+}
+
+func (r *PostgresTenantRepository) CreateApiKey(ctx context.Context, apiKey models.ApiKey) error {
+	_, err := r.db.Write.Exec(ctx, `
+		INSERT INTO api_keys (id, tenant_id, name, prefix, key_hash, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`,
+		apiKey.Id,
+		apiKey.TenantId,
+		apiKey.Name,
+		apiKey.Prefix,
+		apiKey.KeyHash,
+		apiKey.CreatedAt,
+	)
+
+	return err
+}
+
+func (r *PostgresTenantRepository) ListApiKeys(ctx context.Context, tenantId string) ([]models.ApiKey, error) {
+	apiKeys := []models.ApiKey{}
+
+	rows, err := r.db.Read.Query(ctx, `
+		SELECT id, tenant_id, name, prefix, created_at, revoked_at
+		FROM api_keys
+		WHERE tenant_id = $1
+		ORDER BY created_at DESC
+	`, tenantId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var k models.ApiKey
+		if err := rows.Scan(&k.Id, &k.TenantId, &k.Name, &k.Prefix, &k.CreatedAt, &k.RevokedAt); err != nil {
+			return nil, err
+		}
+		apiKeys = append(apiKeys, k)
+	}
+
+	return apiKeys, rows.Err()
+}
+
+func (r *PostgresTenantRepository) RevokeApiKey(ctx context.Context, id string) error {
+	tag, err := r.db.Write.Exec(ctx, `
+		UPDATE api_keys
+		SET revoked_at = NOW()
+		WHERE id = $1
+		  AND revoked_at IS NULL
+	`, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return repository.ErrNotFound
+	}
+
+	return nil
+}
+
+// FindApiKeyByHash returns the active key with the given hash; revoked keys are not found.
+func (r *PostgresTenantRepository) FindApiKeyByHash(ctx context.Context, keyHash string) (*models.ApiKey, error) {
+	var k models.ApiKey
+	err := r.db.Read.QueryRow(ctx, `
+		SELECT id, tenant_id, name, prefix, created_at
+		FROM api_keys
+		WHERE key_hash = $1
+		  AND revoked_at IS NULL
+	`, keyHash).Scan(&k.Id, &k.TenantId, &k.Name, &k.Prefix, &k.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, repository.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &k, nil
+}
+
+// UpdateWebhook sets the tenant's default callback URL; an empty url clears it. The signing
+// secret is kept when secret is empty.
+func (r *PostgresTenantRepository) UpdateWebhook(ctx context.Context, id, url, secret string) error {
+	tag, err := r.db.Write.Exec(ctx, `
+		UPDATE tenants
+		SET webhook_url = NULLIF($2, ''),
+		    webhook_secret = COALESCE(NULLIF($3, ''), webhook_secret)
+		WHERE id = $1
+	`, id, url, secret)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return repository.ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,40 @@
+package postgre
+
+import (
+	"context"
+	"errors"
+
+	"github.com/HuseyinAsik/Notifications/pkg/gpostgresql"
+	"github.com/jackc/pgx/v5"
+)
+
+type PostgresTokenBucketRepository struct {
+	db *gpostgresql.Pool
+}
+
+func NewPostgresTokenBucketRepository(db *gpostgresql.Pool) *PostgresTokenBucketRepository {
+	return &PostgresTokenBucketRepository{db: db}
+}
+
+// Take refills the bucket for the time elapsed since its last use and removes one token
+// if available. The database clock is used so replicas with skewed clocks agree.
+func (r *PostgresTokenBucketRepository) Take(ctx context.Context, key string, ratePerSecond float64, burst int) (bool, error) {
+	var tokens float64
+	err := r.db.Write.QueryRow(ctx, `
+		INSERT INTO token_buckets (key, tokens, updated_at)
+		VALUES ($1, $3 - 1, clock_timestamp())
+		ON CONFLICT (key) DO UPDATE
+		SET tokens = LEAST($3, token_buckets.tokens + EXTRACT(EPOCH FROM clock_timestamp() - token_buckets.updated_at) * $2) - 1,
+		    updated_at = clock_timestamp()
+		WHERE LEAST($3, token_buckets.tokens + EXTRACT(EPOCH FROM clock_timestamp() - token_buckets.updated_at) * $2) >= 1
+		RETURNING tokens
+	`, key, ratePerSecond, float64(burst)).Scan(&tokens)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
@@ -0,0 +1,184 @@
+package postgre
+
+import (
+	"context"
+	"time"
+
+	"github.com/HuseyinAsik/Notifications/models"
+	"github.com/HuseyinAsik/Notifications/pkg/gpostgresql"
+)
+
+type PostgresWebhookRepository struct {
+	db *gpostgresql.Pool
+}
+
+func NewPostgresWebhookRepository(db *gpostgresql.Pool) *PostgresWebhookRepository {
+	return &PostgresWebhookRepository{db: db}
+}
+
+// ClaimDue leases up to limit due deliveries to the caller. A delivery whose dispatcher
+// dies mid-call becomes due again once the lease expires.
+func (r *PostgresWebhookRepository) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]models.WebhookDelivery, error) {
+	deliveries := []models.WebhookDelivery{}
+
+	rows, err := r.db.Write.Query(ctx, `
+		WITH due AS (
+			SELECT id
+			FROM webhook_deliveries
+			WHERE status IN ('pending', 'delivering')
+			  AND next_attempt_at <= NOW()
+			ORDER BY next_attempt_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE webhook_deliveries d
+		SET status = 'delivering',
+		    next_attempt_at = NOW() + make_interval(secs => $2)
+		FROM due, tenants t
+		WHERE d.id = due.id
+		  AND t.id = d.tenant_id
+		RETURNING d.id, d.tenant_id, d.notification_id, d.event, d.url, d.payload, d.status,
+		          d.attempts, d.created_at, COALESCE(t.webhook_secret, '')
+	`, limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var d models.WebhookDelivery
+		if err := rows.Scan(
+			&d.Id,
+			&d.TenantId,
+			&d.NotificationId,
+			&d.Event,
+			&d.Url,
+			&d.Payload,
+			&d.Status,
+			&d.Attempts,
+			&d.CreatedAt,
+			&d.Secret,
+		); err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, d)
+	}
+
+	return deliveries, rows.Err()
+}
+
+// RecordAttempt logs one call of the delivery and stores its outcome on the delivery.
+func (r *PostgresWebhookRepository) RecordAttempt(ctx context.Context, delivery models.WebhookDelivery, attempt models.WebhookAttempt) error {
+
+	tx, err := r.db.Write.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO webhook_delivery_attempts (delivery_id, attempt, status_code, error, duration_ms, created_at)
+		VALUES ($1, $2, NULLIF($3, 0), NULLIF($4, ''), $5, NOW())
+	`, delivery.Id, attempt.Attempt, attempt.StatusCode, attempt.Error, attempt.DurationMs)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE webhook_deliveries
+		SET status = $2,
+		    attempts = $3,
+		    next_attempt_at = $4,
+		    last_status_code = NULLIF($5, 0),
+		    last_error = NULLIF($6, ''),
+		    delivered_at = $7
+		WHERE id = $1
+	`,
+		delivery.Id,
+		delivery.Status,
+		delivery.Attempts,
+		delivery.NextAttemptAt,
+		delivery.LastStatusCode,
+		delivery.LastError,
+		delivery.DeliveredAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *PostgresWebhookRepository) ListByNotification(ctx context.Context, tenantId, notificationId string) ([]models.WebhookDelivery, error) {
+	deliveries := []models.WebhookDelivery{}
+	index := map[string]int{}
+
+	rows, err := r.db.Read.Query(ctx, `
+		SELECT id, tenant_id, notification_id, event, url, payload, status, attempts, next_attempt_at,
+		       COALESCE(last_status_code, 0), COALESCE(last_error, ''), created_at, delivered_at
+		FROM webhook_deliveries
+		WHERE tenant_id = $1
+		  AND notification_id = $2
+		ORDER BY created_at, id
+	`, tenantId, notificationId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		d := models.WebhookDelivery{AttemptLog: []models.WebhookAttempt{}}
+		if err := rows.Scan(
+			&d.Id,
+			&d.TenantId,
+			&d.NotificationId,
+			&d.Event,
+			&d.Url,
+			&d.Payload,
+			&d.Status,
+			&d.Attempts,
+			&d.NextAttemptAt,
+			&d.LastStatusCode,
+			&d.LastError,
+			&d.CreatedAt,
+			&d.DeliveredAt,
+		); err != nil {
+			return nil, err
+		}
+		index[d.Id] = len(deliveries)
+		deliveries = append(deliveries, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(deliveries) == 0 {
+		return deliveries, nil
+	}
+
+	ids := make([]string, 0, len(deliveries))
+	for _, d := range deliveries {
+		ids = append(ids, d.Id)
+	}
+
+	attemptRows, err := r.db.Read.Query(ctx, `
+		SELECT id, delivery_id, attempt, COALESCE(status_code, 0), COALESCE(error, ''), duration_ms, created_at
+		FROM webhook_delivery_attempts
+		WHERE delivery_id = ANY($1)
+		ORDER BY delivery_id, attempt
+	`, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer attemptRows.Close()
+
+	for attemptRows.Next() {
+		var a models.WebhookAttempt
+		if err := attemptRows.Scan(&a.Id, &a.DeliveryId, &a.Attempt, &a.StatusCode, &a.Error, &a.DurationMs, &a.CreatedAt); err != nil {
+			return nil, err
+		}
+		i := index[a.DeliveryId]
+		deliveries[i].AttemptLog = append(deliveries[i].AttemptLog, a)
+	}
+
+	return deliveries, attemptRows.Err()
+}
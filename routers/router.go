@@ -1,17 +1,23 @@
 package routers
 
 import (
+	"context"
 	"net/http"
 
 	"github.com/HuseyinAsik/Notifications/cmd/notification-api/pkg/settings"
 	"github.com/HuseyinAsik/Notifications/controller"
 	"github.com/HuseyinAsik/Notifications/middleware"
+	"github.com/HuseyinAsik/Notifications/pkg/auth"
 	"github.com/HuseyinAsik/Notifications/pkg/gpostgresql"
 	"github.com/HuseyinAsik/Notifications/pkg/httpx"
 	logging "github.com/HuseyinAsik/Notifications/pkg/logging"
+	"github.com/HuseyinAsik/Notifications/pkg/timeline"
+	"github.com/HuseyinAsik/Notifications/providers"
 	"github.com/HuseyinAsik/Notifications/repository/postgre"
 	"github.com/HuseyinAsik/Notifications/services"
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.uber.org/zap"
 )
 
 func NewRouter(logger *logging.LogWrapper) *gin.Engine {
@@ -19,10 +25,14 @@ func NewRouter(logger *logging.LogWrapper) *gin.Engine {
 
 	r.Use(gin.Logger())
 	r.Use(gin.Recovery())
+	r.Use(middleware.TracingMiddleware())
+	r.Use(middleware.RequestIdMiddleware())
 	r.Use(middleware.TimeoutMiddleware(settings.AppSettings.ContextTimeout_))
 	r.Use(middleware.LogMiddleware(logger.ZapLogger))
 	r.Use(middleware.LogRecoveryMiddleware(logger.ZapLogger))
+	r.Use(middleware.MetricsMiddleware())
 	r.GET("/healthcheck", func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"status": "OK"}) })
+	r.GET("/metrics", gin.WrapH(promhttp.Handler()))
 	return r
 }
 
@@ -30,9 +40,80 @@ func BuildServices(httpClient httpx.HTTPClient, pgPool *gpostgresql.Pool) *gin.E
 	logger := logging.GetLogger()
 	router := NewRouter(logger)
 	repo := postgre.NewPostgresNotificationRepository(pgPool)
+	deadLetterRepo := postgre.NewPostgresDeadLetterRepository(pgPool)
+	idempotencyRepo := postgre.NewPostgresIdempotencyRepository(pgPool)
+	templateRepo := postgre.NewPostgresTemplateRepository(pgPool)
+	preferenceRepo := postgre.NewPostgresPreferenceRepository(pgPool)
+	deviceTokenRepo := postgre.NewPostgresDeviceTokenRepository(pgPool)
+	tenantRepo := postgre.NewPostgresTenantRepository(pgPool)
+	webhookRepo := postgre.NewPostgresWebhookRepository(pgPool)
+	receiptRepo := postgre.NewPostgresReceiptRepository(pgPool)
+	eventRepo := postgre.NewPostgresNotificationEventRepository(pgPool)
 
-	notificationService := services.NewNotificationService(repo, logger)
-	controller.NewNotificationController(router, notificationService, logger)
+	var verifier *auth.Verifier
+	if settings.JwtSettings.JwksSource != "" {
+		keys, err := auth.NewJWKS(context.Background(), settings.JwtSettings.JwksSource, httpClient, settings.JwtSettings.RefreshInterval, logger)
+		if err != nil {
+			logger.Fatal(context.Background(), "JWKS load err", zap.Error(err))
+		}
+		go keys.Run(context.Background())
+		verifier = auth.NewVerifier(keys, settings.JwtSettings.Issuer, settings.JwtSettings.Audience, settings.JwtSettings.TenantClaim, settings.JwtSettings.ScopeClaim)
+	}
+
+	tenantService := services.NewTenantService(tenantRepo, logger)
+	authenticate := middleware.AuthMiddleware(tenantService, verifier, settings.AuthSettings.AdminApiKey)
+
+	admin := router.Group("", authenticate, middleware.RequireScope(auth.ScopeAdmin))
+	controller.NewTenantController(admin, tenantService, logger)
+
+	tenantRoutes := router.Group("", authenticate)
+
+	idempotencyService := services.NewIdempotencyService(idempotencyRepo, settings.IdempotencySettings.Retention, settings.IdempotencySettings.LockTimeout, logger)
+	go idempotencyService.RunPurge(context.Background(), settings.IdempotencySettings.PurgeInterval)
+
+	templateService := services.NewTemplateService(templateRepo, settings.TemplateSettings.DefaultLocale, logger)
+	controller.NewTemplateController(tenantRoutes, templateService, logger)
+
+	preferenceService := services.NewPreferenceService(preferenceRepo, logger)
+	controller.NewPreferenceController(tenantRoutes, preferenceService, logger)
+
+	deviceTokenService := services.NewDeviceTokenService(deviceTokenRepo, logger)
+	controller.NewDeviceTokenController(tenantRoutes, deviceTokenService, logger)
+
+	recorder := timeline.NewRecorder(eventRepo, timeline.SourceApi, settings.AppSettings.Hostname, logger)
+	notificationService := services.NewNotificationService(repo, preferenceRepo, eventRepo, templateService, recorder, settings.SchedulerSettings.MaxHorizon, logger)
+	controller.NewNotificationController(tenantRoutes, notificationService, middleware.IdempotencyMiddleware(idempotencyService), logger)
+
+	webhookService := services.NewWebhookService(webhookRepo, repo, logger)
+	controller.NewWebhookController(tenantRoutes, webhookService, logger)
+
+	receiptService := services.NewReceiptService(receiptRepo, repo, receiptParsers(logger), logger)
+	controller.NewReceiptController(router, tenantRoutes, receiptService, logger)
+
+	deadLetterService := services.NewDeadLetterService(deadLetterRepo, logger)
+	controller.NewDeadLetterController(admin, deadLetterService, logger)
 
 	return router
 }
+
+// receiptParsers returns the delivery report parsers of the providers whose callback secret
+// is configured; callbacks of other providers are answered with 404.
+func receiptParsers(logger *logging.LogWrapper) map[string]providers.ReceiptParser {
+	parsers := map[string]providers.ReceiptParser{}
+
+	if settings.ReceiptSettings.SmsSecret != "" {
+		parsers["sms"] = providers.NewRESTSMSReceiptParser(settings.ReceiptSettings)
+	}
+	if settings.ReceiptSettings.MailgunSigningKey != "" {
+		parsers["mailgun"] = providers.NewMailgunReceiptParser(settings.ReceiptSettings.MailgunSigningKey, settings.ReceiptSettings.Tolerance)
+	}
+	if settings.ReceiptSettings.SendgridPublicKey != "" {
+		parser, err := providers.NewSendgridReceiptParser(settings.ReceiptSettings.SendgridPublicKey, settings.ReceiptSettings.Tolerance)
+		if err != nil {
+			logger.Fatal(context.Background(), "SendGrid receipt parser err", zap.Error(err))
+		}
+		parsers["sendgrid"] = parser
+	}
+
+	return parsers
+}
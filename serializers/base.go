@@ -2,6 +2,7 @@ package serializers
 
 import (
 	"context"
+	"time"
 
 	"github.com/HuseyinAsik/Notifications/models"
 	"github.com/HuseyinAsik/Notifications/pkg/logging"
@@ -26,6 +27,89 @@ type NotificationListResponse struct {
 	Notifications []models.Notification `json:"notifications"`
 }
 
+type DeliveryResponse struct {
+	Status        string     `json:"status"`
+	Attempts      int        `json:"attempts"`
+	NextAttemptAt *time.Time `json:"nextAttemptAt,omitempty"`
+	PublishedAt   *time.Time `json:"publishedAt,omitempty"`
+}
+
+type NotificationDetailResponse struct {
+	models.Notification
+	Delivery *DeliveryResponse `json:"delivery,omitempty"`
+}
+
+type NotificationEventListResponse struct {
+	Events []models.NotificationEvent `json:"events"`
+}
+
+type GroupStatusResponse struct {
+	GroupId       string                `json:"groupId"`
+	Total         int                   `json:"total"`
+	Counts        map[string]int        `json:"counts"`
+	Page          int                   `json:"page"`
+	Notifications []models.Notification `json:"notifications"`
+}
+
+type TemplateResponse struct {
+	Template models.Template `json:"template"`
+}
+
+type TemplateVersionsResponse struct {
+	Versions []models.Template `json:"versions"`
+}
+
+type TemplateListResponse struct {
+	Total     int               `json:"total"`
+	Templates []models.Template `json:"templates"`
+}
+
+type PreferenceListResponse struct {
+	Preferences []models.RecipientPreference `json:"preferences"`
+}
+
+type TenantListResponse struct {
+	Total   int             `json:"total"`
+	Tenants []models.Tenant `json:"tenants"`
+}
+
+type ApiKeyResponse struct {
+	ApiKey models.ApiKey `json:"apiKey"`
+	Key    string        `json:"key,omitempty"`
+}
+
+type ApiKeyListResponse struct {
+	ApiKeys []models.ApiKey `json:"apiKeys"`
+}
+
+type TenantWebhookResponse struct {
+	Tenant models.Tenant `json:"tenant"`
+	Secret string        `json:"secret,omitempty"`
+}
+
+type WebhookDeliveryListResponse struct {
+	Deliveries []models.WebhookDelivery `json:"deliveries"`
+}
+
+type ReceiptResponse struct {
+	Recorded  int `json:"recorded"`
+	Unmatched int `json:"unmatched"`
+}
+
+type StatusHistoryResponse struct {
+	History []models.StatusHistory `json:"history"`
+}
+
+type DeadLetterListResponse struct {
+	Total       int                 `json:"total"`
+	DeadLetters []models.DeadLetter `json:"deadLetters"`
+}
+
+type ReplayDeadLettersResponse struct {
+	Replayed []string `json:"replayed"`
+	Skipped  []string `json:"skipped"`
+}
+
 func (s *Serializer) ShouldBindJSON(ctx context.Context, obj interface{}) error {
 	err := s.C.ShouldBindJSON(obj)
 	if err != nil {
@@ -44,6 +128,15 @@ func (s *Serializer) ShouldBindQuery(ctx context.Context, obj interface{}) error
 	return nil
 }
 
+func (s *Serializer) ShouldBindUri(ctx context.Context, obj interface{}) error {
+	err := s.C.ShouldBindUri(obj)
+	if err != nil {
+		s.Logger.Warn(ctx, "Serializer ShouldBindUri Validation Err", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
 func (s *Serializer) Validate(ctx context.Context, form interface{}) error {
 	validate := validator.New()
 	err := validate.StructCtx(ctx, form)
@@ -60,3 +153,75 @@ func (s *Serializer) NotificationResponse(httpCode int, data NotificationRespons
 func (s *Serializer) NotificationListResponse(httpCode int, data NotificationListResponse) {
 	s.C.JSON(httpCode, data)
 }
+
+func (s *Serializer) NotificationDetailResponse(httpCode int, data NotificationDetailResponse) {
+	s.C.JSON(httpCode, data)
+}
+
+func (s *Serializer) NotificationEventListResponse(httpCode int, data NotificationEventListResponse) {
+	s.C.JSON(httpCode, data)
+}
+
+func (s *Serializer) GroupStatusResponse(httpCode int, data GroupStatusResponse) {
+	s.C.JSON(httpCode, data)
+}
+
+func (s *Serializer) TemplateResponse(httpCode int, data TemplateResponse) {
+	s.C.JSON(httpCode, data)
+}
+
+func (s *Serializer) TemplateVersionsResponse(httpCode int, data TemplateVersionsResponse) {
+	s.C.JSON(httpCode, data)
+}
+
+func (s *Serializer) TemplateListResponse(httpCode int, data TemplateListResponse) {
+	s.C.JSON(httpCode, data)
+}
+
+func (s *Serializer) PreferenceResponse(httpCode int, data models.RecipientPreference) {
+	s.C.JSON(httpCode, data)
+}
+
+func (s *Serializer) PreferenceListResponse(httpCode int, data PreferenceListResponse) {
+	s.C.JSON(httpCode, data)
+}
+
+func (s *Serializer) TenantResponse(httpCode int, data models.Tenant) {
+	s.C.JSON(httpCode, data)
+}
+
+func (s *Serializer) TenantListResponse(httpCode int, data TenantListResponse) {
+	s.C.JSON(httpCode, data)
+}
+
+func (s *Serializer) ApiKeyResponse(httpCode int, data ApiKeyResponse) {
+	s.C.JSON(httpCode, data)
+}
+
+func (s *Serializer) ApiKeyListResponse(httpCode int, data ApiKeyListResponse) {
+	s.C.JSON(httpCode, data)
+}
+
+func (s *Serializer) TenantWebhookResponse(httpCode int, data TenantWebhookResponse) {
+	s.C.JSON(httpCode, data)
+}
+
+func (s *Serializer) WebhookDeliveryListResponse(httpCode int, data WebhookDeliveryListResponse) {
+	s.C.JSON(httpCode, data)
+}
+
+func (s *Serializer) ReceiptResponse(httpCode int, data ReceiptResponse) {
+	s.C.JSON(httpCode, data)
+}
+
+func (s *Serializer) StatusHistoryResponse(httpCode int, data StatusHistoryResponse) {
+	s.C.JSON(httpCode, data)
+}
+
+func (s *Serializer) DeadLetterListResponse(httpCode int, data DeadLetterListResponse) {
+	s.C.JSON(httpCode, data)
+}
+
+func (s *Serializer) ReplayDeadLettersResponse(httpCode int, data ReplayDeadLettersResponse) {
+	s.C.JSON(httpCode, data)
+}
@@ -0,0 +1,40 @@
+package serializers
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+type DeadLetterListForm struct {
+	PageStr  string `form:"page"`
+	Channel  string `form:"channel"`
+	TenantId string `form:"tenant_id" validate:"omitempty,uuid"`
+	Page     int
+}
+
+func (s *DeadLetterListForm) Validate(ctx context.Context) error {
+	s.Page = 1
+	if page, err := strconv.Atoi(s.PageStr); err == nil && page > 0 {
+		s.Page = page
+	}
+	s.Channel = strings.ToLower(s.Channel)
+
+	validate := validator.New()
+	err := validate.StructCtx(ctx, s)
+
+	return err
+}
+
+type ReplayDeadLettersForm struct {
+	Ids []string `json:"ids" validate:"required,min=1,max=500,dive,uuid"`
+}
+
+func (s *ReplayDeadLettersForm) Validate(ctx context.Context) error {
+	validate := validator.New()
+	err := validate.StructCtx(ctx, s)
+
+	return err
+}
@@ -0,0 +1,18 @@
+package serializers
+
+import (
+	"context"
+
+	"github.com/go-playground/validator/v10"
+)
+
+type DeviceTokenForm struct {
+	Token string `json:"token" validate:"required,max=512"`
+}
+
+func (s *DeviceTokenForm) Validate(ctx context.Context) error {
+	validate := validator.New()
+	err := validate.StructCtx(ctx, s)
+
+	return err
+}
@@ -3,19 +3,33 @@ package serializers
 import (
 	"context"
 	"errors"
+	"fmt"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/HuseyinAsik/Notifications/pkg/httpx"
 	"github.com/go-playground/validator/v10"
 )
 
 type CreateNotificationForm struct {
-	Recipient   string     `json:"recipient" validate:"required"`
-	Channel     string     `json:"channel" validate:"required,oneof=sms email push"`
-	Content     string     `json:"content" validate:"required"`
-	Priority    string     `json:"priority" validate:"required,oneof=high medium low"`
-	ScheduledAt *time.Time `json:"scheduled_at,omitempty"`
+	Recipient   string         `json:"recipient" validate:"required"`
+	Channel     string         `json:"channel" validate:"required,oneof=sms email push"`
+	Content     string         `json:"content" validate:"required_without=TemplateId,excluded_with=TemplateId"`
+	TemplateId  string         `json:"template_id,omitempty" validate:"omitempty,uuid"`
+	Variables   map[string]any `json:"variables,omitempty"`
+	Locale      string         `json:"locale,omitempty" validate:"omitempty,bcp47_language_tag"`
+	Priority    string         `json:"priority" validate:"required,oneof=high medium low"`
+	Category    string         `json:"category,omitempty" validate:"omitempty,max=50"`
+	Timezone    string         `json:"timezone,omitempty" validate:"omitempty,timezone"`
+	CallbackUrl string         `json:"callback_url,omitempty" validate:"omitempty,http_url"`
+	OrderingKey string         `json:"ordering_key,omitempty" validate:"omitempty,max=255"`
+
+	QuietHoursStart string `json:"quiet_hours_start,omitempty" validate:"omitempty,datetime=15:04,required_with=QuietHoursEnd"`
+	QuietHoursEnd   string `json:"quiet_hours_end,omitempty" validate:"omitempty,datetime=15:04,required_with=QuietHoursStart"`
+
+	ScheduledAtStr string     `json:"scheduled_at,omitempty"`
+	ScheduledAt    *time.Time `json:"-"`
 }
 
 func (s *CreateNotificationForm) Validate(ctx context.Context) error {
@@ -23,12 +37,64 @@ func (s *CreateNotificationForm) Validate(ctx context.Context) error {
 	s.Channel = strings.ToLower(s.Channel)
 	s.Priority = strings.ToLower(s.Priority)
 	err := validate.StructCtx(ctx, s)
+	if err != nil {
+		return err
+	}
+	if err := checkCallbackUrl(s.CallbackUrl); err != nil {
+		return err
+	}
+
+	s.ScheduledAt, err = InLocation(s.ScheduledAtStr, s.Timezone)
 
 	return err
 }
 
+// checkCallbackUrl keeps webhooks away from the service's own network: loopback, private and
+// link-local hosts, cloud metadata endpoints among them, are refused.
+func checkCallbackUrl(callbackUrl string) error {
+	if callbackUrl == "" {
+		return nil
+	}
+	if err := httpx.CheckPublicUrl(callbackUrl); err != nil {
+		return fmt.Errorf("callback_url: %w", err)
+	}
+	return nil
+}
+
+// localLayout is a scheduled_at without an offset.
+const localLayout = "2006-01-02T15:04:05"
+
+// InLocation parses scheduledAt and returns it in UTC. A time with an offset, "Z" included,
+// is taken as it is. A time without one is a wall clock in the given IANA time zone, so
+// "2026-03-01T09:00:00" with "Europe/Istanbul" means 09:00 Istanbul time; without a zone it
+// is read in UTC.
+func InLocation(scheduledAt, timezone string) (*time.Time, error) {
+	if scheduledAt == "" {
+		return nil, nil
+	}
+
+	loc := time.UTC
+	if timezone != "" {
+		var err error
+		if loc, err = time.LoadLocation(timezone); err != nil {
+			return nil, fmt.Errorf("invalid timezone %q", timezone)
+		}
+	}
+
+	t, err := time.Parse(time.RFC3339, scheduledAt)
+	if err != nil {
+		if t, err = time.ParseInLocation(localLayout, scheduledAt, loc); err != nil {
+			return nil, fmt.Errorf("scheduled_at %q is not an RFC 3339 time", scheduledAt)
+		}
+	}
+	t = t.UTC()
+
+	return &t, nil
+}
+
 type CreateNotificationBatchForm struct {
-	Data []CreateNotificationForm `json:"data" validate:"required,min=1,max=1000,dive"`
+	Data        []CreateNotificationForm `json:"data" validate:"required,min=1,max=1000,dive"`
+	CallbackUrl string                   `json:"callback_url,omitempty" validate:"omitempty,http_url"`
 }
 
 func (s *CreateNotificationBatchForm) Validate(ctx context.Context) error {
@@ -42,10 +108,78 @@ func (s *CreateNotificationBatchForm) Validate(ctx context.Context) error {
 	if len(s.Data) == 0 || len(s.Data) > 1000 {
 		return errors.New("request must include between 1 to 1000 notifications")
 	}
+	if err := checkCallbackUrl(s.CallbackUrl); err != nil {
+		return err
+	}
 
 	for i := range s.Data {
 		s.Data[i].Channel = strings.ToLower(s.Data[i].Channel)
 		s.Data[i].Priority = strings.ToLower(s.Data[i].Priority)
+		s.Data[i].ScheduledAt, err = InLocation(s.Data[i].ScheduledAtStr, s.Data[i].Timezone)
+		if err != nil {
+			return fmt.Errorf("data[%d]: %w", i, err)
+		}
+		if err := checkCallbackUrl(s.Data[i].CallbackUrl); err != nil {
+			return fmt.Errorf("data[%d]: %w", i, err)
+		}
+		if s.Data[i].CallbackUrl == "" {
+			s.Data[i].CallbackUrl = s.CallbackUrl
+		}
+	}
+
+	return nil
+}
+
+type NotificationIdForm struct {
+	Id string `uri:"id" validate:"required,uuid"`
+}
+
+func (s *NotificationIdForm) Validate(ctx context.Context) error {
+	validate := validator.New()
+	err := validate.StructCtx(ctx, s)
+
+	return err
+}
+
+type GroupStatusForm struct {
+	GroupId string `uri:"groupId" validate:"required,uuid"`
+	PageStr string `form:"page"`
+	Page    int
+}
+
+func (s *GroupStatusForm) Validate(ctx context.Context) error {
+	validate := validator.New()
+	if err := validate.StructCtx(ctx, s); err != nil {
+		return err
+	}
+
+	s.Page = 1
+	if page, err := strconv.Atoi(s.PageStr); err == nil && page > 0 {
+		s.Page = page
+	}
+
+	return nil
+}
+
+type RescheduleForm struct {
+	ScheduledAtStr string     `json:"scheduled_at" validate:"required"`
+	Timezone       string     `json:"timezone,omitempty" validate:"omitempty,timezone"`
+	ScheduledAt    *time.Time `json:"-"`
+}
+
+func (s *RescheduleForm) Validate(ctx context.Context) error {
+	validate := validator.New()
+	err := validate.StructCtx(ctx, s)
+	if err != nil {
+		return err
+	}
+
+	s.ScheduledAt, err = InLocation(s.ScheduledAtStr, s.Timezone)
+	if err != nil {
+		return err
+	}
+	if !s.ScheduledAt.After(time.Now()) {
+		return errors.New("scheduled_at must be in the future")
 	}
 
 	return nil
@@ -65,7 +199,7 @@ type ListForm struct {
 func (s *ListForm) Validate(ctx context.Context) error {
 	page, err := strconv.Atoi(s.PageStr)
 	s.Page = 1
-	if err != nil && page > 0 {
+	if err == nil && page > 0 {
 		s.Page = page
 	}
 
@@ -0,0 +1,91 @@
+package serializers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/HuseyinAsik/Notifications/pkg/httpx"
+)
+
+func TestInLocation(t *testing.T) {
+	tests := []struct {
+		name        string
+		scheduledAt string
+		timezone    string
+		want        time.Time
+		wantErr     bool
+	}{
+		{name: "utc", scheduledAt: "2026-03-01T09:00:00Z", timezone: "Europe/Istanbul", want: time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)},
+		{name: "matching offset", scheduledAt: "2026-03-01T09:00:00+03:00", timezone: "Europe/Istanbul", want: time.Date(2026, 3, 1, 6, 0, 0, 0, time.UTC)},
+		{name: "conflicting offset", scheduledAt: "2026-03-01T09:00:00+01:00", timezone: "Europe/Istanbul", want: time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)},
+		{name: "local time", scheduledAt: "2026-03-01T09:00:00", timezone: "Europe/Istanbul", want: time.Date(2026, 3, 1, 6, 0, 0, 0, time.UTC)},
+		{name: "local time without a zone", scheduledAt: "2026-03-01T09:00:00", want: time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)},
+		{name: "before dst starts", scheduledAt: "2026-03-08T01:30:00", timezone: "America/New_York", want: time.Date(2026, 3, 8, 6, 30, 0, 0, time.UTC)},
+		{name: "after dst starts", scheduledAt: "2026-03-08T03:30:00", timezone: "America/New_York", want: time.Date(2026, 3, 8, 7, 30, 0, 0, time.UTC)},
+		{name: "fractional seconds", scheduledAt: "2026-03-01T09:00:00.5", timezone: "Europe/Istanbul", want: time.Date(2026, 3, 1, 6, 0, 0, 5e8, time.UTC)},
+		{name: "unknown zone", scheduledAt: "2026-03-01T09:00:00", timezone: "Mars/Olympus", wantErr: true},
+		{name: "not a time", scheduledAt: "tomorrow", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := InLocation(tt.scheduledAt, tt.timezone)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("InLocation = %v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("InLocation: %v", err)
+			}
+			if !got.Equal(tt.want) || got.Location() != time.UTC {
+				t.Errorf("InLocation = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInLocationWithoutTime(t *testing.T) {
+	got, err := InLocation("", "Europe/Istanbul")
+	if got != nil || err != nil {
+		t.Errorf("InLocation = %v, %v, want nil", got, err)
+	}
+}
+
+func TestCreateNotificationFormRejectsInternalCallbackUrl(t *testing.T) {
+	tests := []struct {
+		callbackUrl string
+		wantErr     bool
+	}{
+		{"https://example.com/hooks", false},
+		{"http://169.254.169.254/latest/meta-data/", true},
+		{"http://127.0.0.1:8080/hooks", true},
+		{"http://10.0.0.5/hooks", true},
+		{"http://localhost/hooks", true},
+	}
+	for _, tt := range tests {
+		form := CreateNotificationForm{Recipient: "+905555555555", Channel: "sms", Content: "hi", Priority: "low", CallbackUrl: tt.callbackUrl}
+		err := form.Validate(context.Background())
+		if tt.wantErr != errors.Is(err, httpx.ErrNonPublicAddress) {
+			t.Errorf("Validate(callback_url=%q) = %v, wantErr %v", tt.callbackUrl, err, tt.wantErr)
+		}
+	}
+}
+
+func TestCreateNotificationBatchFormRejectsInternalCallbackUrl(t *testing.T) {
+	item := CreateNotificationForm{Recipient: "+905555555555", Channel: "sms", Content: "hi", Priority: "low"}
+
+	batch := CreateNotificationBatchForm{Data: []CreateNotificationForm{item}, CallbackUrl: "http://169.254.169.254/"}
+	if err := batch.Validate(context.Background()); !errors.Is(err, httpx.ErrNonPublicAddress) {
+		t.Errorf("Validate(top-level callback_url) = %v, want ErrNonPublicAddress", err)
+	}
+
+	item.CallbackUrl = "http://192.168.1.10/hooks"
+	batch = CreateNotificationBatchForm{Data: []CreateNotificationForm{item}}
+	if err := batch.Validate(context.Background()); !errors.Is(err, httpx.ErrNonPublicAddress) {
+		t.Errorf("Validate(item callback_url) = %v, want ErrNonPublicAddress", err)
+	}
+}
@@ -0,0 +1,39 @@
+package serializers
+
+import (
+	"context"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+type PreferenceForm struct {
+	Recipient       string          `json:"recipient" validate:"required,max=512"`
+	Channel         string          `json:"channel" validate:"required,oneof=sms email push"`
+	OptedOut        bool            `json:"opted_out"`
+	QuietHoursStart string          `json:"quiet_hours_start,omitempty" validate:"omitempty,datetime=15:04,required_with=QuietHoursEnd"`
+	QuietHoursEnd   string          `json:"quiet_hours_end,omitempty" validate:"omitempty,datetime=15:04,required_with=QuietHoursStart"`
+	Timezone        string          `json:"timezone,omitempty" validate:"omitempty,timezone"`
+	Categories      map[string]bool `json:"categories,omitempty" validate:"omitempty,dive,keys,min=1,max=50,endkeys"`
+}
+
+func (s *PreferenceForm) Validate(ctx context.Context) error {
+	validate := validator.New()
+	s.Channel = strings.ToLower(s.Channel)
+	err := validate.StructCtx(ctx, s)
+
+	return err
+}
+
+type PreferenceQueryForm struct {
+	Recipient string `form:"recipient" validate:"required,max=512"`
+	Channel   string `form:"channel" validate:"omitempty,oneof=sms email push"`
+}
+
+func (s *PreferenceQueryForm) Validate(ctx context.Context) error {
+	validate := validator.New()
+	s.Channel = strings.ToLower(s.Channel)
+	err := validate.StructCtx(ctx, s)
+
+	return err
+}
@@ -0,0 +1,18 @@
+package serializers
+
+import (
+	"context"
+
+	"github.com/go-playground/validator/v10"
+)
+
+type ReceiptProviderForm struct {
+	Provider string `uri:"provider" validate:"required,alphanum,max=20"`
+}
+
+func (s *ReceiptProviderForm) Validate(ctx context.Context) error {
+	validate := validator.New()
+	err := validate.StructCtx(ctx, s)
+
+	return err
+}
@@ -0,0 +1,65 @@
+package serializers
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+type CreateTemplateForm struct {
+	Name    string `json:"name" validate:"required,max=100"`
+	Channel string `json:"channel" validate:"required,oneof=sms email push"`
+	Locale  string `json:"locale" validate:"required,bcp47_language_tag"`
+	Subject string `json:"subject,omitempty"`
+	Body    string `json:"body" validate:"required"`
+}
+
+func (s *CreateTemplateForm) Validate(ctx context.Context) error {
+	validate := validator.New()
+	s.Channel = strings.ToLower(s.Channel)
+	err := validate.StructCtx(ctx, s)
+
+	return err
+}
+
+type UpdateTemplateForm struct {
+	Locale  string `json:"locale" validate:"required,bcp47_language_tag"`
+	Subject string `json:"subject,omitempty"`
+	Body    string `json:"body" validate:"required"`
+}
+
+func (s *UpdateTemplateForm) Validate(ctx context.Context) error {
+	validate := validator.New()
+	err := validate.StructCtx(ctx, s)
+
+	return err
+}
+
+type TemplateIdForm struct {
+	Id string `uri:"id" validate:"required,uuid"`
+}
+
+func (s *TemplateIdForm) Validate(ctx context.Context) error {
+	validate := validator.New()
+	err := validate.StructCtx(ctx, s)
+
+	return err
+}
+
+type TemplateListForm struct {
+	PageStr string `form:"page"`
+	Channel string `form:"channel"`
+	Page    int
+}
+
+func (s *TemplateListForm) Validate(ctx context.Context) error {
+	s.Page = 1
+	if page, err := strconv.Atoi(s.PageStr); err == nil && page > 0 {
+		s.Page = page
+	}
+	s.Channel = strings.ToLower(s.Channel)
+
+	return nil
+}
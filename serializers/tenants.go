@@ -0,0 +1,88 @@
+package serializers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/HuseyinAsik/Notifications/pkg/httpx"
+	"github.com/go-playground/validator/v10"
+)
+
+type CreateTenantForm struct {
+	Name string `json:"name" validate:"required,max=100"`
+}
+
+func (s *CreateTenantForm) Validate(ctx context.Context) error {
+	validate := validator.New()
+	err := validate.StructCtx(ctx, s)
+
+	return err
+}
+
+type TenantListForm struct {
+	PageStr string `form:"page"`
+	Page    int
+}
+
+func (s *TenantListForm) Validate(ctx context.Context) error {
+	s.Page = 1
+	if page, err := strconv.Atoi(s.PageStr); err == nil && page > 0 {
+		s.Page = page
+	}
+
+	return nil
+}
+
+type TenantIdForm struct {
+	Id string `uri:"id" validate:"required,uuid"`
+}
+
+func (s *TenantIdForm) Validate(ctx context.Context) error {
+	validate := validator.New()
+	err := validate.StructCtx(ctx, s)
+
+	return err
+}
+
+type CreateApiKeyForm struct {
+	Name string `json:"name" validate:"required,max=100"`
+}
+
+func (s *CreateApiKeyForm) Validate(ctx context.Context) error {
+	validate := validator.New()
+	err := validate.StructCtx(ctx, s)
+
+	return err
+}
+
+type ApiKeyIdForm struct {
+	Id string `uri:"id" validate:"required,uuid"`
+}
+
+func (s *ApiKeyIdForm) Validate(ctx context.Context) error {
+	validate := validator.New()
+	err := validate.StructCtx(ctx, s)
+
+	return err
+}
+
+type UpdateWebhookForm struct {
+	Url          string `json:"url" validate:"omitempty,http_url"`
+	RotateSecret bool   `json:"rotate_secret"`
+}
+
+func (s *UpdateWebhookForm) Validate(ctx context.Context) error {
+	validate := validator.New()
+	if err := validate.StructCtx(ctx, s); err != nil {
+		return err
+	}
+	if s.Url == "" {
+		return nil
+	}
+	if err := httpx.CheckPublicUrl(s.Url); err != nil {
+		return fmt.Errorf("url: %w", err)
+	}
+
+	return nil
+}
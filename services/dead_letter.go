@@ -0,0 +1,58 @@
+package services
+
+import (
+	"context"
+	"slices"
+
+	"github.com/HuseyinAsik/Notifications/models"
+	"github.com/HuseyinAsik/Notifications/pkg/logging"
+	"github.com/HuseyinAsik/Notifications/repository"
+	"github.com/HuseyinAsik/Notifications/serializers"
+	"go.uber.org/zap"
+)
+
+type DeadLetterService struct {
+	DeadLetterRepo repository.DeadLetterRepository
+	Logger         *logging.LogWrapper
+}
+
+func NewDeadLetterService(deadLetterRepo repository.DeadLetterRepository, logger *logging.LogWrapper) *DeadLetterService {
+	return &DeadLetterService{
+		DeadLetterRepo: deadLetterRepo,
+		Logger:         logger,
+	}
+}
+
+func (s *DeadLetterService) List(ctx context.Context, listForm serializers.DeadLetterListForm) ([]models.DeadLetter, int, error) {
+	offset := (listForm.Page - 1) * pageLimit
+
+	deadLetters, total, err := s.DeadLetterRepo.List(ctx, listForm.TenantId, listForm.Channel, pageLimit, offset)
+
+	if err != nil {
+		s.Logger.Error(ctx, "DeadLetter List Err", zap.Error(err))
+	}
+
+	return deadLetters, total, err
+}
+
+// Replay sends the selected dead letters back through the outbox and reports which
+// ones could not be replayed.
+func (s *DeadLetterService) Replay(ctx context.Context, form serializers.ReplayDeadLettersForm) ([]string, []string, error) {
+	replayed, err := s.DeadLetterRepo.Replay(ctx, form.Ids)
+	if err != nil {
+		s.Logger.Error(ctx, "DeadLetter Replay Err", zap.Error(err))
+		return nil, nil, err
+	}
+
+	skipped := []string{}
+	for _, id := range form.Ids {
+		if !slices.Contains(replayed, id) {
+			skipped = append(skipped, id)
+		}
+	}
+	if replayed == nil {
+		replayed = []string{}
+	}
+
+	return replayed, skipped, nil
+}
@@ -0,0 +1,33 @@
+package services
+
+import (
+	"context"
+
+	"github.com/HuseyinAsik/Notifications/pkg/logging"
+	"github.com/HuseyinAsik/Notifications/repository"
+	"github.com/HuseyinAsik/Notifications/serializers"
+	"go.uber.org/zap"
+)
+
+type DeviceTokenService struct {
+	DeviceTokenRepo repository.DeviceTokenRepository
+	Logger          *logging.LogWrapper
+}
+
+func NewDeviceTokenService(deviceTokenRepo repository.DeviceTokenRepository, logger *logging.LogWrapper) *DeviceTokenService {
+	return &DeviceTokenService{
+		DeviceTokenRepo: deviceTokenRepo,
+		Logger:          logger,
+	}
+}
+
+// Register records that the app registered the token again, so a token FCM or APNs
+// reported as unregistered is sent to once more.
+func (s *DeviceTokenService) Register(ctx context.Context, tenantId string, form serializers.DeviceTokenForm) error {
+	err := s.DeviceTokenRepo.Register(ctx, tenantId, form.Token)
+	if err != nil {
+		s.Logger.Error(ctx, "DeviceToken Register Err", zap.Error(err))
+	}
+
+	return err
+}
@@ -0,0 +1,30 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/HuseyinAsik/Notifications/repository"
+)
+
+var (
+	ErrScheduleHorizonExceeded = errors.New("scheduled_at exceeds the maximum schedule horizon")
+	ErrNotificationNotFound    = repository.ErrNotFound
+	ErrNotificationNotPending  = errors.New("notification is no longer pending or scheduled")
+
+	ErrTemplateNotFound         = errors.New("template not found")
+	ErrTemplateChannelMismatch  = errors.New("template channel does not match the notification channel")
+	ErrInvalidTemplate          = errors.New("invalid template")
+	ErrTemplateVariablesMissing = errors.New("missing template variables")
+
+	ErrPreferenceNotFound = errors.New("recipient preference not found")
+
+	ErrTenantNotFound = errors.New("tenant not found")
+	ErrApiKeyNotFound = errors.New("api key not found")
+	ErrInvalidApiKey  = errors.New("invalid api key")
+
+	ErrReceiptProviderNotFound = errors.New("receipt provider not found")
+	ErrInvalidReceipt          = errors.New("invalid receipt")
+
+	ErrIdempotencyKeyReused     = errors.New("Idempotency-Key was already used with a different request body")
+	ErrIdempotencyKeyInProgress = errors.New("a request with this Idempotency-Key is still in progress")
+)
@@ -0,0 +1,91 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/HuseyinAsik/Notifications/models"
+	"github.com/HuseyinAsik/Notifications/pkg/logging"
+	"github.com/HuseyinAsik/Notifications/repository"
+	"go.uber.org/zap"
+)
+
+type IdempotencyService struct {
+	IdempotencyRepo repository.IdempotencyRepository
+	Retention       time.Duration
+	LockTimeout     time.Duration
+	Logger          *logging.LogWrapper
+}
+
+func NewIdempotencyService(idempotencyRepo repository.IdempotencyRepository, retention, lockTimeout time.Duration, logger *logging.LogWrapper) *IdempotencyService {
+	return &IdempotencyService{
+		IdempotencyRepo: idempotencyRepo,
+		Retention:       retention,
+		LockTimeout:     lockTimeout,
+		Logger:          logger,
+	}
+}
+
+// Begin reserves key for a new request. When the key was already used it returns the stored
+// response to replay, or an error if the body differs or the first request is still running.
+// A request that has held the key longer than LockTimeout is considered lost and replaced.
+func (s *IdempotencyService) Begin(ctx context.Context, tenantId, key, endpoint, requestHash string) (*models.IdempotencyKey, error) {
+	now := time.Now().UTC()
+	reserved, err := s.IdempotencyRepo.Reserve(ctx, tenantId, key, endpoint, requestHash, now.Add(s.LockTimeout), now.Add(s.Retention))
+	if err != nil {
+		s.Logger.Error(ctx, "Idempotency Reserve Err", zap.Error(err), zap.String("key", key))
+		return nil, err
+	}
+	if reserved {
+		return nil, nil
+	}
+
+	existing, err := s.IdempotencyRepo.Find(ctx, tenantId, key, endpoint)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrIdempotencyKeyInProgress
+	}
+	if err != nil {
+		s.Logger.Error(ctx, "Idempotency Find Err", zap.Error(err), zap.String("key", key))
+		return nil, err
+	}
+
+	if existing.RequestHash != requestHash {
+		return nil, ErrIdempotencyKeyReused
+	}
+	if existing.StatusCode == 0 {
+		return nil, ErrIdempotencyKeyInProgress
+	}
+
+	return existing, nil
+}
+
+func (s *IdempotencyService) Complete(ctx context.Context, tenantId, key, endpoint string, statusCode int, response []byte) {
+	if err := s.IdempotencyRepo.Complete(ctx, tenantId, key, endpoint, statusCode, response); err != nil {
+		s.Logger.Error(ctx, "Idempotency Complete Err", zap.Error(err), zap.String("key", key))
+	}
+}
+
+// Release frees a key whose request did not produce a response worth replaying, so the
+// client can retry with the same key.
+func (s *IdempotencyService) Release(ctx context.Context, tenantId, key, endpoint string) {
+	if err := s.IdempotencyRepo.Release(ctx, tenantId, key, endpoint); err != nil {
+		s.Logger.Error(ctx, "Idempotency Release Err", zap.Error(err), zap.String("key", key))
+	}
+}
+
+func (s *IdempotencyService) RunPurge(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.IdempotencyRepo.DeleteExpired(ctx, time.Now().UTC()); err != nil {
+				s.Logger.Error(ctx, "Idempotency DeleteExpired Err", zap.Error(err))
+			}
+		}
+	}
+}
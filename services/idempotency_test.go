@@ -0,0 +1,188 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/HuseyinAsik/Notifications/models"
+	"github.com/HuseyinAsik/Notifications/pkg/logging"
+	"github.com/HuseyinAsik/Notifications/repository"
+)
+
+// memoryIdempotencyRepo keeps keys in memory with the takeover rules of the PostgreSQL
+// repository.
+type memoryIdempotencyRepo struct {
+	mu          sync.Mutex
+	keys        map[string]*models.IdempotencyKey
+	lockedUntil map[string]time.Time
+}
+
+func newMemoryIdempotencyRepo() *memoryIdempotencyRepo {
+	return &memoryIdempotencyRepo{keys: map[string]*models.IdempotencyKey{}, lockedUntil: map[string]time.Time{}}
+}
+
+func (r *memoryIdempotencyRepo) Reserve(ctx context.Context, tenantId, key, endpoint, requestHash string, lockedUntil, expiresAt time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	id := tenantId + " " + endpoint + " " + key
+	now := time.Now()
+	if existing, ok := r.keys[id]; ok {
+		lockExpired := existing.StatusCode == 0 && !r.lockedUntil[id].After(now)
+		if existing.ExpiresAt.After(now) && !lockExpired {
+			return false, nil
+		}
+	}
+	r.keys[id] = &models.IdempotencyKey{Key: key, Endpoint: endpoint, RequestHash: requestHash, CreatedAt: now, ExpiresAt: expiresAt}
+	r.lockedUntil[id] = lockedUntil
+
+	return true, nil
+}
+
+func (r *memoryIdempotencyRepo) Find(ctx context.Context, tenantId, key, endpoint string) (*models.IdempotencyKey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, ok := r.keys[tenantId+" "+endpoint+" "+key]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	found := *existing
+
+	return &found, nil
+}
+
+func (r *memoryIdempotencyRepo) Complete(ctx context.Context, tenantId, key, endpoint string, statusCode int, response []byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if existing, ok := r.keys[tenantId+" "+endpoint+" "+key]; ok {
+		existing.StatusCode, existing.Response = statusCode, response
+	}
+
+	return nil
+}
+
+func (r *memoryIdempotencyRepo) Release(ctx context.Context, tenantId, key, endpoint string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	id := tenantId + " " + endpoint + " " + key
+	if existing, ok := r.keys[id]; ok && existing.StatusCode == 0 {
+		delete(r.keys, id)
+	}
+
+	return nil
+}
+
+func (r *memoryIdempotencyRepo) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	return 0, nil
+}
+
+func newTestIdempotencyService(lockTimeout time.Duration) *IdempotencyService {
+	return NewIdempotencyService(newMemoryIdempotencyRepo(), time.Hour, lockTimeout, &logging.LogWrapper{ZapLogger: zap.NewNop()})
+}
+
+func TestIdempotencyServiceBegin(t *testing.T) {
+	const endpoint = "POST /api/v1/notifications"
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		// setup runs before the second Begin with key k-1 and hash h-1.
+		setup   func(s *IdempotencyService)
+		want    *models.IdempotencyKey
+		wantErr error
+	}{
+		{
+			name:    "in progress",
+			setup:   func(s *IdempotencyService) {},
+			wantErr: ErrIdempotencyKeyInProgress,
+		},
+		{
+			name: "replay",
+			setup: func(s *IdempotencyService) {
+				s.Complete(ctx, "t-1", "k-1", endpoint, 202, []byte(`{"messageId":"n-1"}`))
+			},
+			want: &models.IdempotencyKey{StatusCode: 202, Response: []byte(`{"messageId":"n-1"}`)},
+		},
+		{
+			name: "released",
+			setup: func(s *IdempotencyService) {
+				s.Release(ctx, "t-1", "k-1", endpoint)
+			},
+		},
+		{
+			name: "completed keys are not released",
+			setup: func(s *IdempotencyService) {
+				s.Complete(ctx, "t-1", "k-1", endpoint, 202, []byte(`{}`))
+				s.Release(ctx, "t-1", "k-1", endpoint)
+			},
+			want: &models.IdempotencyKey{StatusCode: 202, Response: []byte(`{}`)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestIdempotencyService(time.Minute)
+			if existing, err := s.Begin(ctx, "t-1", "k-1", endpoint, "h-1"); existing != nil || err != nil {
+				t.Fatalf("first Begin = %+v, %v, want a reservation", existing, err)
+			}
+			tt.setup(s)
+
+			got, err := s.Begin(ctx, "t-1", "k-1", endpoint, "h-1")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Begin err = %v, want %v", err, tt.wantErr)
+			}
+			if tt.want == nil {
+				if got != nil {
+					t.Errorf("Begin = %+v, want a new reservation", got)
+				}
+				return
+			}
+			if got == nil || got.StatusCode != tt.want.StatusCode || string(got.Response) != string(tt.want.Response) {
+				t.Errorf("Begin = %+v, want the stored %d response", got, tt.want.StatusCode)
+			}
+		})
+	}
+}
+
+func TestIdempotencyServiceBeginDifferentBody(t *testing.T) {
+	ctx := context.Background()
+	s := newTestIdempotencyService(time.Minute)
+
+	if _, err := s.Begin(ctx, "t-1", "k-1", "POST /x", "h-1"); err != nil {
+		t.Fatalf("first Begin: %v", err)
+	}
+	s.Complete(ctx, "t-1", "k-1", "POST /x", 202, []byte(`{}`))
+
+	if _, err := s.Begin(ctx, "t-1", "k-1", "POST /x", "h-2"); !errors.Is(err, ErrIdempotencyKeyReused) {
+		t.Errorf("Begin with another body = %v, want ErrIdempotencyKeyReused", err)
+	}
+	// Keys are scoped to the tenant and the endpoint.
+	if existing, err := s.Begin(ctx, "t-2", "k-1", "POST /x", "h-2"); existing != nil || err != nil {
+		t.Errorf("Begin for another tenant = %+v, %v, want a reservation", existing, err)
+	}
+	if existing, err := s.Begin(ctx, "t-1", "k-1", "POST /y", "h-2"); existing != nil || err != nil {
+		t.Errorf("Begin on another endpoint = %+v, %v, want a reservation", existing, err)
+	}
+}
+
+func TestIdempotencyServiceBeginTakesOverExpiredLock(t *testing.T) {
+	ctx := context.Background()
+	s := newTestIdempotencyService(time.Millisecond)
+
+	if _, err := s.Begin(ctx, "t-1", "k-1", "POST /x", "h-1"); err != nil {
+		t.Fatalf("first Begin: %v", err)
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	if existing, err := s.Begin(ctx, "t-1", "k-1", "POST /x", "h-1"); existing != nil || err != nil {
+		t.Errorf("Begin after the lock ran out = %+v, %v, want a reservation", existing, err)
+	}
+}
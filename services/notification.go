@@ -3,11 +3,14 @@ package services
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/HuseyinAsik/Notifications/models"
 	"github.com/HuseyinAsik/Notifications/pkg/logging"
+	"github.com/HuseyinAsik/Notifications/pkg/timeline"
+	"github.com/HuseyinAsik/Notifications/pkg/tracing"
 	"github.com/HuseyinAsik/Notifications/repository"
 	"github.com/HuseyinAsik/Notifications/serializers"
 	"github.com/google/uuid"
@@ -19,65 +22,134 @@ const (
 )
 
 type NotificationService struct {
-	NotificationRepo repository.NotificationRepository
-	Logger           *logging.LogWrapper
+	NotificationRepo   repository.NotificationRepository
+	PreferenceRepo     repository.PreferenceRepository
+	EventRepo          repository.NotificationEventRepository
+	Templates          *TemplateService
+	Timeline           *timeline.Recorder
+	MaxScheduleHorizon time.Duration
+	Logger             *logging.LogWrapper
 }
 
-func NewNotificationService(notificationRepo repository.NotificationRepository, logger *logging.LogWrapper) *NotificationService {
+func NewNotificationService(notificationRepo repository.NotificationRepository, preferenceRepo repository.PreferenceRepository, eventRepo repository.NotificationEventRepository, templates *TemplateService, timeline *timeline.Recorder, maxScheduleHorizon time.Duration, logger *logging.LogWrapper) *NotificationService {
 	return &NotificationService{
-		NotificationRepo: notificationRepo,
-		Logger:           logger}
+		NotificationRepo:   notificationRepo,
+		PreferenceRepo:     preferenceRepo,
+		EventRepo:          eventRepo,
+		Templates:          templates,
+		Timeline:           timeline,
+		MaxScheduleHorizon: maxScheduleHorizon,
+		Logger:             logger}
 }
 
 func (s *NotificationService) Create(
 	ctx context.Context,
+	tenantId string,
 	form *serializers.CreateNotificationForm,
 ) (string, error) {
+	if err := s.checkHorizon(form.ScheduledAt); err != nil {
+		return "", err
+	}
+
 	Id := uuid.NewString()
 	notification := models.Notification{
 		Id:          Id,
+		TenantId:    tenantId,
 		GroupId:     Id,
 		Recipient:   form.Recipient,
 		Channel:     form.Channel,
 		Content:     form.Content,
 		Status:      "pending",
 		Priority:    form.Priority,
+		Category:    form.Category,
 		ScheduledAt: form.ScheduledAt,
+		Timezone:    form.Timezone,
+		CallbackUrl: form.CallbackUrl,
+		RequestId:   logging.RequestId(ctx),
+		OrderingKey: form.OrderingKey,
+
+		QuietHoursStart: form.QuietHoursStart,
+		QuietHoursEnd:   form.QuietHoursEnd,
+	}
+	if err := s.applyTemplate(ctx, &notification, form, nil); err != nil {
+		return "", err
+	}
+
+	notifications := []models.Notification{notification}
+	if err := s.applyPreferences(ctx, tenantId, notifications); err != nil {
+		return "", err
+	}
+	notification = notifications[0]
+
+	var event *models.OutboxEvent
+	if notification.Status != "suppressed" {
+		event = CreateEvent(ctx, notification)
+		if event == nil {
+			notification.Status = "scheduled"
+		}
 	}
-	event := CreateEvent(notification)
 	err := s.NotificationRepo.Create(ctx, notification, event)
 
 	if err != nil {
 		s.Logger.Error(ctx, "Notification Create Err", zap.Error(err))
 		return "", err
 	}
+	s.Timeline.Record(ctx, acceptedEvent(notification))
 
 	return Id, nil
 }
 
-func (s *NotificationService) BulkCreate(ctx context.Context, batchForm serializers.CreateNotificationBatchForm) (string, error) {
+func (s *NotificationService) BulkCreate(ctx context.Context, tenantId string, batchForm serializers.CreateNotificationBatchForm) (string, error) {
 	var notifications []models.Notification
 	var events []*models.OutboxEvent
 
 	groupId := uuid.NewString()
 	now := time.Now()
-	for _, data := range batchForm.Data {
+	templates := map[string]*models.Template{}
+	for i, data := range batchForm.Data {
+		if err := s.checkHorizon(data.ScheduledAt); err != nil {
+			return "", err
+		}
+
 		notification := models.Notification{
 			Id:          uuid.NewString(),
+			TenantId:    tenantId,
 			GroupId:     groupId,
 			Recipient:   data.Recipient,
 			Channel:     data.Channel,
 			Content:     data.Content,
 			Status:      "pending",
 			Priority:    data.Priority,
+			Category:    data.Category,
 			ScheduledAt: data.ScheduledAt,
+			Timezone:    data.Timezone,
+			CallbackUrl: data.CallbackUrl,
+			RequestId:   logging.RequestId(ctx),
+			OrderingKey: data.OrderingKey,
 			CreatedAt:   now,
+
+			QuietHoursStart: data.QuietHoursStart,
+			QuietHoursEnd:   data.QuietHoursEnd,
+		}
+		if err := s.applyTemplate(ctx, &notification, &batchForm.Data[i], templates); err != nil {
+			return "", fmt.Errorf("data[%d]: %w", i, err)
 		}
 		notifications = append(notifications, notification)
+	}
 
-		event := CreateEvent(notification)
+	if err := s.applyPreferences(ctx, tenantId, notifications); err != nil {
+		return "", err
+	}
+
+	for i := range notifications {
+		if notifications[i].Status == "suppressed" {
+			continue
+		}
+		event := CreateEvent(ctx, notifications[i])
 		if event != nil {
 			events = append(events, event)
+		} else {
+			notifications[i].Status = "scheduled"
 		}
 	}
 
@@ -88,13 +160,19 @@ func (s *NotificationService) BulkCreate(ctx context.Context, batchForm serializ
 		return "", err
 	}
 
+	accepted := make([]models.NotificationEvent, 0, len(notifications))
+	for _, n := range notifications {
+		accepted = append(accepted, acceptedEvent(n))
+	}
+	s.Timeline.Record(ctx, accepted...)
+
 	return groupId, nil
 }
 
-func (s *NotificationService) List(ctx context.Context, listForm serializers.ListForm) ([]models.Notification, int, error) {
+func (s *NotificationService) List(ctx context.Context, tenantId string, listForm serializers.ListForm) ([]models.Notification, int, error) {
 	offset := (listForm.Page - 1) * pageLimit
 
-	notifications, total, err := s.NotificationRepo.ListNotifications(ctx, listForm.Status, listForm.Channel, listForm.StartDate, listForm.EndDate, pageLimit, offset)
+	notifications, total, err := s.NotificationRepo.ListNotifications(ctx, tenantId, listForm.Status, listForm.Channel, listForm.StartDate, listForm.EndDate, pageLimit, offset)
 
 	if err != nil {
 		s.Logger.Error(ctx, "Notification List Err", zap.Error(err))
@@ -103,11 +181,221 @@ func (s *NotificationService) List(ctx context.Context, listForm serializers.Lis
 	return notifications, total, err
 }
 
+func (s *NotificationService) Get(ctx context.Context, tenantId, id string) (*models.Notification, *models.OutboxEvent, error) {
+	notification, err := s.NotificationRepo.FindById(ctx, tenantId, id)
+	if err != nil {
+		if !errors.Is(err, repository.ErrNotFound) {
+			s.Logger.Error(ctx, "Notification FindById Err", zap.Error(err), zap.String("id", id))
+		}
+		return nil, nil, err
+	}
+
+	event, err := s.NotificationRepo.FetchOutboxEventByAggregateId(ctx, id)
+	if err != nil {
+		s.Logger.Error(ctx, "Notification FetchOutboxEventByAggregateId Err", zap.Error(err), zap.String("id", id))
+		return nil, nil, err
+	}
+
+	return notification, event, nil
+}
+
+func (s *NotificationService) GroupStatus(ctx context.Context, tenantId string, form serializers.GroupStatusForm) (map[string]int, []models.Notification, int, error) {
+	counts, err := s.NotificationRepo.GroupStatusCounts(ctx, tenantId, form.GroupId)
+	if err != nil {
+		s.Logger.Error(ctx, "Notification GroupStatusCounts Err", zap.Error(err), zap.String("groupId", form.GroupId))
+		return nil, nil, 0, err
+	}
+
+	total := 0
+	for _, count := range counts {
+		total += count
+	}
+	if total == 0 {
+		return nil, nil, 0, ErrNotificationNotFound
+	}
+
+	offset := (form.Page - 1) * pageLimit
+	notifications, err := s.NotificationRepo.ListGroup(ctx, tenantId, form.GroupId, pageLimit, offset)
+	if err != nil {
+		s.Logger.Error(ctx, "Notification ListGroup Err", zap.Error(err), zap.String("groupId", form.GroupId))
+		return nil, nil, 0, err
+	}
+
+	return counts, notifications, total, nil
+}
+
+func (s *NotificationService) Cancel(ctx context.Context, tenantId, id string) error {
+	err := s.NotificationRepo.CancelNotification(ctx, tenantId, id)
+
+	if errors.Is(err, repository.ErrNotModifiable) {
+		return ErrNotificationNotPending
+	}
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		s.Logger.Error(ctx, "Notification Cancel Err", zap.Error(err), zap.String("id", id))
+	}
+	if err == nil {
+		s.Timeline.Record(ctx, models.NotificationEvent{TenantId: tenantId, NotificationId: id, Status: "cancelled"})
+	}
+
+	return err
+}
+
+func (s *NotificationService) Reschedule(ctx context.Context, tenantId, id string, form serializers.RescheduleForm) error {
+	if err := s.checkHorizon(form.ScheduledAt); err != nil {
+		return err
+	}
+
+	err := s.NotificationRepo.RescheduleNotification(ctx, tenantId, id, *form.ScheduledAt, form.Timezone)
+
+	if errors.Is(err, repository.ErrNotModifiable) {
+		return ErrNotificationNotPending
+	}
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		s.Logger.Error(ctx, "Notification Reschedule Err", zap.Error(err), zap.String("id", id))
+	}
+	if err == nil {
+		s.Timeline.Record(ctx, models.NotificationEvent{
+			TenantId:       tenantId,
+			NotificationId: id,
+			Status:         "scheduled",
+			Reason:         "rescheduled",
+			NextAttemptAt:  form.ScheduledAt,
+		})
+	}
+
+	return err
+}
+
+// Events returns the status transitions of the notification, oldest first.
+func (s *NotificationService) Events(ctx context.Context, tenantId, id string) ([]models.NotificationEvent, error) {
+	if _, err := s.NotificationRepo.FindById(ctx, tenantId, id); err != nil {
+		if !errors.Is(err, repository.ErrNotFound) {
+			s.Logger.Error(ctx, "Notification FindById Err", zap.Error(err), zap.String("id", id))
+		}
+		return nil, err
+	}
+
+	events, err := s.EventRepo.List(ctx, tenantId, id)
+	if err != nil {
+		s.Logger.Error(ctx, "Notification Events Err", zap.Error(err), zap.String("id", id))
+	}
+
+	return events, err
+}
+
+// acceptedEvent is the first entry of a notification's timeline: pending, scheduled or
+// suppressed by the recipient's preferences.
+func acceptedEvent(n models.Notification) models.NotificationEvent {
+	event := models.NotificationEvent{
+		TenantId:       n.TenantId,
+		NotificationId: n.Id,
+		Status:         n.Status,
+		Reason:         n.SuppressionReason,
+	}
+	if n.Status == "scheduled" {
+		event.NextAttemptAt = n.ScheduledAt
+	}
+
+	return event
+}
+
+// applyPreferences marks notifications whose recipient opted out of the channel or
+// category as suppressed, so they are stored without an outbox event.
+func (s *NotificationService) applyPreferences(ctx context.Context, tenantId string, notifications []models.Notification) error {
+	recipients := make([]string, 0, len(notifications))
+	channels := make([]string, 0, len(notifications))
+	for _, n := range notifications {
+		recipients = append(recipients, n.Recipient)
+		channels = append(channels, n.Channel)
+	}
+
+	preferences, err := s.PreferenceRepo.FindMany(ctx, tenantId, recipients, channels)
+	if err != nil {
+		s.Logger.Error(ctx, "Notification FindMany preferences Err", zap.Error(err))
+		return err
+	}
+	if len(preferences) == 0 {
+		return nil
+	}
+
+	byRecipient := make(map[[2]string]*models.RecipientPreference, len(preferences))
+	for i := range preferences {
+		byRecipient[[2]string{preferences[i].Recipient, preferences[i].Channel}] = &preferences[i]
+	}
+
+	for i := range notifications {
+		preference := byRecipient[[2]string{notifications[i].Recipient, notifications[i].Channel}]
+		if reason := preference.SuppressionReason(notifications[i].Category); reason != "" {
+			notifications[i].Status = "suppressed"
+			notifications[i].SuppressionReason = reason
+		}
+	}
+
+	return nil
+}
+
+// applyTemplate renders the form's template into the notification content. Resolved
+// templates are kept in cache, when given, so a batch looks each one up only once.
+func (s *NotificationService) applyTemplate(ctx context.Context, notification *models.Notification, form *serializers.CreateNotificationForm, cache map[string]*models.Template) error {
+	if form.TemplateId == "" {
+		return nil
+	}
+
+	key := form.TemplateId + "|" + form.Locale
+	template, ok := cache[key]
+	if !ok {
+		resolved, err := s.Templates.Resolve(ctx, notification.TenantId, form.TemplateId, form.Locale)
+		if err != nil {
+			return err
+		}
+		template = resolved
+		if cache != nil {
+			cache[key] = template
+		}
+	}
+
+	if template.Channel != notification.Channel {
+		return ErrTemplateChannelMismatch
+	}
+
+	content, err := Render(template, form.Variables)
+	if err != nil {
+		return err
+	}
+
+	notification.Content = content
+	notification.TemplateId = template.Id
+	notification.TemplateVersion = template.Version
+	notification.Locale = template.Locale
+
+	return nil
+}
+
+func (s *NotificationService) checkHorizon(scheduledAt *time.Time) error {
+	if scheduledAt != nil && s.MaxScheduleHorizon > 0 && scheduledAt.After(time.Now().Add(s.MaxScheduleHorizon)) {
+		return ErrScheduleHorizonExceeded
+	}
+	return nil
+}
+
 func BuildTopic(notificationType, priority string) string {
 	return fmt.Sprintf("%s_%s", notificationType, priority)
 }
 
-func CreateEvent(notification models.Notification) *models.OutboxEvent {
+// MessageKey returns the Kafka key of the notification: the client's ordering key, or the
+// recipient, within the tenant. Notifications with the same key are delivered in order.
+func MessageKey(notification models.Notification) string {
+	key := notification.OrderingKey
+	if key == "" {
+		key = notification.Recipient
+	}
+
+	return notification.TenantId + ":" + key
+}
+
+// CreateEvent builds the outbox event publishing the notification, carrying the trace of ctx.
+// It returns nil for notifications scheduled in the future.
+func CreateEvent(ctx context.Context, notification models.Notification) *models.OutboxEvent {
 	now := time.Now()
 	if notification.ScheduledAt != nil && notification.ScheduledAt.After(now) {
 		return nil
@@ -120,11 +408,16 @@ func CreateEvent(notification models.Notification) *models.OutboxEvent {
 	event := &models.OutboxEvent{
 		Id:          uuid.NewString(),
 		AggregateId: notification.Id,
+		TenantId:    notification.TenantId,
 		GroupId:     notification.GroupId,
 		EventType:   "NotificationCreated",
 		Topic:       topic,
 		Payload:     payload,
+		MessageKey:  MessageKey(notification),
 		CreatedAt:   now,
+
+		TraceContext: tracing.Inject(ctx),
+		RequestId:    notification.RequestId,
 	}
 
 	return event
@@ -4,8 +4,11 @@ import (
 	"context"
 	"time"
 
+	"github.com/HuseyinAsik/Notifications/models"
 	"github.com/HuseyinAsik/Notifications/pkg/kafka"
 	"github.com/HuseyinAsik/Notifications/pkg/logging"
+	"github.com/HuseyinAsik/Notifications/pkg/metrics"
+	"github.com/HuseyinAsik/Notifications/pkg/timeline"
 	"github.com/HuseyinAsik/Notifications/repository"
 	"go.uber.org/zap"
 )
@@ -13,6 +16,7 @@ import (
 type Outbox struct {
 	repo      repository.NotificationRepository
 	writer    *kafka.Writer
+	timeline  *timeline.Recorder
 	logger    *logging.LogWrapper
 	batchSize int
 }
@@ -20,11 +24,13 @@ type Outbox struct {
 func NewOutbox(
 	repo repository.NotificationRepository,
 	writer *kafka.Writer,
+	timeline *timeline.Recorder,
 	logger *logging.LogWrapper,
 ) *Outbox {
 	return &Outbox{
 		repo:      repo,
 		writer:    writer,
+		timeline:  timeline,
 		logger:    logger,
 		batchSize: 100,
 	}
@@ -34,20 +40,34 @@ func (p *Outbox) Run(ctx context.Context) {
 
 	ticker := time.NewTicker(200 * time.Millisecond)
 	defer ticker.Stop()
+	backlogTicker := time.NewTicker(10 * time.Second)
+	defer backlogTicker.Stop()
 
+	p.measureBacklog(ctx)
 	for {
 		select {
 		case <-ctx.Done():
 			return
 		case <-ticker.C:
 			p.process(ctx)
+		case <-backlogTicker.C:
+			p.measureBacklog(ctx)
 		}
 	}
 }
 
+func (p *Outbox) measureBacklog(ctx context.Context) {
+	count, err := p.repo.CountPendingOutbox(ctx)
+	if err != nil {
+		p.logger.Error(ctx, "Outbox CountPendingOutbox Err", zap.Error(err))
+		return
+	}
+	metrics.OutboxBacklog.Set(float64(count))
+}
+
 func (p *Outbox) process(ctx context.Context) {
 
-	events, err := p.repo.FetchPendingOutbox(ctx, p.batchSize)
+	events, err := p.repo.ClaimPendingOutbox(ctx, p.batchSize)
 	if err != nil {
 		p.logger.Error(ctx, "Outbox process Err", zap.Error(err))
 		return
@@ -59,21 +79,36 @@ func (p *Outbox) process(ctx context.Context) {
 
 	var messages []kafka.Message
 	var ids []string
+	var published []models.NotificationEvent
 
 	for _, e := range events {
+		var headers map[string]string
+		if e.RequestId != "" {
+			headers = map[string]string{kafka.RequestIdHeader: e.RequestId}
+		}
 		messages = append(messages, kafka.Message{
-			Topic: e.Topic,
-			Value: e.Payload,
+			Key:          []byte(e.MessageKey),
+			Topic:        e.Topic,
+			Value:        e.Payload,
+			Headers:      headers,
+			TraceContext: e.TraceContext,
 		})
 		ids = append(ids, e.Id)
+		published = append(published, models.NotificationEvent{
+			TenantId:       e.TenantId,
+			NotificationId: e.AggregateId,
+			Status:         "published",
+			Attempt:        e.RetryCount + 1,
+		})
 	}
 
-	if markPublishedErr := p.repo.MarkOutboxPublished(ctx, ids); markPublishedErr != nil {
-		p.logger.Error(ctx, "Outbox MarkOutboxPublished Err", zap.Error(markPublishedErr))
-		return
+	started := time.Now()
+	writeMessageErr := p.writer.WriteMessages(ctx, messages)
+	metrics.OutboxPublishDuration.Observe(time.Since(started).Seconds())
+	for _, e := range events {
+		metrics.OutboxPublished.WithLabelValues(e.Topic, metrics.Result(writeMessageErr)).Inc()
 	}
-
-	if writeMessageErr := p.writer.WriteMessages(ctx, messages); writeMessageErr != nil {
+	if writeMessageErr != nil {
 		p.logger.Error(ctx, "Outbox WriteMessages Err", zap.Error(writeMessageErr))
 
 		if markpendingErr := p.repo.MarkOutboxPending(ctx, ids); markpendingErr != nil {
@@ -82,4 +117,10 @@ func (p *Outbox) process(ctx context.Context) {
 		return
 	}
 
+	publishedAt := time.Now()
+	for _, e := range events {
+		metrics.OutboxPublishLag.Observe(publishedAt.Sub(e.CreatedAt).Seconds())
+	}
+	p.timeline.Record(ctx, published...)
+
 }
@@ -0,0 +1,77 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/HuseyinAsik/Notifications/models"
+	"github.com/HuseyinAsik/Notifications/pkg/logging"
+	"github.com/HuseyinAsik/Notifications/repository"
+	"github.com/HuseyinAsik/Notifications/serializers"
+	"go.uber.org/zap"
+)
+
+type PreferenceService struct {
+	PreferenceRepo repository.PreferenceRepository
+	Logger         *logging.LogWrapper
+}
+
+func NewPreferenceService(preferenceRepo repository.PreferenceRepository, logger *logging.LogWrapper) *PreferenceService {
+	return &PreferenceService{
+		PreferenceRepo: preferenceRepo,
+		Logger:         logger,
+	}
+}
+
+func (s *PreferenceService) Upsert(ctx context.Context, tenantId string, form serializers.PreferenceForm) (*models.RecipientPreference, error) {
+	preference := models.RecipientPreference{
+		TenantId:        tenantId,
+		Recipient:       form.Recipient,
+		Channel:         form.Channel,
+		OptedOut:        form.OptedOut,
+		QuietHoursStart: form.QuietHoursStart,
+		QuietHoursEnd:   form.QuietHoursEnd,
+		Timezone:        form.Timezone,
+		Categories:      form.Categories,
+	}
+
+	if err := s.PreferenceRepo.Upsert(ctx, preference); err != nil {
+		s.Logger.Error(ctx, "Preference Upsert Err", zap.Error(err))
+		return nil, err
+	}
+
+	return s.PreferenceRepo.Get(ctx, tenantId, form.Recipient, form.Channel)
+}
+
+func (s *PreferenceService) List(ctx context.Context, tenantId string, form serializers.PreferenceQueryForm) ([]models.RecipientPreference, error) {
+	if form.Channel != "" {
+		preference, err := s.PreferenceRepo.Get(ctx, tenantId, form.Recipient, form.Channel)
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, ErrPreferenceNotFound
+		}
+		if err != nil {
+			s.Logger.Error(ctx, "Preference Get Err", zap.Error(err))
+			return nil, err
+		}
+		return []models.RecipientPreference{*preference}, nil
+	}
+
+	preferences, err := s.PreferenceRepo.ListByRecipient(ctx, tenantId, form.Recipient)
+	if err != nil {
+		s.Logger.Error(ctx, "Preference ListByRecipient Err", zap.Error(err))
+	}
+
+	return preferences, err
+}
+
+func (s *PreferenceService) Delete(ctx context.Context, tenantId string, form serializers.PreferenceQueryForm) error {
+	err := s.PreferenceRepo.Delete(ctx, tenantId, form.Recipient, form.Channel)
+	if errors.Is(err, repository.ErrNotFound) {
+		return ErrPreferenceNotFound
+	}
+	if err != nil {
+		s.Logger.Error(ctx, "Preference Delete Err", zap.Error(err))
+	}
+
+	return err
+}
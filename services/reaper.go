@@ -0,0 +1,69 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/HuseyinAsik/Notifications/pkg/logging"
+	"github.com/HuseyinAsik/Notifications/repository"
+	"go.uber.org/zap"
+)
+
+// Reaper requeues outbox events left in processing by a worker that died or lost its claim,
+// so they are published again instead of staying in processing forever.
+type Reaper struct {
+	repo      repository.NotificationRepository
+	logger    *logging.LogWrapper
+	interval  time.Duration
+	lease     time.Duration
+	batchSize int
+}
+
+func NewReaper(
+	repo repository.NotificationRepository,
+	interval time.Duration,
+	lease time.Duration,
+	logger *logging.LogWrapper,
+) *Reaper {
+	return &Reaper{
+		repo:      repo,
+		logger:    logger,
+		interval:  interval,
+		lease:     lease,
+		batchSize: 100,
+	}
+}
+
+func (r *Reaper) Run(ctx context.Context) {
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.process(ctx)
+		}
+	}
+}
+
+// process requeues stale events batch by batch until none are left.
+func (r *Reaper) process(ctx context.Context) {
+
+	for ctx.Err() == nil {
+		requeued, err := r.repo.RequeueStaleOutbox(ctx, r.lease, r.batchSize)
+		if err != nil {
+			r.logger.Error(ctx, "Reaper RequeueStaleOutbox Err", zap.Error(err))
+			return
+		}
+		if requeued > 0 {
+			r.logger.Warn(ctx, "Reaper requeued stale processing events", zap.Int("count", requeued))
+		}
+
+		if requeued < r.batchSize {
+			return
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/HuseyinAsik/Notifications/models"
+	"github.com/HuseyinAsik/Notifications/pkg/logging"
+	"github.com/HuseyinAsik/Notifications/providers"
+	"github.com/HuseyinAsik/Notifications/repository"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type ReceiptService struct {
+	ReceiptRepo      repository.ReceiptRepository
+	NotificationRepo repository.NotificationRepository
+	Parsers          map[string]providers.ReceiptParser
+	Logger           *logging.LogWrapper
+}
+
+func NewReceiptService(
+	receiptRepo repository.ReceiptRepository,
+	notificationRepo repository.NotificationRepository,
+	parsers map[string]providers.ReceiptParser,
+	logger *logging.LogWrapper,
+) *ReceiptService {
+	return &ReceiptService{
+		ReceiptRepo:      receiptRepo,
+		NotificationRepo: notificationRepo,
+		Parsers:          parsers,
+		Logger:           logger,
+	}
+}
+
+// Handle verifies and records a delivery report callback of provider. It returns how many
+// receipts were recorded and how many did not match a sent notification.
+func (s *ReceiptService) Handle(ctx context.Context, provider string, header http.Header, body []byte) (int, int, error) {
+	parser, ok := s.Parsers[provider]
+	if !ok {
+		return 0, 0, ErrReceiptProviderNotFound
+	}
+
+	receipts, err := parser.Parse(header, body)
+	if errors.Is(err, providers.ErrInvalidSignature) {
+		s.Logger.Warn(ctx, "Receipt invalid signature", zap.String("provider", provider))
+		return 0, 0, err
+	}
+	if err != nil {
+		return 0, 0, errors.Join(ErrInvalidReceipt, err)
+	}
+
+	var recorded, unmatched int
+	for _, receipt := range receipts {
+		err := s.ReceiptRepo.Record(ctx, parser.Channel(), models.StatusHistory{
+			Id:                uuid.NewString(),
+			Status:            receipt.Status,
+			Provider:          provider,
+			ProviderMessageId: receipt.ProviderMessageId,
+			Reason:            receipt.Reason,
+			Payload:           receipt.Payload,
+			OccurredAt:        receipt.OccurredAt,
+		})
+		if errors.Is(err, repository.ErrNotFound) {
+			s.Logger.Warn(ctx, "Receipt unknown message", zap.String("provider", provider),
+				zap.String("providerMessageId", receipt.ProviderMessageId))
+			unmatched++
+			continue
+		}
+		if err != nil {
+			s.Logger.Error(ctx, "Receipt Record Err", zap.Error(err), zap.String("provider", provider),
+				zap.String("providerMessageId", receipt.ProviderMessageId))
+			return recorded, unmatched, err
+		}
+		recorded++
+	}
+
+	return recorded, unmatched, nil
+}
+
+// History returns the provider receipts of the notification, oldest first.
+func (s *ReceiptService) History(ctx context.Context, tenantId, notificationId string) ([]models.StatusHistory, error) {
+	if _, err := s.NotificationRepo.FindById(ctx, tenantId, notificationId); err != nil {
+		if !errors.Is(err, repository.ErrNotFound) {
+			s.Logger.Error(ctx, "Receipt FindById Err", zap.Error(err), zap.String("notificationId", notificationId))
+		}
+		return nil, err
+	}
+
+	history, err := s.ReceiptRepo.List(ctx, tenantId, notificationId)
+	if err != nil {
+		s.Logger.Error(ctx, "Receipt List Err", zap.Error(err), zap.String("notificationId", notificationId))
+	}
+
+	return history, err
+}
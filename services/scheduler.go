@@ -0,0 +1,77 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/HuseyinAsik/Notifications/models"
+	"github.com/HuseyinAsik/Notifications/pkg/logging"
+	"github.com/HuseyinAsik/Notifications/pkg/tracing"
+	"github.com/HuseyinAsik/Notifications/repository"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type Scheduler struct {
+	repo      repository.NotificationRepository
+	logger    *logging.LogWrapper
+	interval  time.Duration
+	batchSize int
+}
+
+func NewScheduler(
+	repo repository.NotificationRepository,
+	interval time.Duration,
+	logger *logging.LogWrapper,
+) *Scheduler {
+	return &Scheduler{
+		repo:      repo,
+		logger:    logger,
+		interval:  interval,
+		batchSize: 100,
+	}
+}
+
+func (s *Scheduler) Run(ctx context.Context) {
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.process(ctx)
+		}
+	}
+}
+
+// process dispatches due notifications batch by batch until none are left.
+func (s *Scheduler) process(ctx context.Context) {
+
+	for ctx.Err() == nil {
+		dispatched, err := s.repo.DispatchDueScheduled(ctx, time.Now().UTC(), s.batchSize, s.dispatchEvent(ctx))
+		if err != nil {
+			s.logger.Error(ctx, "Scheduler DispatchDueScheduled Err", zap.Error(err))
+			return
+		}
+
+		if dispatched < s.batchSize {
+			return
+		}
+	}
+}
+
+// dispatchEvent builds the outbox events of due notifications. The trace of the request that
+// scheduled a notification is long gone, so each dispatched notification starts a new one.
+func (s *Scheduler) dispatchEvent(ctx context.Context) func(models.Notification) *models.OutboxEvent {
+	return func(n models.Notification) *models.OutboxEvent {
+		dispatchCtx, span := tracing.Tracer().Start(ctx, "scheduler dispatch",
+			trace.WithAttributes(attribute.String("notification.id", n.Id)))
+		defer span.End()
+
+		return CreateEvent(dispatchCtx, n)
+	}
+}
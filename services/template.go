@@ -0,0 +1,282 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"text/template"
+	"text/template/parse"
+	"time"
+
+	"github.com/HuseyinAsik/Notifications/models"
+	"github.com/HuseyinAsik/Notifications/pkg/logging"
+	"github.com/HuseyinAsik/Notifications/providers"
+	"github.com/HuseyinAsik/Notifications/repository"
+	"github.com/HuseyinAsik/Notifications/serializers"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type TemplateService struct {
+	TemplateRepo  repository.TemplateRepository
+	DefaultLocale string
+	Logger        *logging.LogWrapper
+}
+
+func NewTemplateService(templateRepo repository.TemplateRepository, defaultLocale string, logger *logging.LogWrapper) *TemplateService {
+	return &TemplateService{
+		TemplateRepo:  templateRepo,
+		DefaultLocale: defaultLocale,
+		Logger:        logger,
+	}
+}
+
+func (s *TemplateService) Create(ctx context.Context, tenantId string, form serializers.CreateTemplateForm) (*models.Template, error) {
+	t := models.Template{
+		Id:        uuid.NewString(),
+		TenantId:  tenantId,
+		Name:      form.Name,
+		Channel:   form.Channel,
+		Locale:    form.Locale,
+		Version:   1,
+		Subject:   form.Subject,
+		Body:      form.Body,
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := checkTemplate(t); err != nil {
+		return nil, err
+	}
+
+	if err := s.TemplateRepo.Create(ctx, t); err != nil {
+		s.Logger.Error(ctx, "Template Create Err", zap.Error(err))
+		return nil, err
+	}
+
+	return &t, nil
+}
+
+func (s *TemplateService) Update(ctx context.Context, tenantId, id string, form serializers.UpdateTemplateForm) (*models.Template, error) {
+	t := models.Template{
+		Id:       id,
+		TenantId: tenantId,
+		Locale:   form.Locale,
+		Subject:  form.Subject,
+		Body:     form.Body,
+	}
+	if err := checkTemplate(t); err != nil {
+		return nil, err
+	}
+
+	updated, err := s.TemplateRepo.AddVersion(ctx, t)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		s.Logger.Error(ctx, "Template AddVersion Err", zap.Error(err), zap.String("id", id))
+	}
+
+	return updated, templateErr(err)
+}
+
+func (s *TemplateService) Get(ctx context.Context, tenantId, id string) ([]models.Template, error) {
+	versions, err := s.TemplateRepo.ListVersions(ctx, tenantId, id)
+	if err != nil {
+		s.Logger.Error(ctx, "Template ListVersions Err", zap.Error(err), zap.String("id", id))
+		return nil, err
+	}
+	if len(versions) == 0 {
+		return nil, ErrTemplateNotFound
+	}
+
+	return versions, nil
+}
+
+func (s *TemplateService) List(ctx context.Context, tenantId string, form serializers.TemplateListForm) ([]models.Template, int, error) {
+	offset := (form.Page - 1) * pageLimit
+
+	templates, total, err := s.TemplateRepo.List(ctx, tenantId, form.Channel, pageLimit, offset)
+	if err != nil {
+		s.Logger.Error(ctx, "Template List Err", zap.Error(err))
+	}
+
+	return templates, total, err
+}
+
+func (s *TemplateService) Delete(ctx context.Context, tenantId, id string) error {
+	err := s.TemplateRepo.Delete(ctx, tenantId, id)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		s.Logger.Error(ctx, "Template Delete Err", zap.Error(err), zap.String("id", id))
+	}
+
+	return templateErr(err)
+}
+
+// Resolve finds the latest version of the template for locale, falling back to its base
+// language ("pt" for "pt-BR") and then to the default locale.
+func (s *TemplateService) Resolve(ctx context.Context, tenantId, id, locale string) (*models.Template, error) {
+	var candidates []string
+	for _, candidate := range []string{locale, baseLanguage(locale), s.DefaultLocale} {
+		if candidate != "" && !slices.Contains(candidates, candidate) {
+			candidates = append(candidates, candidate)
+		}
+	}
+
+	for _, candidate := range candidates {
+		t, err := s.TemplateRepo.FindLatest(ctx, tenantId, id, candidate)
+		if errors.Is(err, repository.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			s.Logger.Error(ctx, "Template FindLatest Err", zap.Error(err), zap.String("id", id), zap.String("locale", candidate))
+			return nil, err
+		}
+		return t, nil
+	}
+
+	return nil, ErrTemplateNotFound
+}
+
+// Render produces the notification content from the template. Email and push templates with a
+// subject render to the JSON message the providers accept; everything else is the plain body.
+func Render(t *models.Template, variables map[string]any) (string, error) {
+	body, err := execute(t.Body, variables)
+	if err != nil {
+		return "", err
+	}
+	if t.Subject == "" {
+		return body, nil
+	}
+
+	subject, err := execute(t.Subject, variables)
+	if err != nil {
+		return "", err
+	}
+
+	var message any
+	switch t.Channel {
+	case "email":
+		message = providers.EmailMessage{Subject: subject, Text: body}
+	case "push":
+		message = providers.PushMessage{Title: subject, Body: body}
+	default:
+		return body, nil
+	}
+
+	content, err := json.Marshal(message)
+	if err != nil {
+		return "", err
+	}
+
+	return string(content), nil
+}
+
+func execute(text string, variables map[string]any) (string, error) {
+	tmpl, err := template.New("").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", ErrInvalidTemplate, err)
+	}
+
+	var missing []string
+	for _, name := range templateVariables(tmpl.Tree) {
+		if _, ok := variables[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return "", fmt.Errorf("%w: %s", ErrTemplateVariablesMissing, strings.Join(missing, ", "))
+	}
+
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, variables); err != nil {
+		return "", fmt.Errorf("%w: %w", ErrTemplateVariablesMissing, err)
+	}
+
+	return sb.String(), nil
+}
+
+func checkTemplate(t models.Template) error {
+	for _, text := range []string{t.Subject, t.Body} {
+		if _, err := template.New("").Parse(text); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidTemplate, err)
+		}
+	}
+	return nil
+}
+
+// templateVariables lists the top-level variables the template reads, in order of first use.
+// Fields inside range and with blocks are relative to another value and are left to Execute;
+// only $-rooted variables are collected there.
+func templateVariables(tree *parse.Tree) []string {
+	var names []string
+	add := func(name string) {
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+
+	var walk func(node parse.Node, scoped bool)
+	walk = func(node parse.Node, scoped bool) {
+		switch n := node.(type) {
+		case *parse.ListNode:
+			if n == nil {
+				return
+			}
+			for _, child := range n.Nodes {
+				walk(child, scoped)
+			}
+		case *parse.ActionNode:
+			walk(n.Pipe, scoped)
+		case *parse.PipeNode:
+			if n == nil {
+				return
+			}
+			for _, cmd := range n.Cmds {
+				walk(cmd, scoped)
+			}
+		case *parse.CommandNode:
+			for _, arg := range n.Args {
+				walk(arg, scoped)
+			}
+		case *parse.FieldNode:
+			if !scoped {
+				add(n.Ident[0])
+			}
+		case *parse.VariableNode:
+			if len(n.Ident) > 1 && n.Ident[0] == "$" {
+				add(n.Ident[1])
+			}
+		case *parse.IfNode:
+			walk(n.Pipe, scoped)
+			walk(n.List, scoped)
+			walk(n.ElseList, scoped)
+		case *parse.RangeNode:
+			walk(n.Pipe, scoped)
+			walk(n.List, true)
+			walk(n.ElseList, scoped)
+		case *parse.WithNode:
+			walk(n.Pipe, scoped)
+			walk(n.List, true)
+			walk(n.ElseList, scoped)
+		case *parse.TemplateNode:
+			walk(n.Pipe, scoped)
+		}
+	}
+
+	if tree != nil {
+		walk(tree.Root, false)
+	}
+
+	return names
+}
+
+func baseLanguage(locale string) string {
+	base, _, _ := strings.Cut(locale, "-")
+	return base
+}
+
+func templateErr(err error) error {
+	if errors.Is(err, repository.ErrNotFound) {
+		return ErrTemplateNotFound
+	}
+	return err
+}
@@ -0,0 +1,143 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+	"text/template"
+
+	"github.com/HuseyinAsik/Notifications/models"
+	"github.com/HuseyinAsik/Notifications/providers"
+)
+
+func TestTemplateVariables(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{name: "fields", text: "Hi {{.name}}, your code is {{.code}}. Bye {{.name}}", want: []string{"name", "code"}},
+		{name: "nested field", text: "{{.user.name}}", want: []string{"user"}},
+		{name: "if", text: "{{if .vip}}Dear {{.name}}{{else}}Hi {{.nick}}{{end}}", want: []string{"vip", "name", "nick"}},
+		{name: "range body is scoped", text: "{{range .items}}{{.title}}{{else}}{{.empty}}{{end}}", want: []string{"items", "empty"}},
+		{name: "with body is scoped", text: "{{with .order}}#{{.id}}{{end}}", want: []string{"order"}},
+		{name: "dollar inside range", text: "{{range .items}}{{.title}} for {{$.name}}{{end}}", want: []string{"items", "name"}},
+		{name: "dollar inside with", text: "{{with .order}}{{$.currency}}{{end}}", want: []string{"order", "currency"}},
+		{name: "declared variables", text: "{{$n := .name}}{{range $i, $item := .items}}{{$i}} {{$item.title}} {{$n}}{{end}}", want: []string{"name", "items"}},
+		{name: "function arguments", text: `{{printf "%s-%s" .a .b}}`, want: []string{"a", "b"}},
+		{name: "plain text", text: "hello", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := template.New("").Parse(tt.text)
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			if got := templateVariables(tmpl.Tree); !slices.Equal(got, tt.want) {
+				t.Errorf("templateVariables = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	variables := map[string]any{
+		"name":  "Ayşe",
+		"code":  "1234",
+		"items": []map[string]any{{"title": "Book"}, {"title": "Pen"}},
+	}
+
+	tests := []struct {
+		name     string
+		template models.Template
+		want     string
+		wantErr  error
+	}{
+		{
+			name:     "sms",
+			template: models.Template{Channel: "sms", Body: "Hi {{.name}}, your code is {{.code}}"},
+			want:     "Hi Ayşe, your code is 1234",
+		},
+		{
+			name:     "range with dollar",
+			template: models.Template{Channel: "sms", Body: "{{range .items}}{{.title}}/{{$.name}} {{end}}"},
+			want:     "Book/Ayşe Pen/Ayşe ",
+		},
+		{
+			name:     "missing variable",
+			template: models.Template{Channel: "sms", Body: "Hi {{.name}} {{.surname}} {{.title}}"},
+			wantErr:  ErrTemplateVariablesMissing,
+		},
+		{
+			name:     "missing dollar variable inside range",
+			template: models.Template{Channel: "sms", Body: "{{range .items}}{{$.currency}}{{end}}"},
+			wantErr:  ErrTemplateVariablesMissing,
+		},
+		{
+			name:     "missing field inside range",
+			template: models.Template{Channel: "sms", Body: "{{range .items}}{{.price}}{{end}}"},
+			wantErr:  ErrTemplateVariablesMissing,
+		},
+		{
+			name:     "missing subject variable",
+			template: models.Template{Channel: "email", Subject: "{{.order}}", Body: "Hi"},
+			wantErr:  ErrTemplateVariablesMissing,
+		},
+		{
+			name:     "invalid template",
+			template: models.Template{Channel: "sms", Body: "Hi {{.name"},
+			wantErr:  ErrInvalidTemplate,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Render(&tt.template, variables)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Render err = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Render = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderMissingVariablesAreListed(t *testing.T) {
+	_, err := Render(&models.Template{Channel: "sms", Body: "{{.b}} {{.a}} {{.b}}"}, map[string]any{})
+	if err == nil || err.Error() != ErrTemplateVariablesMissing.Error()+": b, a" {
+		t.Errorf("Render err = %v, want the missing variables in order", err)
+	}
+}
+
+func TestRenderWrapsEmailAndPush(t *testing.T) {
+	variables := map[string]any{"name": `Ali "A"`, "order": "42"}
+
+	email, err := Render(&models.Template{Channel: "email", Subject: "Order {{.order}}", Body: "Hi {{.name}}"}, variables)
+	if err != nil {
+		t.Fatalf("render email: %v", err)
+	}
+	var message providers.EmailMessage
+	if err := json.Unmarshal([]byte(email), &message); err != nil {
+		t.Fatalf("email content is not JSON: %v", err)
+	}
+	if message.Subject != "Order 42" || message.Text != `Hi Ali "A"` {
+		t.Errorf("email message = %+v", message)
+	}
+
+	push, err := Render(&models.Template{Channel: "push", Subject: "Order {{.order}}", Body: "Hi {{.name}}"}, variables)
+	if err != nil {
+		t.Fatalf("render push: %v", err)
+	}
+	if parsed := providers.ParsePushMessage(push); parsed.Title != "Order 42" || parsed.Body != `Hi Ali "A"` {
+		t.Errorf("push message = %+v", parsed)
+	}
+
+	// Without a subject the body is sent as it is.
+	plain, err := Render(&models.Template{Channel: "email", Body: "Hi {{.name}}"}, variables)
+	if err != nil || plain != `Hi Ali "A"` {
+		t.Errorf("Render without subject = %q, %v", plain, err)
+	}
+}
@@ -0,0 +1,189 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/HuseyinAsik/Notifications/models"
+	"github.com/HuseyinAsik/Notifications/pkg/auth"
+	"github.com/HuseyinAsik/Notifications/pkg/logging"
+	"github.com/HuseyinAsik/Notifications/repository"
+	"github.com/HuseyinAsik/Notifications/serializers"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	apiKeyPrefix    = "ntf_"
+	apiKeyPrefixLen = 12
+
+	webhookSecretPrefix = "whsec_"
+)
+
+type TenantService struct {
+	TenantRepo repository.TenantRepository
+	Logger     *logging.LogWrapper
+}
+
+func NewTenantService(tenantRepo repository.TenantRepository, logger *logging.LogWrapper) *TenantService {
+	return &TenantService{
+		TenantRepo: tenantRepo,
+		Logger:     logger,
+	}
+}
+
+func (s *TenantService) Create(ctx context.Context, form serializers.CreateTenantForm) (*models.Tenant, error) {
+	tenant := models.Tenant{
+		Id:        uuid.NewString(),
+		Name:      form.Name,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	if err := s.TenantRepo.CreateTenant(ctx, tenant); err != nil {
+		s.Logger.Error(ctx, "Tenant Create Err", zap.Error(err))
+		return nil, err
+	}
+
+	return &tenant, nil
+}
+
+func (s *TenantService) List(ctx context.Context, form serializers.TenantListForm) ([]models.Tenant, int, error) {
+	offset := (form.Page - 1) * pageLimit
+
+	tenants, total, err := s.TenantRepo.ListTenants(ctx, pageLimit, offset)
+	if err != nil {
+		s.Logger.Error(ctx, "Tenant List Err", zap.Error(err))
+	}
+
+	return tenants, total, err
+}
+
+func (s *TenantService) ListApiKeys(ctx context.Context, tenantId string) ([]models.ApiKey, error) {
+	if err := s.checkTenant(ctx, tenantId); err != nil {
+		return nil, err
+	}
+
+	apiKeys, err := s.TenantRepo.ListApiKeys(ctx, tenantId)
+	if err != nil {
+		s.Logger.Error(ctx, "Tenant ListApiKeys Err", zap.Error(err), zap.String("tenantId", tenantId))
+	}
+
+	return apiKeys, err
+}
+
+// CreateApiKey issues a new key for the tenant. Only its hash is stored, so the returned
+// plaintext key cannot be recovered later.
+func (s *TenantService) CreateApiKey(ctx context.Context, tenantId string, form serializers.CreateApiKeyForm) (*models.ApiKey, string, error) {
+	if err := s.checkTenant(ctx, tenantId); err != nil {
+		return nil, "", err
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, "", err
+	}
+	key := apiKeyPrefix + base64.RawURLEncoding.EncodeToString(secret)
+
+	apiKey := models.ApiKey{
+		Id:        uuid.NewString(),
+		TenantId:  tenantId,
+		Name:      form.Name,
+		Prefix:    key[:apiKeyPrefixLen],
+		KeyHash:   HashApiKey(key),
+		CreatedAt: time.Now().UTC(),
+	}
+
+	if err := s.TenantRepo.CreateApiKey(ctx, apiKey); err != nil {
+		s.Logger.Error(ctx, "Tenant CreateApiKey Err", zap.Error(err), zap.String("tenantId", tenantId))
+		return nil, "", err
+	}
+
+	return &apiKey, key, nil
+}
+
+func (s *TenantService) RevokeApiKey(ctx context.Context, id string) error {
+	err := s.TenantRepo.RevokeApiKey(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return ErrApiKeyNotFound
+	}
+	if err != nil {
+		s.Logger.Error(ctx, "Tenant RevokeApiKey Err", zap.Error(err), zap.String("id", id))
+	}
+
+	return err
+}
+
+// Authenticate resolves an API key to the tenant it belongs to.
+func (s *TenantService) Authenticate(ctx context.Context, key string) (*auth.Principal, error) {
+	if !strings.HasPrefix(key, apiKeyPrefix) {
+		return nil, ErrInvalidApiKey
+	}
+
+	apiKey, err := s.TenantRepo.FindApiKeyByHash(ctx, HashApiKey(key))
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrInvalidApiKey
+	}
+	if err != nil {
+		s.Logger.Error(ctx, "Tenant FindApiKeyByHash Err", zap.Error(err))
+		return nil, err
+	}
+
+	return &auth.Principal{
+		TenantId: apiKey.TenantId,
+		ApiKeyId: apiKey.Id,
+		Scopes:   []string{auth.ScopeWrite, auth.ScopeRead},
+	}, nil
+}
+
+// UpdateWebhook sets the tenant's default callback URL. A signing secret is generated when
+// the tenant has none or rotation is requested; it is returned only then.
+func (s *TenantService) UpdateWebhook(ctx context.Context, tenantId string, form serializers.UpdateWebhookForm) (*models.Tenant, string, error) {
+	tenant, err := s.TenantRepo.FindTenant(ctx, tenantId)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, "", ErrTenantNotFound
+	}
+	if err != nil {
+		s.Logger.Error(ctx, "Tenant FindTenant Err", zap.Error(err), zap.String("tenantId", tenantId))
+		return nil, "", err
+	}
+
+	var secret string
+	if tenant.WebhookSecret == "" || form.RotateSecret {
+		raw := make([]byte, 32)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, "", err
+		}
+		secret = webhookSecretPrefix + base64.RawURLEncoding.EncodeToString(raw)
+	}
+
+	if err := s.TenantRepo.UpdateWebhook(ctx, tenantId, form.Url, secret); err != nil {
+		s.Logger.Error(ctx, "Tenant UpdateWebhook Err", zap.Error(err), zap.String("tenantId", tenantId))
+		return nil, "", err
+	}
+	tenant.WebhookUrl = form.Url
+
+	return tenant, secret, nil
+}
+
+func (s *TenantService) checkTenant(ctx context.Context, tenantId string) error {
+	_, err := s.TenantRepo.FindTenant(ctx, tenantId)
+	if errors.Is(err, repository.ErrNotFound) {
+		return ErrTenantNotFound
+	}
+	if err != nil {
+		s.Logger.Error(ctx, "Tenant FindTenant Err", zap.Error(err), zap.String("tenantId", tenantId))
+	}
+
+	return err
+}
+
+func HashApiKey(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(hash[:])
+}
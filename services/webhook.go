@@ -0,0 +1,233 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/HuseyinAsik/Notifications/models"
+	"github.com/HuseyinAsik/Notifications/pkg/httpx"
+	"github.com/HuseyinAsik/Notifications/pkg/logging"
+	"github.com/HuseyinAsik/Notifications/pkg/worker"
+	"github.com/HuseyinAsik/Notifications/repository"
+	"go.uber.org/zap"
+)
+
+const (
+	WebhookIdHeader        = "X-Webhook-Id"
+	WebhookEventHeader     = "X-Webhook-Event"
+	WebhookTimestampHeader = "X-Webhook-Timestamp"
+	WebhookSignatureHeader = "X-Webhook-Signature"
+)
+
+// WebhookDispatcher delivers the queued delivery status webhooks to the clients' callback
+// URLs and retries failed calls with backoff.
+type WebhookDispatcher struct {
+	repo        repository.WebhookRepository
+	client      httpx.HTTPClient
+	backoff     worker.Backoff
+	logger      *logging.LogWrapper
+	interval    time.Duration
+	lease       time.Duration
+	concurrency int
+	batchSize   int
+}
+
+// NewWebhookTransport returns the transport callback URLs are called with. Its dialer refuses
+// loopback, private and link-local addresses after name resolution, so a callback host cannot
+// point the dispatcher at the internal network, and it goes around any proxy for the same reason.
+func NewWebhookTransport() *http.Transport {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   httpx.PublicOnlyControl,
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return transport
+}
+
+func NewWebhookDispatcher(
+	repo repository.WebhookRepository,
+	client httpx.HTTPClient,
+	backoff worker.Backoff,
+	interval time.Duration,
+	timeout time.Duration,
+	concurrency int,
+	logger *logging.LogWrapper,
+) *WebhookDispatcher {
+	return &WebhookDispatcher{
+		repo:        repo,
+		client:      client,
+		backoff:     backoff,
+		logger:      logger,
+		interval:    interval,
+		lease:       2 * timeout,
+		concurrency: concurrency,
+		batchSize:   100,
+	}
+}
+
+func (d *WebhookDispatcher) Run(ctx context.Context) {
+
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			d.process(ctx)
+		}
+	}
+}
+
+// process delivers due webhooks batch by batch until none are left.
+func (d *WebhookDispatcher) process(ctx context.Context) {
+
+	for ctx.Err() == nil {
+		deliveries, err := d.repo.ClaimDue(ctx, d.batchSize, d.lease)
+		if err != nil {
+			d.logger.Error(ctx, "WebhookDispatcher ClaimDue Err", zap.Error(err))
+			return
+		}
+
+		var wg sync.WaitGroup
+		sem := make(chan struct{}, d.concurrency)
+		for _, delivery := range deliveries {
+			sem <- struct{}{}
+			wg.Add(1)
+			go func(delivery models.WebhookDelivery) {
+				defer func() {
+					<-sem
+					wg.Done()
+				}()
+				d.deliver(ctx, delivery)
+			}(delivery)
+		}
+		wg.Wait()
+
+		if len(deliveries) < d.batchSize {
+			return
+		}
+	}
+}
+
+func (d *WebhookDispatcher) deliver(ctx context.Context, delivery models.WebhookDelivery) {
+
+	started := time.Now()
+	delivery.Attempts++
+	attempt := models.WebhookAttempt{Attempt: delivery.Attempts}
+
+	statusCode, err := d.send(ctx, delivery)
+	attempt.StatusCode = statusCode
+	attempt.DurationMs = int(time.Since(started).Milliseconds())
+	if err == nil && (statusCode < 200 || statusCode > 299) {
+		err = fmt.Errorf("unexpected status %d", statusCode)
+	}
+
+	delivery.LastStatusCode = statusCode
+	delivery.LastError = ""
+	now := time.Now().UTC()
+	switch {
+	case err == nil:
+		delivery.Status = "delivered"
+		delivery.NextAttemptAt = now
+		delivery.DeliveredAt = &now
+	case delivery.Attempts >= d.backoff.MaxAttempts || delivery.Secret == "":
+		attempt.Error = err.Error()
+		delivery.LastError = attempt.Error
+		delivery.Status = "failed"
+		delivery.NextAttemptAt = now
+	default:
+		attempt.Error = err.Error()
+		delivery.LastError = attempt.Error
+		delivery.Status = "pending"
+		delivery.NextAttemptAt = now.Add(d.backoff.Next(delivery.Attempts))
+	}
+
+	if err != nil {
+		d.logger.Warn(ctx, "WebhookDispatcher deliver Err", zap.Error(err),
+			zap.String("id", delivery.Id), zap.String("url", delivery.Url), zap.Int("attempts", delivery.Attempts))
+	}
+
+	if recordErr := d.repo.RecordAttempt(ctx, delivery, attempt); recordErr != nil {
+		d.logger.Error(ctx, "WebhookDispatcher RecordAttempt Err", zap.Error(recordErr), zap.String("id", delivery.Id))
+	}
+}
+
+func (d *WebhookDispatcher) send(ctx context.Context, delivery models.WebhookDelivery) (int, error) {
+	if delivery.Secret == "" {
+		return 0, errors.New("tenant has no webhook secret")
+	}
+
+	// The body is sent exactly as it was signed.
+	body, err := json.Marshal(delivery.Payload)
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	headers := map[string]string{
+		WebhookIdHeader:        delivery.Id,
+		WebhookEventHeader:     delivery.Event,
+		WebhookTimestampHeader: timestamp,
+		WebhookSignatureHeader: SignWebhook(delivery.Secret, timestamp, body),
+	}
+
+	return d.client.DoJSON(ctx, http.MethodPost, delivery.Url, json.RawMessage(body), headers, nil)
+}
+
+// SignWebhook returns the signature header value of a webhook body: the hex HMAC-SHA256,
+// keyed by the tenant's secret, of the timestamp, a dot and the raw body.
+func SignWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+type WebhookService struct {
+	WebhookRepo      repository.WebhookRepository
+	NotificationRepo repository.NotificationRepository
+	Logger           *logging.LogWrapper
+}
+
+func NewWebhookService(webhookRepo repository.WebhookRepository, notificationRepo repository.NotificationRepository, logger *logging.LogWrapper) *WebhookService {
+	return &WebhookService{
+		WebhookRepo:      webhookRepo,
+		NotificationRepo: notificationRepo,
+		Logger:           logger,
+	}
+}
+
+// Deliveries returns the webhooks sent for the notification with their attempt log.
+func (s *WebhookService) Deliveries(ctx context.Context, tenantId, notificationId string) ([]models.WebhookDelivery, error) {
+	if _, err := s.NotificationRepo.FindById(ctx, tenantId, notificationId); err != nil {
+		if !errors.Is(err, repository.ErrNotFound) {
+			s.Logger.Error(ctx, "Webhook FindById Err", zap.Error(err), zap.String("notificationId", notificationId))
+		}
+		return nil, err
+	}
+
+	deliveries, err := s.WebhookRepo.ListByNotification(ctx, tenantId, notificationId)
+	if err != nil {
+		s.Logger.Error(ctx, "Webhook ListByNotification Err", zap.Error(err), zap.String("notificationId", notificationId))
+	}
+
+	return deliveries, err
+}
@@ -0,0 +1,30 @@
+package services
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/HuseyinAsik/Notifications/pkg/httpx"
+)
+
+func TestWebhookTransportRefusesLoopback(t *testing.T) {
+	called := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: NewWebhookTransport()}
+	resp, err := client.Post(server.URL, "application/json", nil)
+	if err == nil {
+		resp.Body.Close()
+	}
+	if !errors.Is(err, httpx.ErrNonPublicAddress) {
+		t.Fatalf("Post(%s) err = %v, want ErrNonPublicAddress", server.URL, err)
+	}
+	if called {
+		t.Error("the loopback server was called")
+	}
+}